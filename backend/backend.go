@@ -3,6 +3,8 @@ package backend
 import (
 	"context"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sundhar-perumal/vault-plugin-secrets-skyflow/backend/telemetry"
@@ -29,28 +31,102 @@ var (
 type skyflowBackend struct {
 	*framework.Backend
 
-	// Telemetry providers
+	// Telemetry providers scoped to this mount, and the release of its share of the process pipeline.
+	// Guarded by telemetryLock since config/telemetry swaps them while requests are served.
+	telemetryLock      sync.RWMutex
 	telemetryProviders *telemetry.Providers
 	telemetryShutdown  func(context.Context) error
+
+	// Environment-derived telemetry config that config/telemetry is merged over, and this mount's scope
+	telemetryEnvConfig *telemetry.ResolvedConfig
+	telemetryScope     telemetry.MountScope
+
+	// Mount storage, for reloads triggered by invalidation
+	storage logical.Storage
+
+	// Cached Skyflow tokens for this mount
+	tokenCache *tokenCache
+
+	// Coalesces concurrent token mints for the same role+ctx+config
+	tokenFlight *tokenFlight
+
+	// Fails token calls fast while this mount's Skyflow token endpoint is failing
+	breakers *circuitBreakers
+
+	// Throttles creds reads for roles with a rate_limit
+	rateLimiter *rateLimiter
+
+	// Refresh status of roles whose tokens are kept warm by the periodic worker
+	prewarm *prewarmTracker
+
+	// Serializes config writes, deletes, rollbacks and root rotations
+	configLock sync.Mutex
+
+	// Mints bearer tokens for creds/<role> on mounts using the SDK issuer
+	tokenIssuer TokenIssuer
+
+	// Native issuer for mounts with token_issuer=native, rebuilt when its HTTP settings change
+	nativeIssuerLock sync.Mutex
+	nativeIssuer     *nativeTokenIssuer
+	nativeIssuerKey  string
+
+	// Mints bearer tokens for Skyflow management API calls
+	managementToken func(credentialsJSON string) (string, error)
+
+	// Scheduled rotation is not retried before this time after a failure; guarded by configLock
+	scheduledRotationRetryAt time.Time
 }
 
 // Factory returns a new backend as logical.Backend
 func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+	return newBackend(ctx, conf)
+}
+
+// FactoryWithOptions returns a logical.Factory that applies opts to each backend it creates
+func FactoryWithOptions(opts ...Option) logical.Factory {
+	return func(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+		return newBackend(ctx, conf, opts...)
+	}
+}
+
+// newBackend creates and sets up the backend
+func newBackend(ctx context.Context, conf *logical.BackendConfig, opts ...Option) (logical.Backend, error) {
 	// Get environment from ENV variable, default to "unknown"
 	environment := os.Getenv("ENV")
 	if environment == "" {
 		environment = "unknown"
 	}
 
-	b := &skyflowBackend{}
+	b := &skyflowBackend{
+		tokenCache:      newTokenCache(),
+		tokenFlight:     newTokenFlight(),
+		breakers:        newCircuitBreakers(),
+		rateLimiter:     newRateLimiter(),
+		prewarm:         newPrewarmTracker(),
+		tokenIssuer:     sdkTokenIssuer{},
+		managementToken: sdkBearerToken,
+	}
 
-	// Initialize telemetry (respects RUNTIME_LOCAL and ENV for local development)
-	// If disabled or fails, OTEL uses built-in noop tracer automatically
-	providers, shutdown, err := telemetry.Init(ctx, telemetry.BuildConfigInput{
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.storage = conf.StorageView
+	b.telemetryScope = telemetry.MountScope{BackendUUID: conf.BackendUUID}
+
+	// Join the plugin process's shared telemetry pipeline (respects RUNTIME_LOCAL and ENV for local development)
+	// If disabled or fails, OTEL uses built-in noop tracer automatically. config/telemetry is applied on initialize.
+	var providers *telemetry.Providers
+	var shutdown func(context.Context) error
+	envConfig, err := telemetry.BuildConfig(telemetry.BuildConfigInput{
 		ServiceName:    "skyflow-vault-plugin",
 		ServiceVersion: Version,
 		Environment:    environment,
 	})
+	if err == nil {
+		b.telemetryEnvConfig = envConfig
+		providers, shutdown, err = telemetry.AcquireWithConfig(ctx, envConfig, b.telemetryScope)
+	}
 	if err != nil {
 		// Log warning but don't fail - telemetry is optional
 		// OTEL will use built-in noop tracer
@@ -90,32 +166,61 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 
 		Paths: framework.PathAppend(
 			pathConfig(b),
+			pathConfigTelemetry(b),
+			pathConfigHistory(b),
+			pathRotateRoot(b),
 			pathRoles(b),
+			pathCredentials(b),
 			pathToken(b),
+			pathSign(b),
 			pathHealth(b),
+			pathMetrics(b),
 		),
 
 		PathsSpecial: &logical.Paths{
+			Unauthenticated: unauthenticatedPaths(),
 			SealWrapStorage: []string{
 				"config",
 				"role/*",
+				credentialSetStoragePrefix + "*",
+				configHistoryStoragePrefix + "*",
+				telemetryConfigStorageKey,
+				framework.WALPrefix + "*",
 			},
 		},
 
-		Secrets:    []*framework.Secret{},
-		Invalidate: b.invalidate,
-		Clean:      b.cleanup,
+		Secrets:           []*framework.Secret{secretToken(b)},
+		InitializeFunc:    b.initialize,
+		PeriodicFunc:      b.periodicFunc,
+		WALRollback:       b.walRollback,
+		WALRollbackMinAge: walRollbackMinAge,
+		Invalidate:        b.invalidate,
+		Clean:             b.cleanup,
 	}
 
 	if err := b.Setup(ctx, conf); err != nil {
+		if b.telemetryShutdown != nil {
+			_ = b.telemetryShutdown(ctx)
+		}
 		return nil, err
 	}
 
 	return b, nil
 }
 
+// unauthenticatedPaths returns the paths served without a Vault token
+func unauthenticatedPaths() []string {
+	if metricsUnauthenticated() {
+		return []string{"metrics"}
+	}
+	return nil
+}
+
 // metrics returns the metrics provider (nil-safe)
 func (b *skyflowBackend) metrics() *telemetry.MetricsProvider {
+	b.telemetryLock.RLock()
+	defer b.telemetryLock.RUnlock()
+
 	if b.telemetryProviders == nil {
 		return nil
 	}
@@ -124,21 +229,65 @@ func (b *skyflowBackend) metrics() *telemetry.MetricsProvider {
 
 // traces returns the traces provider (nil-safe)
 func (b *skyflowBackend) traces() *telemetry.TracesProvider {
+	b.telemetryLock.RLock()
+	defer b.telemetryLock.RUnlock()
+
 	if b.telemetryProviders == nil {
 		return nil
 	}
 	return b.telemetryProviders.Traces()
 }
 
+// initialize is called once the mount is ready. It resumes interrupted root rotations and
+// applies the mount's config/telemetry settings.
+func (b *skyflowBackend) initialize(ctx context.Context, req *logical.InitializationRequest) error {
+	if err := b.resumeRootRotations(ctx, req.Storage); err != nil {
+		b.Logger().Warn("failed to resume root rotation", "error", err)
+	}
+
+	telemetryConfig, err := b.getTelemetryConfig(ctx, req.Storage)
+	if err == nil && telemetryConfig != nil {
+		err = b.applyTelemetryConfig(ctx, telemetryConfig)
+	}
+	if err != nil {
+		b.Logger().Warn("failed to apply telemetry configuration", "error", err)
+	}
+
+	return nil
+}
+
 // invalidate is called when a key is updated
 func (b *skyflowBackend) invalidate(ctx context.Context, key string) {
 	b.Logger().Debug("key invalidated", "key", key)
+
+	switch {
+	case key == "config":
+		b.tokenCache.purge()
+		b.breakers.reset()
+	case strings.HasPrefix(key, "role/"):
+		b.tokenCache.purgeRole(strings.TrimPrefix(key, "role/"))
+		b.rateLimiter.purgeRole(strings.TrimPrefix(key, "role/"))
+	case strings.HasPrefix(key, credentialSetStoragePrefix):
+		b.tokenCache.purge()
+		b.breakers.resetSet(strings.TrimPrefix(key, credentialSetStoragePrefix))
+	case strings.HasPrefix(key, revokedTokenStoragePrefix):
+		b.tokenCache.purgeToken(strings.TrimPrefix(key, revokedTokenStoragePrefix))
+	case key == telemetryConfigStorageKey:
+		if err := b.reloadTelemetryConfig(ctx, b.storage); err != nil {
+			b.Logger().Warn("failed to reload telemetry configuration", "error", err)
+		}
+	}
 }
 
 // cleanup is called during backend cleanup
 func (b *skyflowBackend) cleanup(ctx context.Context) {
-	if b.telemetryShutdown != nil {
-		if err := b.telemetryShutdown(ctx); err != nil {
+	b.telemetryLock.Lock()
+	shutdown := b.telemetryShutdown
+	b.telemetryShutdown = nil
+	b.telemetryLock.Unlock()
+
+	if shutdown != nil {
+		if err := shutdown(ctx); err != nil {
 			b.Logger().Warn("telemetry shutdown error", "error", err)
 		}
 	}
@@ -154,7 +303,10 @@ type auditEvent struct {
 	Duration  int64     `json:"duration_ms"`
 	ClientIP  string    `json:"client_ip,omitempty"`
 	TraceID   string    `json:"trace_id,omitempty"`
+	Cached    bool      `json:"cached,omitempty"`
+	Stale     bool      `json:"stale,omitempty"`
 	Error     string    `json:"error,omitempty"`
+	ErrorCode string    `json:"error_code,omitempty"`
 }
 
 // auditLog writes audit events
@@ -175,9 +327,21 @@ func (b *skyflowBackend) auditLog(event auditEvent) {
 		fields = append(fields, "client_ip", event.ClientIP)
 	}
 
+	if event.Cached {
+		fields = append(fields, "cached", true)
+	}
+
+	if event.Stale {
+		fields = append(fields, "stale", true)
+	}
+
 	if event.Error != "" {
 		fields = append(fields, "error", event.Error)
 	}
 
+	if event.ErrorCode != "" {
+		fields = append(fields, "error_code", event.ErrorCode)
+	}
+
 	b.Logger().Info("audit", fields...)
 }
\ No newline at end of file
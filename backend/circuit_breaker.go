@@ -0,0 +1,225 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCircuitBreakerThreshold is how many consecutive failures open the breaker
+	defaultCircuitBreakerThreshold = 5
+
+	// defaultCircuitBreakerWindow is how close together those failures must be
+	defaultCircuitBreakerWindow = time.Minute
+
+	// defaultCircuitBreakerCooldown is how long the breaker stays open before a probe is let through
+	defaultCircuitBreakerCooldown = 30 * time.Second
+)
+
+// Circuit breaker states, also the values of the circuit breaker state gauge
+const (
+	circuitClosed   = 0
+	circuitHalfOpen = 1
+	circuitOpen     = 2
+)
+
+// errCircuitOpen is returned instead of calling Skyflow while the breaker is open
+var errCircuitOpen = errors.New("skyflow token endpoint circuit breaker is open")
+
+// circuitBreakerSettings are the mount's breaker thresholds
+type circuitBreakerSettings struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+}
+
+// circuitBreaker stops token calls to a failing Skyflow endpoint. It opens after threshold
+// consecutive failures within window, and after cooldown lets a single probe through
+// (half-open) whose result closes or re-opens it. Each mount owns one breaker for its config
+// credentials and one per credential set, so a failing service account only fails its own roles.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	state        int
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	probing      bool
+}
+
+// newCircuitBreaker creates a closed circuit breaker
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{}
+}
+
+// allow returns errCircuitOpen if a token call must not be made now. Once the cooldown has
+// passed it moves the breaker to half-open and admits one probe.
+func (cb *circuitBreaker) allow(settings circuitBreakerSettings, now time.Time) error {
+	if settings.threshold <= 0 {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == circuitOpen && now.Sub(cb.openedAt) >= settings.cooldown {
+		cb.state = circuitHalfOpen
+		cb.probing = false
+	}
+
+	switch cb.state {
+	case circuitOpen:
+		retryAt := cb.openedAt.Add(settings.cooldown)
+		return fmt.Errorf("%w; retry after %s", errCircuitOpen, retryAt.UTC().Format(time.RFC3339))
+	case circuitHalfOpen:
+		if cb.probing {
+			return fmt.Errorf("%w; a probe request is in progress", errCircuitOpen)
+		}
+		cb.probing = true
+	}
+
+	return nil
+}
+
+// record reports the outcome of an allowed token call
+func (cb *circuitBreaker) record(settings circuitBreakerSettings, failed bool, now time.Time) {
+	if settings.threshold <= 0 {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !failed {
+		cb.state = circuitClosed
+		cb.failures = 0
+		cb.probing = false
+		return
+	}
+
+	if cb.state == circuitHalfOpen {
+		cb.trip(now)
+		return
+	}
+
+	// A failure streak older than the window starts over
+	if cb.failures == 0 || now.Sub(cb.firstFailure) > settings.window {
+		cb.failures = 0
+		cb.firstFailure = now
+	}
+
+	cb.failures++
+	if cb.failures >= settings.threshold {
+		cb.trip(now)
+	}
+}
+
+// trip opens the breaker; callers hold mu
+func (cb *circuitBreaker) trip(now time.Time) {
+	cb.state = circuitOpen
+	cb.openedAt = now
+	cb.failures = 0
+	cb.probing = false
+}
+
+// reset closes the breaker, e.g. after the mount's credentials change
+func (cb *circuitBreaker) reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.state = circuitClosed
+	cb.failures = 0
+	cb.probing = false
+}
+
+// circuitBreakers holds a mount's breakers keyed by credential set name; "" is the mount config
+type circuitBreakers struct {
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+// newCircuitBreakers creates an empty breaker registry
+func newCircuitBreakers() *circuitBreakers {
+	return &circuitBreakers{breakers: make(map[string]*circuitBreaker)}
+}
+
+// get returns the breaker for a credential set, creating a closed one on first use
+func (r *circuitBreakers) get(credentialSet string) *circuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cb, ok := r.breakers[credentialSet]
+	if !ok {
+		cb = newCircuitBreaker()
+		r.breakers[credentialSet] = cb
+	}
+	return cb
+}
+
+// reset closes every breaker, e.g. after the mount config changes the breaker settings
+func (r *circuitBreakers) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.breakers = make(map[string]*circuitBreaker)
+}
+
+// resetSet closes the breaker of one credential set after its credentials change
+func (r *circuitBreakers) resetSet(credentialSet string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.breakers, credentialSet)
+}
+
+// statuses returns the state of every breaker that has seen a call, keyed by credential set
+func (r *circuitBreakers) statuses() map[string]circuitBreakerStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make(map[string]circuitBreakerStatus, len(r.breakers))
+	for name, cb := range r.breakers {
+		statuses[name] = cb.status()
+	}
+	return statuses
+}
+
+// circuitBreakerStatus is a point-in-time view of a breaker for health and metrics
+type circuitBreakerStatus struct {
+	State               int
+	ConsecutiveFailures int
+	OpenedAt            time.Time
+}
+
+// status returns the breaker's current state
+func (cb *circuitBreaker) status() circuitBreakerStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return circuitBreakerStatus{
+		State:               cb.state,
+		ConsecutiveFailures: cb.failures,
+		OpenedAt:            cb.openedAt,
+	}
+}
+
+// circuitStateName returns the name of a breaker state for responses and logs
+func circuitStateName(state int) string {
+	switch state {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// tripsCircuitBreaker reports whether a token call failure counts against the breaker.
+// Only signs of endpoint degradation count; a rejected request shows the endpoint is up.
+func tripsCircuitBreaker(err error) bool {
+	return isRetryableTokenError(err) || errors.Is(err, context.DeadlineExceeded)
+}
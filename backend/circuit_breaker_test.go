@@ -0,0 +1,353 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
+)
+
+func TestCircuitBreaker_StateMachine(t *testing.T) {
+	settings := circuitBreakerSettings{threshold: 3, window: time.Minute, cooldown: 30 * time.Second}
+	start := time.Now()
+
+	t.Run("Opens after consecutive failures", func(t *testing.T) {
+		cb := newCircuitBreaker()
+		for i := 0; i < 3; i++ {
+			if err := cb.allow(settings, start); err != nil {
+				t.Fatalf("call %d rejected while closed: %v", i, err)
+			}
+			cb.record(settings, true, start)
+		}
+
+		if cb.status().State != circuitOpen {
+			t.Fatalf("expected open, got %s", circuitStateName(cb.status().State))
+		}
+		if err := cb.allow(settings, start.Add(time.Second)); !errors.Is(err, errCircuitOpen) {
+			t.Errorf("expected errCircuitOpen, got %v", err)
+		}
+	})
+
+	t.Run("Success resets the streak", func(t *testing.T) {
+		cb := newCircuitBreaker()
+		cb.record(settings, true, start)
+		cb.record(settings, true, start)
+		cb.record(settings, false, start)
+		cb.record(settings, true, start)
+
+		if cb.status().State != circuitClosed {
+			t.Errorf("expected closed, got %s", circuitStateName(cb.status().State))
+		}
+	})
+
+	t.Run("Failures outside the window start over", func(t *testing.T) {
+		cb := newCircuitBreaker()
+		cb.record(settings, true, start)
+		cb.record(settings, true, start)
+		cb.record(settings, true, start.Add(2*time.Minute))
+
+		if cb.status().State != circuitClosed {
+			t.Errorf("expected closed, got %s", circuitStateName(cb.status().State))
+		}
+		if cb.status().ConsecutiveFailures != 1 {
+			t.Errorf("expected 1 failure in the new window, got %d", cb.status().ConsecutiveFailures)
+		}
+	})
+
+	t.Run("Half-open admits one probe", func(t *testing.T) {
+		cb := newCircuitBreaker()
+		for i := 0; i < 3; i++ {
+			cb.record(settings, true, start)
+		}
+
+		afterCooldown := start.Add(settings.cooldown)
+		if err := cb.allow(settings, afterCooldown); err != nil {
+			t.Fatalf("expected probe to be admitted, got %v", err)
+		}
+		if cb.status().State != circuitHalfOpen {
+			t.Fatalf("expected half_open, got %s", circuitStateName(cb.status().State))
+		}
+		if err := cb.allow(settings, afterCooldown); !errors.Is(err, errCircuitOpen) {
+			t.Errorf("expected second caller to be rejected during the probe, got %v", err)
+		}
+
+		cb.record(settings, false, afterCooldown)
+		if cb.status().State != circuitClosed {
+			t.Errorf("expected closed after a successful probe, got %s", circuitStateName(cb.status().State))
+		}
+	})
+
+	t.Run("Failed probe re-opens", func(t *testing.T) {
+		cb := newCircuitBreaker()
+		for i := 0; i < 3; i++ {
+			cb.record(settings, true, start)
+		}
+
+		afterCooldown := start.Add(settings.cooldown)
+		_ = cb.allow(settings, afterCooldown)
+		cb.record(settings, true, afterCooldown)
+
+		if cb.status().State != circuitOpen {
+			t.Fatalf("expected open after a failed probe, got %s", circuitStateName(cb.status().State))
+		}
+		if err := cb.allow(settings, afterCooldown.Add(time.Second)); !errors.Is(err, errCircuitOpen) {
+			t.Errorf("expected a fresh cooldown, got %v", err)
+		}
+	})
+
+	t.Run("Threshold of zero disables the breaker", func(t *testing.T) {
+		disabled := circuitBreakerSettings{window: time.Minute, cooldown: time.Minute}
+		cb := newCircuitBreaker()
+		for i := 0; i < 10; i++ {
+			cb.record(disabled, true, start)
+		}
+		if err := cb.allow(disabled, start); err != nil {
+			t.Errorf("expected disabled breaker to allow calls, got %v", err)
+		}
+	})
+}
+
+func TestCircuitBreaker_TokenPath(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	issuer := &recordingTokenIssuer{err: &tokenExchangeError{StatusCode: http.StatusServiceUnavailable}}
+
+	b, err := FactoryWithOptions(WithTokenIssuer(issuer))(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"credentials_json":          `{"test": "creds"}`,
+			"validate_credentials":      false,
+			"max_retries":               0,
+			"circuit_breaker_threshold": 2,
+			"circuit_breaker_cooldown":  "1h",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "roles/payment-risk-engine",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"role_ids": "skyflow-role-read",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write role: err=%v resp=%v", err, resp)
+	}
+
+	readCreds := func(t *testing.T) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.ReadOperation,
+			Path:       "creds/payment-risk-engine",
+			Storage:    storage,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return resp
+	}
+
+	readHealth := func(t *testing.T) map[string]interface{} {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "health",
+			Storage:   storage,
+		})
+		if err != nil || resp == nil {
+			t.Fatalf("failed to read health: err=%v", err)
+		}
+		return resp.Data["circuit_breaker"].(map[string]interface{})
+	}
+
+	for i := 0; i < 2; i++ {
+		if resp := readCreds(t); resp == nil || !resp.IsError() {
+			t.Fatalf("expected failure %d to be returned", i)
+		}
+	}
+
+	t.Run("Open breaker fails fast with 503", func(t *testing.T) {
+		calls := len(issuer.requests)
+
+		resp := readCreds(t)
+		body := decodeRawErrorBody(t, resp, http.StatusServiceUnavailable)
+		if body.Data.ErrorCode != tokenErrorCircuitOpen {
+			t.Errorf("error_code = %q, want %q", body.Data.ErrorCode, tokenErrorCircuitOpen)
+		}
+		if len(issuer.requests) != calls {
+			t.Error("expected the issuer not to be called while the breaker is open")
+		}
+	})
+
+	t.Run("Health reports the open breaker", func(t *testing.T) {
+		status := readHealth(t)
+		if status["state"] != "open" {
+			t.Errorf("expected state open, got %v", status["state"])
+		}
+		if _, ok := status["retry_at"]; !ok {
+			t.Error("expected retry_at while open")
+		}
+	})
+
+	t.Run("Config write closes the breaker", func(t *testing.T) {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "config",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"description":          "rotated credentials",
+				"validate_credentials": false,
+			},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+		}
+
+		if status := readHealth(t); status["state"] != "closed" {
+			t.Errorf("expected state closed, got %v", status["state"])
+		}
+	})
+}
+
+// credentialsFailingIssuer fails token calls made with one set of credentials
+type credentialsFailingIssuer struct {
+	failing string
+}
+
+func (f *credentialsFailingIssuer) IssueToken(ctx context.Context, req *TokenRequest) (*common.TokenResponse, error) {
+	if req.CredentialsJSON == f.failing {
+		return nil, &tokenExchangeError{StatusCode: http.StatusServiceUnavailable}
+	}
+	return &common.TokenResponse{AccessToken: "token", TokenType: "Bearer"}, nil
+}
+
+func TestCircuitBreaker_PerCredentialSet(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	b, err := FactoryWithOptions(WithTokenIssuer(&credentialsFailingIssuer{failing: `{"sa": "broken"}`}))(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	request := func(t *testing.T, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  op,
+			Path:       path,
+			Storage:    storage,
+			Data:       data,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil {
+			t.Fatalf("%s %s: unexpected error: %v", op, path, err)
+		}
+		return resp
+	}
+
+	writes := []struct {
+		path string
+		data map[string]interface{}
+	}{
+		{"config", map[string]interface{}{
+			"credentials_json":          `{"sa": "mount"}`,
+			"validate_credentials":      false,
+			"max_retries":               0,
+			"circuit_breaker_threshold": 1,
+			"circuit_breaker_cooldown":  "1h",
+		}},
+		{"credentials/broken-sa", map[string]interface{}{
+			"credentials_json":     `{"sa": "broken"}`,
+			"validate_credentials": false,
+		}},
+		{"credentials/healthy-sa", map[string]interface{}{
+			"credentials_json":     `{"sa": "healthy"}`,
+			"validate_credentials": false,
+		}},
+		{"roles/broken", map[string]interface{}{"role_ids": "skyflow-role-read", "credential_set": "broken-sa"}},
+		{"roles/healthy", map[string]interface{}{"role_ids": "skyflow-role-read", "credential_set": "healthy-sa"}},
+		{"roles/mount", map[string]interface{}{"role_ids": "skyflow-role-read"}},
+	}
+	for _, w := range writes {
+		if resp := request(t, logical.UpdateOperation, w.path, w.data); resp != nil && resp.IsError() {
+			t.Fatalf("failed to write %s: %v", w.path, resp.Error())
+		}
+	}
+
+	// One failure opens the broken set's breaker
+	if resp := request(t, logical.ReadOperation, "creds/broken", nil); resp == nil || !resp.IsError() {
+		t.Fatal("expected the broken set's token call to fail")
+	}
+	body := decodeRawErrorBody(t, request(t, logical.ReadOperation, "creds/broken", nil), http.StatusServiceUnavailable)
+	if body.Data.ErrorCode != tokenErrorCircuitOpen {
+		t.Fatalf("error_code = %q, want %q", body.Data.ErrorCode, tokenErrorCircuitOpen)
+	}
+
+	t.Run("Roles on other credentials are not failed fast", func(t *testing.T) {
+		for _, role := range []string{"healthy", "mount"} {
+			if resp := request(t, logical.ReadOperation, "creds/"+role, nil); resp == nil || resp.IsError() {
+				t.Errorf("expected creds/%s to succeed, got %v", role, resp)
+			}
+		}
+	})
+
+	t.Run("Health reports each breaker", func(t *testing.T) {
+		status := request(t, logical.ReadOperation, "health", nil).Data["circuit_breaker"].(map[string]interface{})
+		if status["state"] != "closed" {
+			t.Errorf("expected the mount breaker closed, got %v", status["state"])
+		}
+		sets := status["credential_sets"].(map[string]interface{})
+		if state := sets["broken-sa"].(map[string]interface{})["state"]; state != "open" {
+			t.Errorf("expected broken-sa open, got %v", state)
+		}
+		if state := sets["healthy-sa"].(map[string]interface{})["state"]; state != "closed" {
+			t.Errorf("expected healthy-sa closed, got %v", state)
+		}
+	})
+
+	t.Run("Credential set write closes only its breaker", func(t *testing.T) {
+		tripNow := circuitBreakerSettings{threshold: 1, window: time.Minute, cooldown: time.Hour}
+		b.(*skyflowBackend).breakers.get("healthy-sa").record(tripNow, true, time.Now())
+
+		resp := request(t, logical.UpdateOperation, "credentials/broken-sa", map[string]interface{}{
+			"credentials_json":     `{"sa": "fixed"}`,
+			"validate_credentials": false,
+		})
+		if resp != nil && resp.IsError() {
+			t.Fatalf("failed to write credential set: %v", resp.Error())
+		}
+
+		breakers := b.(*skyflowBackend).breakers.statuses()
+		if breakers["broken-sa"].State != circuitClosed {
+			t.Errorf("expected broken-sa closed, got %s", circuitStateName(breakers["broken-sa"].State))
+		}
+		if breakers["healthy-sa"].State != circuitOpen {
+			t.Errorf("expected healthy-sa to stay open, got %s", circuitStateName(breakers["healthy-sa"].State))
+		}
+	})
+}
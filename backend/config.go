@@ -4,22 +4,62 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"net/url"
 	"time"
 
 	"github.com/hashicorp/vault/sdk/logical"
-	"github.com/skyflowapi/skyflow-go/v2/serviceaccount"
-	"github.com/skyflowapi/skyflow-go/v2/utils/common"
-	skyflowError "github.com/skyflowapi/skyflow-go/v2/utils/error"
-	"github.com/skyflowapi/skyflow-go/v2/utils/logger"
 )
 
+// defaultMaxRoleIDs is the number of Skyflow role IDs a role may hold unless the mount overrides it
+const defaultMaxRoleIDs = 10
+
+// defaultHistoryMaxVersions is how many config versions are kept in history unless the mount overrides it
+const defaultHistoryMaxVersions = 10
+
 // skyflowConfig represents the backend configuration
 type skyflowConfig struct {
 	// Credentials - one of these must be provided
 	CredentialsFilePath string `json:"credentials_file_path,omitempty"`
 	CredentialsJSON     string `json:"credentials_json,omitempty"`
 
+	// Token cache - cached tokens are refreshed this long before they expire
+	TokenCacheRefreshWindow time.Duration `json:"token_cache_refresh_window,omitempty"`
+
+	// Role ID policy - limits which and how many Skyflow role IDs a role may hold
+	MaxRoleIDs     int      `json:"max_role_ids,omitempty"`
+	AllowedRoleIDs []string `json:"allowed_role_ids,omitempty"`
+
+	// History retention - older config_history entries are pruned on write
+	HistoryMaxVersions int           `json:"history_max_versions,omitempty"`
+	HistoryMaxAge      time.Duration `json:"history_max_age,omitempty"`
+
+	// Skyflow management API used by rotate-root
+	ManagementURL string `json:"management_url,omitempty"`
+
+	// Scheduled rotation - the key is rotated once RotationPeriod has passed since the last rotation
+	RotationPeriod time.Duration `json:"rotation_period,omitempty"`
+	LastRotatedAt  time.Time     `json:"last_rotated_at,omitempty"`
+
+	// Token issuer - "sdk" (default) uses the Skyflow SDK, "native" exchanges the JWT assertion in-plugin.
+	// The HTTP settings apply to the native issuer only.
+	TokenIssuer string        `json:"token_issuer,omitempty"`
+	HTTPTimeout time.Duration `json:"http_timeout,omitempty"`
+	HTTPProxy   string        `json:"http_proxy,omitempty"`
+	CABundle    string        `json:"ca_bundle,omitempty"`
+
+	// Token call limits - each call is bounded by RequestTimeout, including up to MaxRetries
+	// jittered retries of transient failures starting RetryBackoff apart. MaxRetries is a pointer
+	// so an explicit 0 can be told apart from a config written before it existed.
+	RequestTimeout time.Duration `json:"request_timeout,omitempty"`
+	MaxRetries     *int          `json:"max_retries,omitempty"`
+	RetryBackoff   time.Duration `json:"retry_backoff,omitempty"`
+
+	// Circuit breaker - token calls fail fast for CircuitBreakerCooldown once CircuitBreakerThreshold
+	// consecutive failures happen within CircuitBreakerWindow; an explicit threshold of 0 disables it
+	CircuitBreakerThreshold *int          `json:"circuit_breaker_threshold,omitempty"`
+	CircuitBreakerWindow    time.Duration `json:"circuit_breaker_window,omitempty"`
+	CircuitBreakerCooldown  time.Duration `json:"circuit_breaker_cooldown,omitempty"`
+
 	// Metadata
 	Description string    `json:"description,omitempty"`
 	Tags        []string  `json:"tags,omitempty"`
@@ -30,9 +70,125 @@ type skyflowConfig struct {
 // defaultConfig returns a config with default values
 func defaultConfig() *skyflowConfig {
 	return &skyflowConfig{
-		Version:     1,
-		LastUpdated: time.Now(),
+		TokenCacheRefreshWindow: defaultTokenCacheRefreshWindow,
+		MaxRoleIDs:              defaultMaxRoleIDs,
+		HistoryMaxVersions:      defaultHistoryMaxVersions,
+		Version:                 1,
+		LastUpdated:             time.Now(),
+	}
+}
+
+// tokenCacheRefreshWindow returns the refresh window, falling back to the default for older configs
+func (c *skyflowConfig) tokenCacheRefreshWindow() time.Duration {
+	if c.TokenCacheRefreshWindow <= 0 {
+		return defaultTokenCacheRefreshWindow
+	}
+	return c.TokenCacheRefreshWindow
+}
+
+// maxRoleIDs returns the role ID cap, falling back to the default for older configs
+func (c *skyflowConfig) maxRoleIDs() int {
+	if c.MaxRoleIDs <= 0 {
+		return defaultMaxRoleIDs
+	}
+	return c.MaxRoleIDs
+}
+
+// historyMaxVersions returns the history retention cap, falling back to the default for older configs
+func (c *skyflowConfig) historyMaxVersions() int {
+	if c.HistoryMaxVersions <= 0 {
+		return defaultHistoryMaxVersions
+	}
+	return c.HistoryMaxVersions
+}
+
+// managementURL returns the management API base URL, falling back to the default
+func (c *skyflowConfig) managementURL() string {
+	if c.ManagementURL == "" {
+		return defaultManagementURL
+	}
+	return c.ManagementURL
+}
+
+// nextRotationAt returns when the key is next due for scheduled rotation, or the zero time if
+// scheduled rotation is off. Keys that were never rotated are measured from the last config write.
+func (c *skyflowConfig) nextRotationAt() time.Time {
+	if c.RotationPeriod <= 0 || c.CredentialsJSON == "" {
+		return time.Time{}
+	}
+
+	base := c.LastRotatedAt
+	if base.IsZero() {
+		base = c.LastUpdated
 	}
+	return base.Add(c.RotationPeriod)
+}
+
+// tokenIssuer returns the configured token issuer, falling back to the SDK for older configs
+func (c *skyflowConfig) tokenIssuer() string {
+	if c.TokenIssuer == "" {
+		return tokenIssuerSDK
+	}
+	return c.TokenIssuer
+}
+
+// httpTimeout returns the native issuer's HTTP timeout, falling back to the default
+func (c *skyflowConfig) httpTimeout() time.Duration {
+	if c.HTTPTimeout <= 0 {
+		return defaultHTTPTimeout
+	}
+	return c.HTTPTimeout
+}
+
+// requestTimeout returns the token call timeout, falling back to the default
+func (c *skyflowConfig) requestTimeout() time.Duration {
+	if c.RequestTimeout <= 0 {
+		return defaultRequestTimeout
+	}
+	return c.RequestTimeout
+}
+
+// maxRetries returns how many times a transient failure is retried, falling back to the default for older configs
+func (c *skyflowConfig) maxRetries() int {
+	if c.MaxRetries == nil {
+		return defaultMaxRetries
+	}
+	return *c.MaxRetries
+}
+
+// retryBackoff returns the base retry delay, falling back to the default
+func (c *skyflowConfig) retryBackoff() time.Duration {
+	if c.RetryBackoff <= 0 {
+		return defaultRetryBackoff
+	}
+	return c.RetryBackoff
+}
+
+// circuitBreakerSettings returns the breaker thresholds, falling back to the defaults
+func (c *skyflowConfig) circuitBreakerSettings() circuitBreakerSettings {
+	settings := circuitBreakerSettings{
+		threshold: defaultCircuitBreakerThreshold,
+		window:    c.CircuitBreakerWindow,
+		cooldown:  c.CircuitBreakerCooldown,
+	}
+	if c.CircuitBreakerThreshold != nil {
+		settings.threshold = *c.CircuitBreakerThreshold
+	}
+	if settings.window <= 0 {
+		settings.window = defaultCircuitBreakerWindow
+	}
+	if settings.cooldown <= 0 {
+		settings.cooldown = defaultCircuitBreakerCooldown
+	}
+	return settings
+}
+
+// credentialsType returns the credential source type for responses and telemetry
+func (c *skyflowConfig) credentialsType() string {
+	if c.CredentialsFilePath != "" {
+		return "file_path"
+	}
+	return "json"
 }
 
 // validate checks if the configuration is valid
@@ -54,6 +210,85 @@ func (c *skyflowConfig) validate() error {
 		}
 	}
 
+	if c.TokenCacheRefreshWindow < 0 {
+		return fmt.Errorf("token_cache_refresh_window cannot be negative")
+	}
+
+	if c.MaxRoleIDs < 0 {
+		return fmt.Errorf("max_role_ids cannot be negative")
+	}
+
+	if c.HistoryMaxVersions < 0 {
+		return fmt.Errorf("history_max_versions cannot be negative")
+	}
+
+	if c.HistoryMaxAge < 0 {
+		return fmt.Errorf("history_max_age cannot be negative")
+	}
+
+	if c.RotationPeriod < 0 {
+		return fmt.Errorf("rotation_period cannot be negative")
+	}
+
+	if c.RotationPeriod > 0 {
+		if c.RotationPeriod < minRotationPeriod {
+			return fmt.Errorf("rotation_period must be at least %s", minRotationPeriod)
+		}
+		if c.CredentialsJSON == "" {
+			return fmt.Errorf("rotation_period requires credentials_json")
+		}
+	}
+
+	if c.ManagementURL != "" {
+		u, err := url.Parse(c.ManagementURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("management_url must be an http or https URL")
+		}
+	}
+
+	switch c.tokenIssuer() {
+	case tokenIssuerSDK, tokenIssuerNative:
+	default:
+		return fmt.Errorf("token_issuer must be %q or %q", tokenIssuerSDK, tokenIssuerNative)
+	}
+
+	if c.HTTPTimeout < 0 {
+		return fmt.Errorf("http_timeout cannot be negative")
+	}
+
+	if c.HTTPProxy != "" {
+		u, err := url.Parse(c.HTTPProxy)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("http_proxy must be an http or https URL")
+		}
+	}
+
+	if c.CABundle != "" {
+		if _, err := parseCABundle(c.CABundle); err != nil {
+			return err
+		}
+	}
+
+	if c.RequestTimeout < 0 {
+		return fmt.Errorf("request_timeout cannot be negative")
+	}
+
+	if c.MaxRetries != nil && (*c.MaxRetries < 0 || *c.MaxRetries > maxMaxRetries) {
+		return fmt.Errorf("max_retries must be between 0 and %d", maxMaxRetries)
+	}
+
+	if c.RetryBackoff < 0 {
+		return fmt.Errorf("retry_backoff_ms cannot be negative")
+	}
+
+	if c.CircuitBreakerThreshold != nil && *c.CircuitBreakerThreshold < 0 {
+		return fmt.Errorf("circuit_breaker_threshold cannot be negative")
+	}
+
+	if c.CircuitBreakerWindow < 0 || c.CircuitBreakerCooldown < 0 {
+		return fmt.Errorf("circuit_breaker_window and circuit_breaker_cooldown cannot be negative")
+	}
+
 	return nil
 }
 
@@ -92,6 +327,16 @@ func (b *skyflowBackend) saveConfig(ctx context.Context, s logical.Storage, conf
 
 // saveConfigWithHistory stores config and maintains version history
 func (b *skyflowBackend) saveConfigWithHistory(ctx context.Context, s logical.Storage, config *skyflowConfig) error {
+	// Continue after the newest known version so a recreated or restored config
+	// never overwrites an existing history entry
+	latest, err := b.latestConfigVersion(ctx, s)
+	if err != nil {
+		return err
+	}
+	if latest > config.Version {
+		config.Version = latest
+	}
+
 	// Increment version
 	config.Version++
 	config.LastUpdated = time.Now()
@@ -102,12 +347,8 @@ func (b *skyflowBackend) saveConfigWithHistory(ctx context.Context, s logical.St
 	}
 
 	// Save to history
-	historyKey := fmt.Sprintf("config_history/%d", config.Version)
-	historyEntry, err := logical.StorageEntryJSON(historyKey, map[string]interface{}{
-		"version":     config.Version,
-		"timestamp":   config.LastUpdated.Format(time.RFC3339),
-		"description": config.Description,
-	})
+	historyKey := fmt.Sprintf("%s%d", configHistoryStoragePrefix, config.Version)
+	historyEntry, err := logical.StorageEntryJSON(historyKey, newConfigHistoryEntry(config))
 	if err != nil {
 		return fmt.Errorf("failed to create history entry: %w", err)
 	}
@@ -116,6 +357,11 @@ func (b *skyflowBackend) saveConfigWithHistory(ctx context.Context, s logical.St
 		b.Logger().Warn("failed to save config history", "error", err)
 	}
 
+	// Trim history outside the retention settings
+	if err := b.pruneConfigHistory(ctx, s, config); err != nil {
+		b.Logger().Warn("failed to prune config history", "error", err)
+	}
+
 	return nil
 }
 
@@ -129,31 +375,17 @@ func (b *skyflowBackend) deleteConfig(ctx context.Context, s logical.Storage) er
 }
 
 // validateCredentials tests that credentials can generate tokens
-func (c *skyflowConfig) validateCredentials() (returnErr error) {
-	// Recover from SDK panics - defensive measure for unexpected SDK behavior
-	defer func() {
-		if r := recover(); r != nil {
-			returnErr = fmt.Errorf("credential validation panic: %v", r)
-		}
-	}()
-
-	var token *common.TokenResponse
-	var sdkErr *skyflowError.SkyflowError
-
-	opts := common.BearerTokenOptions{LogLevel: logger.DEBUG}
+func (c *skyflowConfig) validateCredentials(ctx context.Context, issuer TokenIssuer) error {
+	ctx, cancel := context.WithTimeout(ctx, c.requestTimeout())
+	defer cancel()
 
 	// Try to generate a token to validate credentials
-	if c.CredentialsFilePath != "" {
-		if _, statErr := os.Stat(c.CredentialsFilePath); os.IsNotExist(statErr) {
-			return fmt.Errorf("credentials file not found: %s: %w", c.CredentialsFilePath, statErr)
-		}
-		token, sdkErr = serviceaccount.GenerateBearerToken(c.CredentialsFilePath, opts)
-	} else if c.CredentialsJSON != "" {
-		token, sdkErr = serviceaccount.GenerateBearerTokenFromCreds(c.CredentialsJSON, opts)
-	}
-
-	if sdkErr != nil {
-		return fmt.Errorf("credential validation failed: %w", sdkErr)
+	token, err := issuer.IssueToken(ctx, &TokenRequest{
+		CredentialsJSON:     c.CredentialsJSON,
+		CredentialsFilePath: c.CredentialsFilePath,
+	})
+	if err != nil {
+		return fmt.Errorf("credential validation failed: %w", err)
 	}
 
 	if token == nil || token.AccessToken == "" {
@@ -0,0 +1,169 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// configHistoryStoragePrefix is the storage prefix for config version history
+const configHistoryStoragePrefix = "config_history/"
+
+// configHistoryEntry is the record kept for each saved config version.
+// Config holds a full copy of that version for rollback; it is stored seal-wrapped
+// and never returned by the history endpoints.
+type configHistoryEntry struct {
+	Version                int       `json:"version"`
+	Timestamp              time.Time `json:"timestamp"`
+	Description            string    `json:"description,omitempty"`
+	CredentialsType        string    `json:"credentials_type,omitempty"`
+	CredentialsFingerprint string    `json:"credentials_fingerprint,omitempty"`
+
+	Config *skyflowConfig `json:"config,omitempty"`
+}
+
+// newConfigHistoryEntry builds the history entry for a config version
+func newConfigHistoryEntry(config *skyflowConfig) *configHistoryEntry {
+	snapshot := *config
+	return &configHistoryEntry{
+		Version:                config.Version,
+		Timestamp:              config.LastUpdated,
+		Description:            config.Description,
+		CredentialsType:        config.credentialsType(),
+		CredentialsFingerprint: config.credentialsFingerprint(),
+		Config:                 &snapshot,
+	}
+}
+
+// credentialsFingerprint returns a short, non-reversible identifier for the configured credentials
+// so operators can tell versions apart without exposing the secret
+func (c *skyflowConfig) credentialsFingerprint() string {
+	source := "json:" + c.CredentialsJSON
+	if c.CredentialsFilePath != "" {
+		source = "file_path:" + c.CredentialsFilePath
+	}
+
+	sum := sha256.Sum256([]byte(source))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// responseData returns the config/history/<version> response body
+func (e *configHistoryEntry) responseData() map[string]interface{} {
+	return map[string]interface{}{
+		"version":                 e.Version,
+		"timestamp":               e.Timestamp.Format(time.RFC3339),
+		"description":             e.Description,
+		"credentials_type":        e.CredentialsType,
+		"credentials_fingerprint": e.CredentialsFingerprint,
+		"restorable":              e.Config != nil,
+	}
+}
+
+// getConfigHistory retrieves a config history entry from storage
+func (b *skyflowBackend) getConfigHistory(ctx context.Context, s logical.Storage, version int) (*configHistoryEntry, error) {
+	entry, err := s.Get(ctx, configHistoryStoragePrefix+strconv.Itoa(version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config history: %w", err)
+	}
+
+	if entry == nil {
+		return nil, nil
+	}
+
+	history := &configHistoryEntry{}
+	if err := entry.DecodeJSON(history); err != nil {
+		return nil, fmt.Errorf("failed to decode config history: %w", err)
+	}
+
+	return history, nil
+}
+
+// listConfigHistory returns the stored config history versions in ascending order
+func (b *skyflowBackend) listConfigHistory(ctx context.Context, s logical.Storage) ([]int, error) {
+	keys, err := s.List(ctx, configHistoryStoragePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list config history: %w", err)
+	}
+
+	versions := make([]int, 0, len(keys))
+	for _, key := range keys {
+		version, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// latestConfigVersion returns the highest config version in use, from the current
+// config or, if it was deleted, from history
+func (b *skyflowBackend) latestConfigVersion(ctx context.Context, s logical.Storage) (int, error) {
+	latest := 0
+
+	config, err := b.getConfig(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+	if config != nil {
+		latest = config.Version
+	}
+
+	versions, err := b.listConfigHistory(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+	if len(versions) > 0 && versions[len(versions)-1] > latest {
+		latest = versions[len(versions)-1]
+	}
+
+	return latest, nil
+}
+
+// pruneConfigHistory deletes history entries outside the config's retention settings.
+// The entry for the current version is always kept.
+func (b *skyflowBackend) pruneConfigHistory(ctx context.Context, s logical.Storage, config *skyflowConfig) error {
+	versions, err := b.listConfigHistory(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	maxVersions := config.historyMaxVersions()
+	cutoff := time.Time{}
+	if config.HistoryMaxAge > 0 {
+		cutoff = time.Now().Add(-config.HistoryMaxAge)
+	}
+
+	for i, version := range versions {
+		if version == config.Version {
+			continue
+		}
+
+		expired := len(versions)-i > maxVersions
+		if !expired && !cutoff.IsZero() {
+			history, err := b.getConfigHistory(ctx, s, version)
+			if err != nil {
+				return err
+			}
+			expired = history != nil && history.Timestamp.Before(cutoff)
+		}
+
+		if !expired {
+			continue
+		}
+
+		if err := s.Delete(ctx, configHistoryStoragePrefix+strconv.Itoa(version)); err != nil {
+			return fmt.Errorf("failed to delete config history: %w", err)
+		}
+	}
+
+	return nil
+}
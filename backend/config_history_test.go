@@ -0,0 +1,301 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestConfigHistory_Fingerprint(t *testing.T) {
+	a := &skyflowConfig{CredentialsJSON: `{"clientID": "a"}`}
+	b := &skyflowConfig{CredentialsJSON: `{"clientID": "b"}`}
+
+	if a.credentialsFingerprint() == b.credentialsFingerprint() {
+		t.Error("expected different credentials to have different fingerprints")
+	}
+
+	if a.credentialsFingerprint() != a.credentialsFingerprint() {
+		t.Error("expected fingerprint to be stable")
+	}
+
+	fp := a.credentialsFingerprint()
+	if !strings.HasPrefix(fp, "sha256:") || strings.Contains(fp, "clientID") {
+		t.Errorf("unexpected fingerprint: %s", fp)
+	}
+}
+
+func TestConfigHistory_Retention(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	config := &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	backend := b.(*skyflowBackend)
+
+	t.Run("Max versions", func(t *testing.T) {
+		cfg := &skyflowConfig{
+			CredentialsFilePath: "/test/path.json",
+			HistoryMaxVersions:  3,
+		}
+		for i := 0; i < 5; i++ {
+			if err := backend.saveConfigWithHistory(ctx, storage, cfg); err != nil {
+				t.Fatalf("failed to save config: %v", err)
+			}
+		}
+
+		versions, err := backend.listConfigHistory(ctx, storage)
+		if err != nil {
+			t.Fatalf("failed to list history: %v", err)
+		}
+		if len(versions) != 3 || versions[0] != 3 || versions[2] != 5 {
+			t.Errorf("expected versions [3 4 5], got %v", versions)
+		}
+	})
+
+	t.Run("Max age", func(t *testing.T) {
+		// Backdate version 4 past the age limit; version 3 falls out by count
+		old := &configHistoryEntry{Version: 4, Timestamp: time.Now().Add(-48 * time.Hour)}
+		entry, err := logical.StorageEntryJSON(configHistoryStoragePrefix+"4", old)
+		if err != nil {
+			t.Fatalf("failed to create entry: %v", err)
+		}
+		if err := storage.Put(ctx, entry); err != nil {
+			t.Fatalf("failed to put entry: %v", err)
+		}
+
+		cfg, err := backend.getConfig(ctx, storage)
+		if err != nil || cfg == nil {
+			t.Fatalf("failed to get config: %v", err)
+		}
+		cfg.HistoryMaxAge = 24 * time.Hour
+		if err := backend.saveConfigWithHistory(ctx, storage, cfg); err != nil {
+			t.Fatalf("failed to save config: %v", err)
+		}
+
+		versions, err := backend.listConfigHistory(ctx, storage)
+		if err != nil {
+			t.Fatalf("failed to list history: %v", err)
+		}
+		if len(versions) != 2 || versions[0] != 5 || versions[1] != 6 {
+			t.Errorf("expected versions [5 6], got %v", versions)
+		}
+	})
+}
+
+func TestConfigHistory_Paths(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	config := &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation:  op,
+			Path:       path,
+			Storage:    storage,
+			Data:       data,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+	}
+
+	for _, creds := range []string{`{"test": "first"}`, `{"test": "second"}`} {
+		resp, err := request(logical.UpdateOperation, "config", map[string]interface{}{
+			"credentials_json":     creds,
+			"description":          "Config " + creds,
+			"validate_credentials": false,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+		}
+	}
+
+	t.Run("List history", func(t *testing.T) {
+		resp, err := request(logical.ListOperation, "config/history/", nil)
+		if err != nil || resp == nil {
+			t.Fatalf("failed to list history: err=%v", err)
+		}
+		keys, _ := resp.Data["keys"].([]string)
+		if len(keys) != 2 {
+			t.Errorf("expected 2 versions, got %v", keys)
+		}
+	})
+
+	t.Run("Read history hides secret", func(t *testing.T) {
+		first, err := request(logical.ReadOperation, "config/history/2", nil)
+		if err != nil || first == nil {
+			t.Fatalf("failed to read history: err=%v", err)
+		}
+		second, err := request(logical.ReadOperation, "config/history/3", nil)
+		if err != nil || second == nil {
+			t.Fatalf("failed to read history: err=%v", err)
+		}
+
+		for _, resp := range []*logical.Response{first, second} {
+			if _, ok := resp.Data["credentials_json"]; ok {
+				t.Error("credentials_json must not be returned")
+			}
+			if resp.Data["credentials_type"] != "json" {
+				t.Errorf("expected credentials_type 'json', got %v", resp.Data["credentials_type"])
+			}
+		}
+
+		if first.Data["credentials_fingerprint"] == second.Data["credentials_fingerprint"] {
+			t.Error("expected fingerprints to differ between versions")
+		}
+	})
+
+	t.Run("Read missing version", func(t *testing.T) {
+		resp, err := request(logical.ReadOperation, "config/history/99", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp != nil {
+			t.Error("expected nil response for missing version")
+		}
+	})
+}
+
+func TestConfigHistory_Rollback(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	config := &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	backend := b.(*skyflowBackend)
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation:  op,
+			Path:       path,
+			Storage:    storage,
+			Data:       data,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+	}
+
+	for _, creds := range []string{`{"test": "good"}`, `{"test": "bad"}`} {
+		resp, err := request(logical.UpdateOperation, "config", map[string]interface{}{
+			"credentials_json":     creds,
+			"validate_credentials": false,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+		}
+	}
+
+	t.Run("History read does not expose stored config", func(t *testing.T) {
+		resp, err := request(logical.ReadOperation, "config/history/2", nil)
+		if err != nil || resp == nil {
+			t.Fatalf("failed to read history: err=%v", err)
+		}
+		if _, ok := resp.Data["config"]; ok {
+			t.Error("stored config must not be returned")
+		}
+		if resp.Data["restorable"] != true {
+			t.Error("expected version to be restorable")
+		}
+	})
+
+	t.Run("Rollback restores credentials as a new version", func(t *testing.T) {
+		resp, err := request(logical.UpdateOperation, "config/rollback", map[string]interface{}{
+			"version":              2,
+			"validate_credentials": false,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to roll back: err=%v resp=%v", err, resp)
+		}
+		if resp.Data["version"] != 4 || resp.Data["restored_version"] != 2 {
+			t.Errorf("unexpected rollback response: %v", resp.Data)
+		}
+
+		cfg, err := backend.getConfig(ctx, storage)
+		if err != nil || cfg == nil {
+			t.Fatalf("failed to get config: %v", err)
+		}
+		if cfg.CredentialsJSON != `{"test": "good"}` || cfg.Version != 4 {
+			t.Errorf("expected version 4 with restored credentials, got version %d", cfg.Version)
+		}
+	})
+
+	t.Run("Rollback after delete keeps version sequence", func(t *testing.T) {
+		if _, err := request(logical.DeleteOperation, "config", nil); err != nil {
+			t.Fatalf("failed to delete config: %v", err)
+		}
+
+		resp, err := request(logical.UpdateOperation, "config/rollback", map[string]interface{}{
+			"version":              3,
+			"validate_credentials": false,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to roll back: err=%v resp=%v", err, resp)
+		}
+		if resp.Data["version"] != 5 {
+			t.Errorf("expected version 5, got %v", resp.Data["version"])
+		}
+	})
+
+	t.Run("Rollback to missing version", func(t *testing.T) {
+		resp, err := request(logical.UpdateOperation, "config/rollback", map[string]interface{}{
+			"version": 99,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected error response for missing version")
+		}
+	})
+
+	t.Run("Rollback to legacy entry without stored config", func(t *testing.T) {
+		legacy, err := logical.StorageEntryJSON(configHistoryStoragePrefix+"1", map[string]interface{}{
+			"version":   1,
+			"timestamp": time.Now().Format(time.RFC3339),
+		})
+		if err != nil {
+			t.Fatalf("failed to create entry: %v", err)
+		}
+		if err := storage.Put(ctx, legacy); err != nil {
+			t.Fatalf("failed to put entry: %v", err)
+		}
+
+		resp, err := request(logical.UpdateOperation, "config/rollback", map[string]interface{}{
+			"version": 1,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected error response for legacy entry")
+		}
+	})
+}
@@ -3,6 +3,7 @@ package backend
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -63,6 +64,88 @@ func TestConfig_Validate(t *testing.T) {
 			wantError: true,
 			errorMsg:  "credentials_json must be valid JSON",
 		},
+		{
+			name: "Native issuer with proxy",
+			config: &skyflowConfig{
+				CredentialsJSON: `{"key": "value"}`,
+				TokenIssuer:     "native",
+				HTTPProxy:       "http://proxy.internal:3128",
+			},
+			wantError: false,
+		},
+		{
+			name: "Unknown token issuer",
+			config: &skyflowConfig{
+				CredentialsJSON: `{"key": "value"}`,
+				TokenIssuer:     "grpc",
+			},
+			wantError: true,
+			errorMsg:  "token_issuer must be",
+		},
+		{
+			name: "Negative http timeout",
+			config: &skyflowConfig{
+				CredentialsJSON: `{"key": "value"}`,
+				HTTPTimeout:     -time.Second,
+			},
+			wantError: true,
+			errorMsg:  "http_timeout cannot be negative",
+		},
+		{
+			name: "Proxy without scheme",
+			config: &skyflowConfig{
+				CredentialsJSON: `{"key": "value"}`,
+				HTTPProxy:       "proxy.internal:3128",
+			},
+			wantError: true,
+			errorMsg:  "http_proxy must be an http or https URL",
+		},
+		{
+			name: "CA bundle without certificates",
+			config: &skyflowConfig{
+				CredentialsJSON: `{"key": "value"}`,
+				CABundle:        "not a certificate",
+			},
+			wantError: true,
+			errorMsg:  "ca_bundle must contain at least one PEM certificate",
+		},
+		{
+			name: "Retry settings",
+			config: &skyflowConfig{
+				CredentialsJSON: `{"key": "value"}`,
+				RequestTimeout:  10 * time.Second,
+				MaxRetries:      intPtr(3),
+				RetryBackoff:    100 * time.Millisecond,
+			},
+			wantError: false,
+		},
+		{
+			name: "Too many retries",
+			config: &skyflowConfig{
+				CredentialsJSON: `{"key": "value"}`,
+				MaxRetries:      intPtr(maxMaxRetries + 1),
+			},
+			wantError: true,
+			errorMsg:  "max_retries must be between 0 and 10",
+		},
+		{
+			name: "Negative request timeout",
+			config: &skyflowConfig{
+				CredentialsJSON: `{"key": "value"}`,
+				RequestTimeout:  -time.Second,
+			},
+			wantError: true,
+			errorMsg:  "request_timeout cannot be negative",
+		},
+		{
+			name: "Negative circuit breaker threshold",
+			config: &skyflowConfig{
+				CredentialsJSON:         `{"key": "value"}`,
+				CircuitBreakerThreshold: intPtr(-1),
+			},
+			wantError: true,
+			errorMsg:  "circuit_breaker_threshold cannot be negative",
+		},
 	}
 
 	for _, tt := range tests {
@@ -239,7 +322,7 @@ func TestConfig_ValidateCredentials(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// This should NOT panic, but return an error gracefully
-			err := tt.config.validateCredentials()
+			err := tt.config.validateCredentials(context.Background(), sdkTokenIssuer{})
 			if err == nil {
 				t.Error("expected error for invalid credentials, got nil")
 			}
@@ -252,10 +335,115 @@ func TestConfig_ValidateCredentials_NoCredentials(t *testing.T) {
 	// Test with no credentials set - should return error without panic
 	config := &skyflowConfig{}
 
-	err := config.validateCredentials()
+	err := config.validateCredentials(context.Background(), sdkTokenIssuer{})
 	// With no credentials, token will be nil and should return error
 	if err == nil {
 		t.Error("expected error when no credentials provided")
 	}
 	t.Logf("Got expected error: %v", err)
 }
+
+// intPtr returns a pointer to v, for optional config fields
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestConfig_StoredBeforeSettingsExisted(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	b, err := Factory(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+	backend := b.(*skyflowBackend)
+
+	getStored := func(t *testing.T, raw string) *skyflowConfig {
+		t.Helper()
+		if err := storage.Put(ctx, &logical.StorageEntry{Key: "config", Value: []byte(raw)}); err != nil {
+			t.Fatalf("failed to store config: %v", err)
+		}
+		cfg, err := backend.getConfig(ctx, storage)
+		if err != nil || cfg == nil {
+			t.Fatalf("failed to get config: cfg=%v err=%v", cfg, err)
+		}
+		return cfg
+	}
+
+	t.Run("Unset max_retries falls back to the default", func(t *testing.T) {
+		cfg := getStored(t, `{"credentials_json": "{}", "version": 3}`)
+		if got := cfg.maxRetries(); got != defaultMaxRetries {
+			t.Errorf("maxRetries() = %d, want %d", got, defaultMaxRetries)
+		}
+	})
+
+	t.Run("Explicit zero max_retries is kept", func(t *testing.T) {
+		cfg := getStored(t, `{"credentials_json": "{}", "version": 3, "max_retries": 0}`)
+		if got := cfg.maxRetries(); got != 0 {
+			t.Errorf("maxRetries() = %d, want 0", got)
+		}
+	})
+
+	t.Run("Unset circuit_breaker_threshold falls back to the default", func(t *testing.T) {
+		cfg := getStored(t, `{"credentials_json": "{}", "version": 3}`)
+		if got := cfg.circuitBreakerSettings().threshold; got != defaultCircuitBreakerThreshold {
+			t.Errorf("threshold = %d, want %d", got, defaultCircuitBreakerThreshold)
+		}
+	})
+
+	t.Run("Explicit zero circuit_breaker_threshold disables the breaker", func(t *testing.T) {
+		cfg := getStored(t, `{"credentials_json": "{}", "version": 3, "circuit_breaker_threshold": 0}`)
+		if got := cfg.circuitBreakerSettings().threshold; got != 0 {
+			t.Errorf("threshold = %d, want 0", got)
+		}
+	})
+}
+
+func TestConfig_CallLimitFieldTypes(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	b, err := Factory(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"credentials_json":     `{"test": "creds"}`,
+			"validate_credentials": false,
+			"request_timeout":      "10s",
+			"retry_backoff_ms":     100,
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "config",
+		Storage:   storage,
+	})
+	if err != nil || resp == nil {
+		t.Fatalf("failed to read config: err=%v", err)
+	}
+
+	if resp.Data["request_timeout"] != int64(10) {
+		t.Errorf("request_timeout = %#v, want int64 seconds", resp.Data["request_timeout"])
+	}
+	if resp.Data["retry_backoff_ms"] != int64(100) {
+		t.Errorf("retry_backoff_ms = %#v, want int64 milliseconds", resp.Data["retry_backoff_ms"])
+	}
+}
@@ -0,0 +1,183 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// credentialSetStoragePrefix is the storage prefix for named credential sets
+const credentialSetStoragePrefix = "credentials/"
+
+// skyflowCredentialSet is a named Skyflow service account credential set.
+// Roles reference a credential set by name to mint tokens with a service account
+// other than the one in the mount config.
+type skyflowCredentialSet struct {
+	Name string `json:"name"`
+
+	// Credentials - one of these must be provided
+	CredentialsFilePath string `json:"credentials_file_path,omitempty"`
+	CredentialsJSON     string `json:"credentials_json,omitempty"`
+
+	// Metadata
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// defaultCredentialSet returns a credential set with default values
+func defaultCredentialSet(name string) *skyflowCredentialSet {
+	now := time.Now()
+	return &skyflowCredentialSet{
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// toConfig returns a copy of base that mints tokens with this credential set.
+// Mount-level settings (cache window, role ID policy, version) are kept from base.
+func (cs *skyflowCredentialSet) toConfig(base *skyflowConfig) *skyflowConfig {
+	config := *base
+	config.CredentialsFilePath = cs.CredentialsFilePath
+	config.CredentialsJSON = cs.CredentialsJSON
+	return &config
+}
+
+// validate checks if the credential set is valid
+func (cs *skyflowCredentialSet) validate() error {
+	if cs.Name == "" {
+		return fmt.Errorf("credential set name is required")
+	}
+
+	return cs.toConfig(defaultConfig()).validate()
+}
+
+// credentialsType returns the credential source type for responses and telemetry
+func (cs *skyflowCredentialSet) credentialsType() string {
+	if cs.CredentialsFilePath != "" {
+		return "file_path"
+	}
+	return "json"
+}
+
+// getCredentialSet retrieves a named credential set from storage
+func (b *skyflowBackend) getCredentialSet(ctx context.Context, s logical.Storage, name string) (*skyflowCredentialSet, error) {
+	if name == "" {
+		return nil, fmt.Errorf("credential set name is required")
+	}
+
+	entry, err := s.Get(ctx, credentialSetStoragePrefix+name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credential set: %w", err)
+	}
+
+	if entry == nil {
+		return nil, nil
+	}
+
+	set := &skyflowCredentialSet{}
+	if err := entry.DecodeJSON(set); err != nil {
+		return nil, fmt.Errorf("failed to decode credential set: %w", err)
+	}
+
+	return set, nil
+}
+
+// saveCredentialSet stores a named credential set in Vault storage
+func (b *skyflowBackend) saveCredentialSet(ctx context.Context, s logical.Storage, set *skyflowCredentialSet) error {
+	if set.Name == "" {
+		return fmt.Errorf("credential set name is required")
+	}
+
+	set.UpdatedAt = time.Now()
+
+	entry, err := logical.StorageEntryJSON(credentialSetStoragePrefix+set.Name, set)
+	if err != nil {
+		return fmt.Errorf("failed to create storage entry: %w", err)
+	}
+
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("failed to save credential set: %w", err)
+	}
+
+	return nil
+}
+
+// deleteCredentialSet removes a named credential set from storage
+func (b *skyflowBackend) deleteCredentialSet(ctx context.Context, s logical.Storage, name string) error {
+	if name == "" {
+		return fmt.Errorf("credential set name is required")
+	}
+
+	if err := s.Delete(ctx, credentialSetStoragePrefix+name); err != nil {
+		return fmt.Errorf("failed to delete credential set: %w", err)
+	}
+
+	return nil
+}
+
+// listCredentialSets returns all credential set names
+func (b *skyflowBackend) listCredentialSets(ctx context.Context, s logical.Storage) ([]string, error) {
+	sets, err := s.List(ctx, credentialSetStoragePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credential sets: %w", err)
+	}
+
+	return sets, nil
+}
+
+// rolesUsingCredentialSet returns the names of roles that reference a credential set
+func (b *skyflowBackend) rolesUsingCredentialSet(ctx context.Context, s logical.Storage, name string) ([]string, error) {
+	roleNames, err := b.listRoles(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []string
+	for _, roleName := range roleNames {
+		role, err := b.getRole(ctx, s, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if role != nil && role.CredentialSet == name {
+			users = append(users, roleName)
+		}
+	}
+
+	return users, nil
+}
+
+// tokenConfig resolves the config used to mint tokens for a role: the mount config,
+// or the role's named credential set layered over the mount config.
+// Returns nil when the mount config (or the role's credential set) does not exist.
+func (b *skyflowBackend) tokenConfig(ctx context.Context, s logical.Storage, role *skyflowRole) (*skyflowConfig, error) {
+	config, err := b.getConfig(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.CredentialSet == "" {
+		return config, nil
+	}
+
+	set, err := b.getCredentialSet(ctx, s, role.CredentialSet)
+	if err != nil {
+		return nil, err
+	}
+
+	if set == nil {
+		return nil, nil
+	}
+
+	// A mount that only uses credential sets has no config; use default mount settings
+	if config == nil {
+		config = defaultConfig()
+		config.Version = 0
+	}
+
+	return set.toConfig(config), nil
+}
@@ -0,0 +1,266 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestCredentialSet_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		set       *skyflowCredentialSet
+		wantError bool
+	}{
+		{
+			name: "Valid set with file path",
+			set: &skyflowCredentialSet{
+				Name:                "payment-sa",
+				CredentialsFilePath: "/path/to/creds.json",
+			},
+			wantError: false,
+		},
+		{
+			name: "Valid set with JSON",
+			set: &skyflowCredentialSet{
+				Name:            "payment-sa",
+				CredentialsJSON: `{"key": "value"}`,
+			},
+			wantError: false,
+		},
+		{
+			name: "Missing name",
+			set: &skyflowCredentialSet{
+				CredentialsJSON: `{"key": "value"}`,
+			},
+			wantError: true,
+		},
+		{
+			name:      "No credentials",
+			set:       &skyflowCredentialSet{Name: "payment-sa"},
+			wantError: true,
+		},
+		{
+			name: "Both credentials provided",
+			set: &skyflowCredentialSet{
+				Name:                "payment-sa",
+				CredentialsFilePath: "/path/to/creds.json",
+				CredentialsJSON:     `{"key": "value"}`,
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.set.validate()
+			if tt.wantError && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCredentialSet_Paths(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	config := &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation:  op,
+			Path:       path,
+			Storage:    storage,
+			Data:       data,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+	}
+
+	t.Run("Create credential set", func(t *testing.T) {
+		resp, err := request(logical.CreateOperation, "credentials/payment-sa", map[string]interface{}{
+			"credentials_json":     `{"test": "creds"}`,
+			"description":          "Payment service account",
+			"validate_credentials": false,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write credential set: err=%v resp=%v", err, resp)
+		}
+	})
+
+	t.Run("Read credential set hides secret", func(t *testing.T) {
+		resp, err := request(logical.ReadOperation, "credentials/payment-sa", nil)
+		if err != nil || resp == nil {
+			t.Fatalf("failed to read credential set: err=%v", err)
+		}
+		if _, ok := resp.Data["credentials_json"]; ok {
+			t.Error("credentials_json must not be returned")
+		}
+		if resp.Data["credentials_type"] != "json" {
+			t.Errorf("expected credentials_type 'json', got %v", resp.Data["credentials_type"])
+		}
+		if resp.Data["description"] != "Payment service account" {
+			t.Errorf("unexpected description: %v", resp.Data["description"])
+		}
+	})
+
+	t.Run("List credential sets", func(t *testing.T) {
+		resp, err := request(logical.ListOperation, "credentials/", nil)
+		if err != nil || resp == nil {
+			t.Fatalf("failed to list credential sets: err=%v", err)
+		}
+		keys, _ := resp.Data["keys"].([]string)
+		if len(keys) != 1 || keys[0] != "payment-sa" {
+			t.Errorf("expected [payment-sa], got %v", keys)
+		}
+	})
+
+	t.Run("Role referencing unknown credential set is rejected", func(t *testing.T) {
+		resp, err := request(logical.CreateOperation, "roles/payment-risk-engine", map[string]interface{}{
+			"role_ids":       "skyflow-role-risk-001",
+			"credential_set": "missing-sa",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected error response for unknown credential set")
+		}
+	})
+
+	t.Run("Role uses credential set without mount config", func(t *testing.T) {
+		resp, err := request(logical.CreateOperation, "roles/payment-risk-engine", map[string]interface{}{
+			"role_ids":       "skyflow-role-risk-001",
+			"credential_set": "payment-sa",
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write role: err=%v resp=%v", err, resp)
+		}
+
+		resp, err = request(logical.ReadOperation, "creds/payment-risk-engine", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected error response for fake credentials")
+		}
+		// Credentials are fake, so the request must get as far as the SDK
+		if !strings.Contains(resp.Error().Error(), "failed to generate token") {
+			t.Errorf("expected token generation failure, got: %v", resp.Error())
+		}
+	})
+
+	t.Run("Delete in-use credential set is refused", func(t *testing.T) {
+		resp, err := request(logical.DeleteOperation, "credentials/payment-sa", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected error response for in-use credential set")
+		}
+		if !strings.Contains(resp.Error().Error(), "payment-risk-engine") {
+			t.Errorf("expected error to name the role, got: %v", resp.Error())
+		}
+	})
+
+	t.Run("Delete unused credential set", func(t *testing.T) {
+		if _, err := request(logical.DeleteOperation, "roles/payment-risk-engine", nil); err != nil {
+			t.Fatalf("failed to delete role: %v", err)
+		}
+
+		resp, err := request(logical.DeleteOperation, "credentials/payment-sa", nil)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to delete credential set: err=%v resp=%v", err, resp)
+		}
+
+		resp, err = request(logical.ReadOperation, "credentials/payment-sa", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp != nil {
+			t.Error("expected credential set to be deleted")
+		}
+	})
+}
+
+func TestCredentialSet_TokenConfig(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	config := &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	backend := b.(*skyflowBackend)
+
+	mountConfig := &skyflowConfig{
+		CredentialsFilePath: "/etc/vault/creds/mount.json",
+		MaxRoleIDs:          3,
+		Version:             7,
+	}
+	if err := backend.saveConfig(ctx, storage, mountConfig); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	set := &skyflowCredentialSet{
+		Name:            "payment-sa",
+		CredentialsJSON: `{"test": "creds"}`,
+	}
+	if err := backend.saveCredentialSet(ctx, storage, set); err != nil {
+		t.Fatalf("failed to save credential set: %v", err)
+	}
+
+	t.Run("Role without credential set uses mount config", func(t *testing.T) {
+		cfg, err := backend.tokenConfig(ctx, storage, &skyflowRole{Name: "r"})
+		if err != nil || cfg == nil {
+			t.Fatalf("failed to resolve config: %v", err)
+		}
+		if cfg.CredentialsFilePath != mountConfig.CredentialsFilePath {
+			t.Errorf("expected mount credentials, got %q", cfg.CredentialsFilePath)
+		}
+	})
+
+	t.Run("Role with credential set uses its credentials", func(t *testing.T) {
+		cfg, err := backend.tokenConfig(ctx, storage, &skyflowRole{Name: "r", CredentialSet: "payment-sa"})
+		if err != nil || cfg == nil {
+			t.Fatalf("failed to resolve config: %v", err)
+		}
+		if cfg.CredentialsJSON != set.CredentialsJSON || cfg.CredentialsFilePath != "" {
+			t.Error("expected credential set credentials")
+		}
+		if cfg.MaxRoleIDs != 3 || cfg.Version != 7 {
+			t.Error("expected mount settings to be kept")
+		}
+	})
+
+	t.Run("Role with unknown credential set", func(t *testing.T) {
+		cfg, err := backend.tokenConfig(ctx, storage, &skyflowRole{Name: "r", CredentialSet: "missing"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg != nil {
+			t.Error("expected nil config for unknown credential set")
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package backend
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/helper/identitytpl"
+)
+
+// validateCtxTemplate checks that a ctx_template is well formed and references identity data
+func validateCtxTemplate(tmpl string) error {
+	if tmpl == "" {
+		return nil
+	}
+
+	subst, _, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+		String:            tmpl,
+		ValidityCheckOnly: true,
+		Mode:              identitytpl.ACLTemplating,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid ctx_template: %w", err)
+	}
+
+	// A template without directives is a fixed ctx; it would not bind anything to the caller
+	if !subst {
+		return fmt.Errorf("invalid ctx_template: no identity template directives found")
+	}
+
+	return nil
+}
+
+// renderCtxTemplate renders a role's ctx_template from the identity entity that made the request
+func (b *skyflowBackend) renderCtxTemplate(role *skyflowRole, entityID string) (string, error) {
+	if entityID == "" {
+		return "", fmt.Errorf("role %q derives ctx from the caller's identity but the request has no entity", role.Name)
+	}
+
+	entity, err := b.System().EntityInfo(entityID)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up entity: %w", err)
+	}
+	if entity == nil {
+		return "", fmt.Errorf("entity %q not found", entityID)
+	}
+
+	groups, err := b.System().GroupsForEntity(entityID)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up entity groups: %w", err)
+	}
+
+	_, ctxData, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+		String:      role.CtxTemplate,
+		Entity:      entity,
+		Groups:      groups,
+		NamespaceID: entity.NamespaceID,
+		Mode:        identitytpl.ACLTemplating,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render ctx_template for role %q: %w", role.Name, err)
+	}
+
+	// An empty ctx would mint a token without context restrictions
+	if ctxData == "" {
+		return "", fmt.Errorf("ctx_template for role %q rendered an empty ctx for entity %q", role.Name, entityID)
+	}
+
+	return ctxData, nil
+}
+
+// resolveCtx returns the ctx to mint a token with: the rendered ctx_template when the role has
+// one, otherwise the caller-supplied ctx
+func (b *skyflowBackend) resolveCtx(role *skyflowRole, callerCtx string, entityID string) (string, error) {
+	if role.CtxTemplate == "" {
+		return callerCtx, nil
+	}
+
+	if callerCtx != "" {
+		return "", fmt.Errorf("role %q sets ctx from the caller's identity; ctx cannot be supplied", role.Name)
+	}
+
+	return b.renderCtxTemplate(role, entityID)
+}
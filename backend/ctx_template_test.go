@@ -0,0 +1,174 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestCtxTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		tmpl      string
+		wantError bool
+	}{
+		{name: "Empty template", tmpl: ""},
+		{name: "Entity metadata", tmpl: "{{identity.entity.metadata.tenant_id}}"},
+		{name: "Mixed literal and directive", tmpl: "tenant:{{identity.entity.metadata.tenant_id}}"},
+		{name: "Unbalanced braces", tmpl: "{{identity.entity.metadata.tenant_id", wantError: true},
+		{name: "No directives", tmpl: "tenant:acme", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCtxTemplate(tt.tmpl)
+			if tt.wantError && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCtxTemplate_TokenPath(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	config := &logical.BackendConfig{
+		Logger: nil,
+		System: &logical.StaticSystemView{
+			EntityVal: &logical.Entity{
+				ID:       "entity-1",
+				Name:     "risk-engine",
+				Metadata: map[string]string{"tenant_id": "acme", "team": ""},
+			},
+		},
+		StorageView: storage,
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	backend := b.(*skyflowBackend)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"credentials_json":     `{"test": "creds"}`,
+			"validate_credentials": false,
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+	}
+
+	writeRole := func(t *testing.T, tmpl string) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "roles/payment-risk-engine",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"role_ids":     "skyflow-role-read",
+				"ctx_template": tmpl,
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return resp
+	}
+
+	readCreds := func(t *testing.T, entityID, ctxData string) *logical.Response {
+		t.Helper()
+		data := map[string]interface{}{}
+		if ctxData != "" {
+			data["ctx"] = ctxData
+		}
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.ReadOperation,
+			Path:       "creds/payment-risk-engine",
+			Storage:    storage,
+			Data:       data,
+			EntityID:   entityID,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil {
+			t.Fatal("expected a response")
+		}
+		return resp
+	}
+
+	t.Run("Invalid template is rejected", func(t *testing.T) {
+		resp := writeRole(t, "{{identity.entity.metadata.tenant_id")
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected error response for unbalanced template")
+		}
+	})
+
+	if resp := writeRole(t, "tenant:{{identity.entity.metadata.tenant_id}}"); resp != nil && resp.IsError() {
+		t.Fatalf("failed to write role: %v", resp.Error())
+	}
+
+	t.Run("ctx is rendered from the entity", func(t *testing.T) {
+		token := testIssuedToken(time.Now().Add(time.Hour))
+		token.Role = "payment-risk-engine"
+		backend.tokenCache.put(backend.tokenCache.currentEpoch(), "payment-risk-engine", "tenant:acme", token)
+
+		resp := readCreds(t, "entity-1", "")
+		if resp.IsError() {
+			t.Fatalf("unexpected error response: %v", resp.Error())
+		}
+		if resp.Data["access_token"] != token.AccessToken {
+			t.Error("expected the token cached under the rendered ctx")
+		}
+	})
+
+	t.Run("Caller cannot override the template", func(t *testing.T) {
+		resp := readCreds(t, "entity-1", "tenant:other")
+		if !resp.IsError() || !strings.Contains(resp.Error().Error(), "ctx cannot be supplied") {
+			t.Errorf("expected override rejection, got: %v", resp.Error())
+		}
+	})
+
+	t.Run("Request without an entity is rejected", func(t *testing.T) {
+		resp := readCreds(t, "", "")
+		if !resp.IsError() || !strings.Contains(resp.Error().Error(), "has no entity") {
+			t.Errorf("expected missing entity error, got: %v", resp.Error())
+		}
+	})
+
+	t.Run("Missing metadata key is rejected", func(t *testing.T) {
+		if resp := writeRole(t, "{{identity.entity.metadata.region}}"); resp != nil && resp.IsError() {
+			t.Fatalf("failed to write role: %v", resp.Error())
+		}
+
+		resp := readCreds(t, "entity-1", "")
+		if !resp.IsError() || !strings.Contains(resp.Error().Error(), "failed to render ctx_template") {
+			t.Errorf("expected render error, got: %v", resp.Error())
+		}
+	})
+
+	t.Run("Empty metadata value is rejected", func(t *testing.T) {
+		if resp := writeRole(t, "{{identity.entity.metadata.team}}"); resp != nil && resp.IsError() {
+			t.Fatalf("failed to write role: %v", resp.Error())
+		}
+
+		resp := readCreds(t, "entity-1", "")
+		if !resp.IsError() || !strings.Contains(resp.Error().Error(), "rendered an empty ctx") {
+			t.Errorf("expected empty ctx error, got: %v", resp.Error())
+		}
+	})
+}
@@ -0,0 +1,263 @@
+package backend
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTokenPath is the Skyflow service account token exchange endpoint
+const fakeTokenPath = "/v1/auth/sa/oauth/token"
+
+// fakeTokenGrantType is the OAuth grant type Skyflow expects for service account assertions
+const fakeTokenGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+// fakeTokenExchange is one accepted token exchange, as seen by the fake token server
+type fakeTokenExchange struct {
+	Scope string
+	Ctx   string
+}
+
+// fakeTokenServer is a local stand-in for the Skyflow OAuth token endpoint. It only issues
+// tokens for JWT assertions signed by its service account key with the expected claims.
+type fakeTokenServer struct {
+	*httptest.Server
+
+	clientID string
+	keyID    string
+	key      *rsa.PrivateKey
+	tokenTTL time.Duration
+
+	mu        sync.Mutex
+	exchanges []fakeTokenExchange
+	failures  int
+}
+
+// newFakeTokenServer starts a fake token server with a freshly generated service account key
+func newFakeTokenServer(t *testing.T) *fakeTokenServer {
+	t.Helper()
+
+	f := &fakeTokenServer{
+		clientID: "sa-payment",
+		keyID:    "key-1",
+		key:      generateTestKey(t),
+		tokenTTL: time.Hour,
+	}
+	f.Server = httptest.NewTLSServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+// generateTestKey returns an RSA key for signing test service account assertions
+func generateTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+// testServiceAccountJSON returns Skyflow service account credentials for a key
+func testServiceAccountJSON(t *testing.T, clientID, keyID, tokenURI string, key *rsa.PrivateKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	creds, err := json.Marshal(map[string]string{
+		"clientID":   clientID,
+		"keyID":      keyID,
+		"tokenURI":   tokenURI,
+		"privateKey": string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal credentials: %v", err)
+	}
+	return string(creds)
+}
+
+// tokenURI returns the token endpoint URL placed in the service account credentials
+func (f *fakeTokenServer) tokenURI() string {
+	return f.URL + fakeTokenPath
+}
+
+// credentialsJSON returns service account credentials the server accepts
+func (f *fakeTokenServer) credentialsJSON(t *testing.T) string {
+	return testServiceAccountJSON(t, f.clientID, f.keyID, f.tokenURI(), f.key)
+}
+
+// useAsDefaultTransport routes the Skyflow SDK, which always uses http.DefaultClient, to this
+// server for the rest of the test
+func (f *fakeTokenServer) useAsDefaultTransport(t *testing.T) {
+	t.Helper()
+
+	original := http.DefaultTransport
+	http.DefaultTransport = f.Client().Transport
+	t.Cleanup(func() { http.DefaultTransport = original })
+}
+
+// failNext makes the next n exchanges fail with a 503
+func (f *fakeTokenServer) failNext(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = n
+}
+
+// accepted returns the exchanges the server has accepted so far
+func (f *fakeTokenServer) accepted() []fakeTokenExchange {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]fakeTokenExchange(nil), f.exchanges...)
+}
+
+func (f *fakeTokenServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != fakeTokenPath {
+		f.writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	f.mu.Lock()
+	if f.failures > 0 {
+		f.failures--
+		f.mu.Unlock()
+		f.writeError(w, http.StatusServiceUnavailable, "service unavailable")
+		return
+	}
+	f.mu.Unlock()
+
+	var body struct {
+		GrantType string `json:"grant_type"`
+		Assertion string `json:"assertion"`
+		Scope     string `json:"scope"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		f.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if body.GrantType != fakeTokenGrantType {
+		f.writeError(w, http.StatusBadRequest, "unsupported grant_type")
+		return
+	}
+
+	claims, err := f.verifyAssertion(body.Assertion)
+	if err != nil {
+		f.writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	exchange := fakeTokenExchange{Scope: strings.TrimSpace(body.Scope)}
+	exchange.Ctx, _ = claims["ctx"].(string)
+
+	f.mu.Lock()
+	f.exchanges = append(f.exchanges, exchange)
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"accessToken": f.accessToken(exchange),
+		"tokenType":   "Bearer",
+	})
+}
+
+// verifyAssertion checks the RS256 signature and claims of a service account JWT assertion
+func (f *fakeTokenServer) verifyAssertion(assertion string) (map[string]interface{}, error) {
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("assertion is not a JWT")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("unexpected alg %q", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding")
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&f.key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("invalid assertion signature")
+	}
+
+	claims := map[string]interface{}{}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	for name, want := range map[string]string{"iss": f.clientID, "sub": f.clientID, "key": f.keyID, "aud": f.tokenURI()} {
+		if claims[name] != want {
+			return nil, fmt.Errorf("unexpected %s claim %v", name, claims[name])
+		}
+	}
+
+	exp, _ := claims["exp"].(float64)
+	if time.Unix(int64(exp), 0).Before(time.Now()) {
+		return nil, fmt.Errorf("assertion has expired")
+	}
+
+	return claims, nil
+}
+
+// accessToken returns an unsigned JWT carrying the claims the plugin reads from access tokens
+func (f *fakeTokenServer) accessToken(exchange fakeTokenExchange) string {
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "none", "typ": "JWT"})
+	payload, _ := json.Marshal(map[string]interface{}{
+		"iat":   now.Unix(),
+		"exp":   now.Add(f.tokenTTL).Unix(),
+		"sub":   f.clientID,
+		"scope": exchange.Scope,
+		"ctx":   exchange.Ctx,
+		"jti":   fmt.Sprintf("%d", now.UnixNano()),
+	})
+	return base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func (f *fakeTokenServer) writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"http_code":   status,
+			"http_status": http.StatusText(status),
+			"message":     message,
+		},
+	})
+}
+
+// decodeJWTSegment decodes one base64url JSON segment of a JWT
+func decodeJWTSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("invalid JWT segment encoding")
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid JWT segment: %w", err)
+	}
+	return nil
+}
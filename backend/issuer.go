@@ -0,0 +1,208 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/skyflowapi/skyflow-go/v2/serviceaccount"
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
+	skyflowError "github.com/skyflowapi/skyflow-go/v2/utils/error"
+	"github.com/skyflowapi/skyflow-go/v2/utils/logger"
+)
+
+// TokenRequest describes a Skyflow bearer token to mint
+type TokenRequest struct {
+	// Service account credentials; exactly one of these is set
+	CredentialsJSON     string
+	CredentialsFilePath string
+
+	// Skyflow role IDs the token is scoped to
+	RoleIDs []string
+
+	// Context for Skyflow context-aware authorization (optional)
+	Ctx string
+}
+
+// TokenIssuer mints Skyflow bearer tokens from service account credentials
+type TokenIssuer interface {
+	IssueToken(ctx context.Context, req *TokenRequest) (*common.TokenResponse, error)
+}
+
+// SignRequest describes Skyflow data tokens to sign
+type SignRequest struct {
+	// Service account credentials; exactly one of these is set
+	CredentialsJSON     string
+	CredentialsFilePath string
+
+	// Data tokens to sign, in the order they are returned
+	DataTokens []string
+
+	// Lifetime of the signed tokens
+	TTL time.Duration
+
+	// Context for Skyflow context-aware authorization (optional)
+	Ctx string
+}
+
+// DataTokenSigner signs Skyflow data tokens. A TokenIssuer that also implements it serves
+// sign/<role> on the mounts that select it.
+type DataTokenSigner interface {
+	SignDataTokens(ctx context.Context, req *SignRequest) ([]common.SignedDataTokensResponse, error)
+}
+
+// errSigningUnsupported is returned by sign/<role> when the mount's issuer cannot sign data tokens
+var errSigningUnsupported = errors.New("the mount's token issuer does not support signing data tokens")
+
+// Option configures the backend built by FactoryWithOptions
+type Option func(*skyflowBackend)
+
+// WithTokenIssuer replaces the Skyflow SDK as the issuer of bearer tokens
+func WithTokenIssuer(issuer TokenIssuer) Option {
+	return func(b *skyflowBackend) {
+		b.tokenIssuer = issuer
+	}
+}
+
+// sdkTokenIssuer is the default TokenIssuer, backed by the Skyflow Go SDK
+type sdkTokenIssuer struct{}
+
+// IssueToken implements TokenIssuer. The SDK does not take a context, so when ctx is done the
+// SDK call is abandoned to finish in the background and ctx's error is returned.
+func (s sdkTokenIssuer) IssueToken(ctx context.Context, req *TokenRequest) (*common.TokenResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("token request abandoned: %w", err)
+	}
+
+	type result struct {
+		token *common.TokenResponse
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		token, err := s.issue(req)
+		done <- result{token: token, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.token, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("token request abandoned: %w", ctx.Err())
+	}
+}
+
+// issue mints a token with the SDK
+func (sdkTokenIssuer) issue(req *TokenRequest) (token *common.TokenResponse, returnErr error) {
+	// Recover from SDK panics - defensive measure
+	defer func() {
+		if r := recover(); r != nil {
+			returnErr = fmt.Errorf("%w: %v", errTokenPanic, r)
+		}
+	}()
+
+	var sdkErr *skyflowError.SkyflowError
+
+	opts := common.BearerTokenOptions{
+		LogLevel: logger.DEBUG,
+		RoleIDs:  req.RoleIDs,
+		Ctx:      req.Ctx,
+	}
+
+	if req.CredentialsFilePath != "" {
+		if _, statErr := os.Stat(req.CredentialsFilePath); os.IsNotExist(statErr) {
+			return nil, fmt.Errorf("credentials file not found: %s: %w", req.CredentialsFilePath, statErr)
+		}
+		token, sdkErr = serviceaccount.GenerateBearerToken(req.CredentialsFilePath, opts)
+	} else if req.CredentialsJSON != "" {
+		token, sdkErr = serviceaccount.GenerateBearerTokenFromCreds(req.CredentialsJSON, opts)
+	} else {
+		return nil, errNoCredentials
+	}
+
+	if sdkErr != nil {
+		return nil, fmt.Errorf("failed to generate bearer token: %w", sdkErr)
+	}
+
+	return token, nil
+}
+
+// SignDataTokens implements DataTokenSigner. Signing happens locally, so only a done ctx is checked.
+func (sdkTokenIssuer) SignDataTokens(ctx context.Context, req *SignRequest) (signed []common.SignedDataTokensResponse, returnErr error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("signing request abandoned: %w", err)
+	}
+
+	// Recover from SDK panics - defensive measure
+	defer func() {
+		if r := recover(); r != nil {
+			returnErr = fmt.Errorf("%w: %v", errTokenPanic, r)
+		}
+	}()
+
+	var sdkErr *skyflowError.SkyflowError
+
+	opts := common.SignedDataTokensOptions{
+		DataTokens: req.DataTokens,
+		TimeToLive: int(req.TTL.Seconds()),
+		Ctx:        req.Ctx,
+		LogLevel:   logger.DEBUG,
+	}
+
+	if req.CredentialsFilePath != "" {
+		if _, statErr := os.Stat(req.CredentialsFilePath); os.IsNotExist(statErr) {
+			return nil, fmt.Errorf("credentials file not found: %s: %w", req.CredentialsFilePath, statErr)
+		}
+		signed, sdkErr = serviceaccount.GenerateSignedDataTokens(req.CredentialsFilePath, opts)
+	} else if req.CredentialsJSON != "" {
+		signed, sdkErr = serviceaccount.GenerateSignedDataTokensFromCreds(req.CredentialsJSON, opts)
+	} else {
+		return nil, errNoCredentials
+	}
+
+	if sdkErr != nil {
+		return nil, fmt.Errorf("failed to sign data tokens: %w", sdkErr)
+	}
+
+	return signed, nil
+}
+
+// issuerFor returns the TokenIssuer selected by the config's token_issuer. The native issuer's
+// HTTP client is reused until the config's HTTP settings change.
+func (b *skyflowBackend) issuerFor(config *skyflowConfig) (TokenIssuer, error) {
+	if config.tokenIssuer() != tokenIssuerNative {
+		return b.tokenIssuer, nil
+	}
+
+	key := strings.Join([]string{config.httpTimeout().String(), config.HTTPProxy, config.CABundle}, "\x00")
+
+	b.nativeIssuerLock.Lock()
+	defer b.nativeIssuerLock.Unlock()
+
+	if b.nativeIssuer != nil && b.nativeIssuerKey == key {
+		return b.nativeIssuer, nil
+	}
+
+	issuer, err := newNativeTokenIssuer(config)
+	if err != nil {
+		return nil, err
+	}
+
+	b.nativeIssuer = issuer
+	b.nativeIssuerKey = key
+	return issuer, nil
+}
+
+// validateCredentials checks that the config's credentials can mint a token with its issuer
+func (b *skyflowBackend) validateCredentials(ctx context.Context, config *skyflowConfig) error {
+	issuer, err := b.issuerFor(config)
+	if err != nil {
+		return fmt.Errorf("credential validation failed: %w", err)
+	}
+
+	return config.validateCredentials(ctx, issuer)
+}
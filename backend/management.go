@@ -0,0 +1,204 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/skyflowapi/skyflow-go/v2/serviceaccount"
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
+	"github.com/skyflowapi/skyflow-go/v2/utils/logger"
+)
+
+// defaultManagementURL is the Skyflow management API used for service account key operations
+const defaultManagementURL = "https://manage.skyflowapis.com"
+
+// managementRequestTimeout bounds each management API call
+const managementRequestTimeout = 30 * time.Second
+
+// serviceAccountCredentials holds the fields of a Skyflow credentials JSON
+type serviceAccountCredentials struct {
+	ClientID   string `json:"clientID"`
+	KeyID      string `json:"keyID"`
+	TokenURI   string `json:"tokenURI"`
+	PrivateKey string `json:"privateKey"`
+}
+
+// parseServiceAccountCredentials reads the client and key IDs from a credentials JSON
+func parseServiceAccountCredentials(credentialsJSON string) (*serviceAccountCredentials, error) {
+	creds := &serviceAccountCredentials{}
+	if err := json.Unmarshal([]byte(credentialsJSON), creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+
+	if creds.ClientID == "" || creds.KeyID == "" {
+		return nil, fmt.Errorf("credentials must contain clientID and keyID")
+	}
+
+	return creds, nil
+}
+
+// sdkBearerToken mints a Skyflow bearer token for a credentials JSON using the SDK
+func sdkBearerToken(credentialsJSON string) (accessToken string, returnErr error) {
+	// Recover from SDK panics - defensive measure for unexpected SDK behavior
+	defer func() {
+		if r := recover(); r != nil {
+			returnErr = fmt.Errorf("%w: %v", errTokenPanic, r)
+		}
+	}()
+
+	token, sdkErr := serviceaccount.GenerateBearerTokenFromCreds(credentialsJSON, common.BearerTokenOptions{LogLevel: logger.DEBUG})
+	if sdkErr != nil {
+		return "", fmt.Errorf("failed to generate token: %w", sdkErr)
+	}
+
+	if token == nil || token.AccessToken == "" {
+		return "", fmt.Errorf("failed to generate token: no token returned")
+	}
+
+	return token.AccessToken, nil
+}
+
+// managementAPIError is returned when the management API responds with a non-2xx status
+type managementAPIError struct {
+	StatusCode int
+}
+
+// Error implements error
+func (e *managementAPIError) Error() string {
+	return fmt.Sprintf("management API returned %d", e.StatusCode)
+}
+
+// managementClient calls the Skyflow management API to create and revoke service account keys
+type managementClient struct {
+	baseURL     string
+	httpClient  *http.Client
+	bearerToken func(credentialsJSON string) (string, error)
+}
+
+// newManagementClient returns a management API client for the config's management URL
+func (b *skyflowBackend) newManagementClient(config *skyflowConfig) *managementClient {
+	return &managementClient{
+		baseURL:     strings.TrimSuffix(config.managementURL(), "/"),
+		httpClient:  &http.Client{Timeout: managementRequestTimeout},
+		bearerToken: b.managementToken,
+	}
+}
+
+// keysURL returns the key collection URL for a service account
+func (c *managementClient) keysURL(clientID string) string {
+	return c.baseURL + "/v1/serviceAccounts/" + url.PathEscape(clientID) + "/keys"
+}
+
+// createKey creates a new key for the service account that owns credentialsJSON.
+// The returned credentials JSON has the same shape as a Skyflow credentials file.
+func (c *managementClient) createKey(ctx context.Context, credentialsJSON string) (string, error) {
+	creds, err := parseServiceAccountCredentials(credentialsJSON)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := c.do(ctx, http.MethodPost, c.keysURL(creds.ClientID), credentialsJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to create service account key: %w", err)
+	}
+
+	newCreds, err := parseServiceAccountCredentials(string(body))
+	if err != nil {
+		return "", fmt.Errorf("unexpected create key response: %w", err)
+	}
+
+	if newCreds.ClientID != creds.ClientID {
+		return "", fmt.Errorf("unexpected create key response: key belongs to %q", newCreds.ClientID)
+	}
+
+	return string(body), nil
+}
+
+// serviceAccountKey is a key listed on a service account
+type serviceAccountKey struct {
+	KeyID     string    `json:"keyID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// listKeys returns the keys of the service account that owns credentialsJSON
+func (c *managementClient) listKeys(ctx context.Context, credentialsJSON string) ([]serviceAccountKey, error) {
+	creds, err := parseServiceAccountCredentials(credentialsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.do(ctx, http.MethodGet, c.keysURL(creds.ClientID), credentialsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service account keys: %w", err)
+	}
+
+	var list struct {
+		Keys []serviceAccountKey `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("unexpected list keys response: %w", err)
+	}
+
+	return list.Keys, nil
+}
+
+// deleteKey revokes keyID on the service account, authenticating with credentialsJSON
+func (c *managementClient) deleteKey(ctx context.Context, credentialsJSON, keyID string) error {
+	creds, err := parseServiceAccountCredentials(credentialsJSON)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(ctx, http.MethodDelete, c.keysURL(creds.ClientID)+"/"+url.PathEscape(keyID), credentialsJSON)
+
+	// A key that is already gone was revoked by an earlier attempt
+	var apiErr *managementAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to revoke service account key %s: %w", keyID, err)
+	}
+
+	return nil
+}
+
+// do sends an authenticated management API request and returns the response body
+func (c *managementClient) do(ctx context.Context, method, endpoint, credentialsJSON string) ([]byte, error) {
+	token, err := c.bearerToken(credentialsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &managementAPIError{StatusCode: resp.StatusCode}
+	}
+
+	return body, nil
+}
@@ -0,0 +1,325 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
+)
+
+const (
+	// tokenIssuerSDK mints tokens through the Skyflow Go SDK
+	tokenIssuerSDK = "sdk"
+
+	// tokenIssuerNative mints tokens with the in-plugin JWT-bearer exchange
+	tokenIssuerNative = "native"
+
+	// defaultHTTPTimeout bounds each token exchange made by the native issuer
+	defaultHTTPTimeout = 30 * time.Second
+
+	// nativeAssertionLifetime matches the lifetime the Skyflow SDK gives its assertions
+	nativeAssertionLifetime = time.Hour
+
+	// jwtBearerGrantType is the OAuth grant type for service account assertions
+	jwtBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+)
+
+// tokenExchangeError is returned when the Skyflow token endpoint responds with a non-2xx status
+type tokenExchangeError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements error
+func (e *tokenExchangeError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("token endpoint returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("token endpoint returned %d: %s", e.StatusCode, e.Message)
+}
+
+// nativeTokenIssuer is a TokenIssuer that signs the service account assertion and exchanges
+// it at the credentials' tokenURI itself, using an HTTP client the mount controls
+type nativeTokenIssuer struct {
+	client *http.Client
+}
+
+// newNativeTokenIssuer returns a native issuer using the config's timeout, proxy and CA bundle
+func newNativeTokenIssuer(config *skyflowConfig) (*nativeTokenIssuer, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.HTTPProxy != "" {
+		proxyURL, err := url.Parse(config.HTTPProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid http_proxy: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if config.CABundle != "" {
+		pool, err := parseCABundle(config.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	return &nativeTokenIssuer{
+		client: &http.Client{
+			Timeout:   config.httpTimeout(),
+			Transport: transport,
+		},
+	}, nil
+}
+
+// parseCABundle returns the system roots plus the certificates in a PEM bundle
+func parseCABundle(bundle string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM([]byte(bundle)) {
+		return nil, fmt.Errorf("ca_bundle must contain at least one PEM certificate")
+	}
+
+	return pool, nil
+}
+
+// IssueToken implements TokenIssuer
+func (n *nativeTokenIssuer) IssueToken(ctx context.Context, req *TokenRequest) (*common.TokenResponse, error) {
+	creds, err := loadServiceAccountCredentials(req)
+	if err != nil {
+		return nil, invalidCredentials(err)
+	}
+
+	assertion, err := signAssertion(creds, req.Ctx, time.Now())
+	if err != nil {
+		return nil, invalidCredentials(err)
+	}
+
+	body := map[string]string{
+		"grant_type": jwtBearerGrantType,
+		"assertion":  assertion,
+	}
+	if len(req.RoleIDs) > 0 {
+		scopes := make([]string, 0, len(req.RoleIDs))
+		for _, id := range req.RoleIDs {
+			scopes = append(scopes, "role:"+id)
+		}
+		body["scope"] = strings.Join(scopes, " ")
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode token request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, creds.TokenURI, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build token request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := n.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		exchangeErr := &tokenExchangeError{StatusCode: resp.StatusCode}
+		var errBody struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(respBody, &errBody) == nil {
+			exchangeErr.Message = errBody.Error.Message
+		}
+		return nil, exchangeErr
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"accessToken"`
+		TokenType   string `json:"tokenType"`
+	}
+	if err := json.Unmarshal(respBody, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+
+	return &common.TokenResponse{
+		AccessToken: tokenResp.AccessToken,
+		TokenType:   tokenResp.TokenType,
+	}, nil
+}
+
+// SignDataTokens implements DataTokenSigner with the same claims the Skyflow SDK signs
+func (n *nativeTokenIssuer) SignDataTokens(ctx context.Context, req *SignRequest) ([]common.SignedDataTokensResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("signing request abandoned: %w", err)
+	}
+
+	creds, err := loadServiceAccountCredentials(&TokenRequest{
+		CredentialsJSON:     req.CredentialsJSON,
+		CredentialsFilePath: req.CredentialsFilePath,
+	})
+	if err != nil {
+		return nil, invalidCredentials(err)
+	}
+
+	key, err := parseRSAPrivateKey(creds.PrivateKey)
+	if err != nil {
+		return nil, invalidCredentials(err)
+	}
+
+	now := time.Now()
+	signed := make([]common.SignedDataTokensResponse, 0, len(req.DataTokens))
+	for _, dataToken := range req.DataTokens {
+		claims := map[string]interface{}{
+			"iss": "sdk",
+			"key": creds.KeyID,
+			"aud": creds.TokenURI,
+			"sub": creds.ClientID,
+			"tok": dataToken,
+			"iat": now.Unix(),
+			"exp": now.Add(req.TTL).Unix(),
+		}
+		if req.Ctx != "" {
+			claims["ctx"] = req.Ctx
+		}
+
+		jwt, err := signJWT(key, claims)
+		if err != nil {
+			return nil, invalidCredentials(err)
+		}
+		signed = append(signed, common.SignedDataTokensResponse{Token: dataToken, SignedToken: "signed_token_" + jwt})
+	}
+
+	return signed, nil
+}
+
+// loadServiceAccountCredentials reads and checks the credentials named in a token request
+func loadServiceAccountCredentials(req *TokenRequest) (*serviceAccountCredentials, error) {
+	credentialsJSON := req.CredentialsJSON
+	if req.CredentialsFilePath != "" {
+		raw, err := os.ReadFile(req.CredentialsFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials file: %w", err)
+		}
+		credentialsJSON = string(raw)
+	}
+
+	if credentialsJSON == "" {
+		return nil, errNoCredentials
+	}
+
+	creds, err := parseServiceAccountCredentials(credentialsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	if creds.PrivateKey == "" {
+		return nil, fmt.Errorf("credentials must contain privateKey")
+	}
+
+	u, err := url.Parse(creds.TokenURI)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("credentials tokenURI must be an https URL")
+	}
+
+	return creds, nil
+}
+
+// signAssertion returns the RS256 JWT assertion a service account presents at the token endpoint
+func signAssertion(creds *serviceAccountCredentials, ctxData string, now time.Time) (string, error) {
+	key, err := parseRSAPrivateKey(creds.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	claims := map[string]interface{}{
+		"iss": creds.ClientID,
+		"key": creds.KeyID,
+		"aud": creds.TokenURI,
+		"sub": creds.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(nativeAssertionLifetime).Unix(),
+	}
+	if ctxData != "" {
+		claims["ctx"] = ctxData
+	}
+
+	return signJWT(key, claims)
+}
+
+// signJWT returns claims as an RS256 JWT signed with key
+func signJWT(key *rsa.PrivateKey, claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey parses a PEM "PRIVATE KEY" block holding a PKCS#1 or PKCS#8 RSA key
+func parseRSAPrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("privateKey must be a PEM encoded PRIVATE KEY")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse privateKey: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("privateKey must be an RSA key")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,229 @@
+package backend
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// fakeServerCABundle returns the fake token server's certificate as a PEM CA bundle
+func fakeServerCABundle(server *fakeTokenServer) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+}
+
+func TestNativeIssuer_TokenPath(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	// Not routed through http.DefaultTransport: the mount must trust the server via ca_bundle
+	server := newFakeTokenServer(t)
+
+	b, err := Factory(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	t.Run("Untrusted server fails validation", func(t *testing.T) {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "config",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"credentials_json": server.credentialsJSON(t),
+				"token_issuer":     "native",
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected validation to fail without the server's CA")
+		}
+	})
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"credentials_json": server.credentialsJSON(t),
+			"token_issuer":     "native",
+			"http_timeout":     "5s",
+			"ca_bundle":        fakeServerCABundle(server),
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "roles/payment-risk-engine",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"role_ids": "skyflow-role-read,skyflow-role-write",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write role: err=%v resp=%v", err, resp)
+	}
+
+	t.Run("Config read returns issuer settings", func(t *testing.T) {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "config",
+			Storage:   storage,
+		})
+		if err != nil || resp == nil {
+			t.Fatalf("failed to read config: err=%v", err)
+		}
+		if resp.Data["token_issuer"] != "native" {
+			t.Errorf("expected token_issuer native, got %v", resp.Data["token_issuer"])
+		}
+		if resp.Data["http_timeout"] != int64(5) {
+			t.Errorf("expected http_timeout 5, got %v", resp.Data["http_timeout"])
+		}
+	})
+
+	t.Run("Token is minted with role IDs and ctx", func(t *testing.T) {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.ReadOperation,
+			Path:       "creds/payment-risk-engine",
+			Storage:    storage,
+			Data:       map[string]interface{}{"ctx": "txn:PAY-8934"},
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("token request failed: err=%v resp=%v", err, resp)
+		}
+
+		if resp.Data["access_token"] == "" {
+			t.Error("expected an access token")
+		}
+
+		exchanges := server.accepted()
+		last := exchanges[len(exchanges)-1]
+		if last.Scope != "role:skyflow-role-read role:skyflow-role-write" {
+			t.Errorf("unexpected scope %q", last.Scope)
+		}
+		if last.Ctx != "txn:PAY-8934" {
+			t.Errorf("unexpected ctx %q", last.Ctx)
+		}
+	})
+}
+
+func TestNativeIssuer_IssueToken(t *testing.T) {
+	server := newFakeTokenServer(t)
+
+	issuer, err := newNativeTokenIssuer(&skyflowConfig{CABundle: fakeServerCABundle(server)})
+	if err != nil {
+		t.Fatalf("failed to create issuer: %v", err)
+	}
+
+	t.Run("Cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := issuer.IssueToken(ctx, &TokenRequest{CredentialsJSON: server.credentialsJSON(t)})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("Wrong key is rejected", func(t *testing.T) {
+		creds := testServiceAccountJSON(t, server.clientID, server.keyID, server.tokenURI(), generateTestKey(t))
+
+		_, err := issuer.IssueToken(context.Background(), &TokenRequest{CredentialsJSON: creds})
+		var exchangeErr *tokenExchangeError
+		if !errors.As(err, &exchangeErr) {
+			t.Fatalf("expected tokenExchangeError, got %v", err)
+		}
+		if exchangeErr.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected 401, got %d", exchangeErr.StatusCode)
+		}
+	})
+
+	t.Run("Non-https tokenURI is rejected", func(t *testing.T) {
+		creds := testServiceAccountJSON(t, server.clientID, server.keyID, "http://example.invalid/token", server.key)
+
+		_, err := issuer.IssueToken(context.Background(), &TokenRequest{CredentialsJSON: creds})
+		if err == nil || !strings.Contains(err.Error(), "https") {
+			t.Errorf("expected tokenURI error, got %v", err)
+		}
+	})
+
+	t.Run("Missing credentials", func(t *testing.T) {
+		_, err := issuer.IssueToken(context.Background(), &TokenRequest{})
+		if err == nil || !strings.Contains(err.Error(), "no credentials configured") {
+			t.Errorf("expected missing credentials error, got %v", err)
+		}
+	})
+}
+
+func TestNativeIssuer_Transport(t *testing.T) {
+	issuer, err := newNativeTokenIssuer(&skyflowConfig{
+		HTTPTimeout: 7 * time.Second,
+		HTTPProxy:   "http://proxy.internal:3128",
+	})
+	if err != nil {
+		t.Fatalf("failed to create issuer: %v", err)
+	}
+
+	if issuer.client.Timeout != 7*time.Second {
+		t.Errorf("expected 7s timeout, got %s", issuer.client.Timeout)
+	}
+
+	transport := issuer.client.Transport.(*http.Transport)
+	req, _ := http.NewRequest(http.MethodPost, "https://manage.skyflowapis.com/v1/auth/sa/oauth/token", nil)
+	proxyURL, err := transport.Proxy(req)
+	if err != nil || proxyURL == nil || proxyURL.Host != "proxy.internal:3128" {
+		t.Errorf("expected requests to use the configured proxy, got %v (err=%v)", proxyURL, err)
+	}
+}
+
+func TestNativeIssuer_IssuerFor(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := Factory(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: &logical.InmemStorage{},
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+	backend := b.(*skyflowBackend)
+
+	sdk, err := backend.issuerFor(&skyflowConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := sdk.(sdkTokenIssuer); !ok {
+		t.Errorf("expected the SDK issuer by default, got %T", sdk)
+	}
+
+	first, err := backend.issuerFor(&skyflowConfig{TokenIssuer: tokenIssuerNative})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, _ := backend.issuerFor(&skyflowConfig{TokenIssuer: tokenIssuerNative})
+	if first != second {
+		t.Error("expected the native issuer to be reused while settings are unchanged")
+	}
+
+	third, _ := backend.issuerFor(&skyflowConfig{TokenIssuer: tokenIssuerNative, HTTPTimeout: time.Second})
+	if third == first {
+		t.Error("expected a new native issuer after http_timeout changed")
+	}
+}
@@ -2,6 +2,7 @@ package backend
 
 import (
 	"context"
+	"net/url"
 	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
@@ -31,6 +32,74 @@ func pathConfig(b *skyflowBackend) []*framework.Path {
 					Type:        framework.TypeCommaStringSlice,
 					Description: "Tags for organizing configurations",
 				},
+				"token_cache_refresh_window": {
+					Type:        framework.TypeDurationSecond,
+					Description: "How long before expiry a cached token is refreshed (default: 60s)",
+				},
+				"max_role_ids": {
+					Type:        framework.TypeInt,
+					Description: "Maximum number of Skyflow role IDs a role may hold (default: 10)",
+				},
+				"allowed_role_ids": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "Skyflow role IDs that roles on this mount may use (default: any)",
+				},
+				"history_max_versions": {
+					Type:        framework.TypeInt,
+					Description: "Number of config versions kept in config/history (default: 10)",
+				},
+				"history_max_age": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Prune config/history entries older than this (default: no age limit)",
+				},
+				"rotation_period": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Rotate the service account key automatically this long after the last rotation (default: 0, disabled)",
+				},
+				"management_url": {
+					Type:        framework.TypeString,
+					Description: "Skyflow management API base URL used by config/rotate-root (default: https://manage.skyflowapis.com)",
+				},
+				"token_issuer": {
+					Type:        framework.TypeString,
+					Description: "How tokens are minted: sdk (Skyflow Go SDK) or native (in-plugin JWT-bearer exchange) (default: sdk)",
+				},
+				"http_timeout": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Timeout for each token exchange made by the native issuer (default: 30s)",
+				},
+				"http_proxy": {
+					Type:        framework.TypeString,
+					Description: "Proxy URL for the native issuer (default: HTTPS_PROXY/NO_PROXY from the environment)",
+				},
+				"ca_bundle": {
+					Type:        framework.TypeString,
+					Description: "PEM CA certificates trusted by the native issuer in addition to the system roots",
+				},
+				"request_timeout": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Timeout for a Skyflow token call, including retries (default: 30s)",
+				},
+				"max_retries": {
+					Type:        framework.TypeInt,
+					Description: "Retries of a token call after a transient Skyflow or network error, 0 to 10 (default: 2)",
+				},
+				"retry_backoff_ms": {
+					Type:        framework.TypeInt,
+					Description: "Base delay in milliseconds before the first retry; later retries back off exponentially with jitter (default: 250)",
+				},
+				"circuit_breaker_threshold": {
+					Type:        framework.TypeInt,
+					Description: "Consecutive failed token calls that open the circuit breaker; 0 disables it (default: 5)",
+				},
+				"circuit_breaker_window": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Window the consecutive failures must fall within (default: 60s)",
+				},
+				"circuit_breaker_cooldown": {
+					Type:        framework.TypeDurationSecond,
+					Description: "How long the open breaker fails token calls before letting a probe through (default: 30s)",
+				},
 				"validate_credentials": {
 					Type:        framework.TypeBool,
 					Description: "Validate credentials by generating a test token (default: true)",
@@ -86,6 +155,9 @@ func (b *skyflowBackend) pathConfigWrite(ctx context.Context, req *logical.Reque
 	ctx, span := traces.StartConfigWrite(ctx, operation)
 	defer span.End()
 
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
 	config := defaultConfig()
 
 	// Load existing config if updating
@@ -101,6 +173,8 @@ func (b *skyflowBackend) pathConfigWrite(ctx context.Context, req *logical.Reque
 	}
 
 	// Update fields from request
+	previousCredentialsJSON, previousCredentialsFilePath := config.CredentialsJSON, config.CredentialsFilePath
+
 	if credPath, ok := data.GetOk("credentials_file_path"); ok {
 		config.CredentialsFilePath = credPath.(string)
 		config.CredentialsJSON = "" // Clear JSON if file path is set
@@ -111,6 +185,11 @@ func (b *skyflowBackend) pathConfigWrite(ctx context.Context, req *logical.Reque
 		config.CredentialsFilePath = "" // Clear file path if JSON is set
 	}
 
+	// A replaced key was never rotated by this mount, so its schedule starts from this write
+	if config.CredentialsJSON != previousCredentialsJSON || config.CredentialsFilePath != previousCredentialsFilePath {
+		config.LastRotatedAt = time.Time{}
+	}
+
 	if desc, ok := data.GetOk("description"); ok {
 		config.Description = desc.(string)
 	}
@@ -119,6 +198,77 @@ func (b *skyflowBackend) pathConfigWrite(ctx context.Context, req *logical.Reque
 		config.Tags = tags.([]string)
 	}
 
+	if window, ok := data.GetOk("token_cache_refresh_window"); ok {
+		config.TokenCacheRefreshWindow = time.Duration(window.(int)) * time.Second
+	}
+
+	if maxRoleIDs, ok := data.GetOk("max_role_ids"); ok {
+		config.MaxRoleIDs = maxRoleIDs.(int)
+	}
+
+	if allowedRoleIDs, ok := data.GetOk("allowed_role_ids"); ok {
+		config.AllowedRoleIDs = allowedRoleIDs.([]string)
+	}
+
+	if historyMaxVersions, ok := data.GetOk("history_max_versions"); ok {
+		config.HistoryMaxVersions = historyMaxVersions.(int)
+	}
+
+	if historyMaxAge, ok := data.GetOk("history_max_age"); ok {
+		config.HistoryMaxAge = time.Duration(historyMaxAge.(int)) * time.Second
+	}
+
+	if rotationPeriod, ok := data.GetOk("rotation_period"); ok {
+		config.RotationPeriod = time.Duration(rotationPeriod.(int)) * time.Second
+	}
+
+	if managementURL, ok := data.GetOk("management_url"); ok {
+		config.ManagementURL = managementURL.(string)
+	}
+
+	if tokenIssuer, ok := data.GetOk("token_issuer"); ok {
+		config.TokenIssuer = tokenIssuer.(string)
+	}
+
+	if httpTimeout, ok := data.GetOk("http_timeout"); ok {
+		config.HTTPTimeout = time.Duration(httpTimeout.(int)) * time.Second
+	}
+
+	if httpProxy, ok := data.GetOk("http_proxy"); ok {
+		config.HTTPProxy = httpProxy.(string)
+	}
+
+	if caBundle, ok := data.GetOk("ca_bundle"); ok {
+		config.CABundle = caBundle.(string)
+	}
+
+	if requestTimeout, ok := data.GetOk("request_timeout"); ok {
+		config.RequestTimeout = time.Duration(requestTimeout.(int)) * time.Second
+	}
+
+	if maxRetries, ok := data.GetOk("max_retries"); ok {
+		retries := maxRetries.(int)
+		config.MaxRetries = &retries
+	}
+
+	if threshold, ok := data.GetOk("circuit_breaker_threshold"); ok {
+		breakerThreshold := threshold.(int)
+		config.CircuitBreakerThreshold = &breakerThreshold
+	}
+
+	if window, ok := data.GetOk("circuit_breaker_window"); ok {
+		config.CircuitBreakerWindow = time.Duration(window.(int)) * time.Second
+	}
+
+	if cooldown, ok := data.GetOk("circuit_breaker_cooldown"); ok {
+		config.CircuitBreakerCooldown = time.Duration(cooldown.(int)) * time.Second
+	}
+
+	// Sub-second backoffs are common, so this is set in milliseconds rather than seconds
+	if retryBackoff, ok := data.GetOk("retry_backoff_ms"); ok {
+		config.RetryBackoff = time.Duration(retryBackoff.(int)) * time.Millisecond
+	}
+
 	// Validate configuration
 	if err := config.validate(); err != nil {
 		traces.RecordConfigErrorWithMessage(span, err.Error())
@@ -133,7 +283,7 @@ func (b *skyflowBackend) pathConfigWrite(ctx context.Context, req *logical.Reque
 
 	if validateCreds {
 		b.Logger().Info("validating credentials")
-		if err := config.validateCredentials(); err != nil {
+		if err := b.validateCredentials(ctx, config); err != nil {
 			traces.RecordConfigError(span, err)
 			return logical.ErrorResponse("credential validation failed: %s", err.Error()), nil
 		}
@@ -146,6 +296,10 @@ func (b *skyflowBackend) pathConfigWrite(ctx context.Context, req *logical.Reque
 		return nil, err
 	}
 
+	// Tokens minted with the previous credentials must not be served
+	b.tokenCache.purge()
+	b.breakers.reset()
+
 	// Record metrics
 	if m := b.metrics(); m != nil {
 		m.RecordConfigWrite(ctx, operation)
@@ -187,18 +341,45 @@ func (b *skyflowBackend) pathConfigRead(ctx context.Context, req *logical.Reques
 
 	// Don't return sensitive credentials, only metadata
 	responseData := map[string]interface{}{
-		"credentials_configured": true,
-		"description":            config.Description,
-		"tags":                   config.Tags,
-		"version":                config.Version,
-		"last_updated":           config.LastUpdated.Format(time.RFC3339),
+		"credentials_configured":     true,
+		"description":                config.Description,
+		"tags":                       config.Tags,
+		"version":                    config.Version,
+		"last_updated":               config.LastUpdated.Format(time.RFC3339),
+		"token_cache_refresh_window": int64(config.tokenCacheRefreshWindow().Seconds()),
+		"max_role_ids":               config.maxRoleIDs(),
+		"allowed_role_ids":           config.AllowedRoleIDs,
+		"history_max_versions":       config.historyMaxVersions(),
+		"history_max_age":            int64(config.HistoryMaxAge.Seconds()),
+		"management_url":             config.managementURL(),
+		"rotation_period":            int64(config.RotationPeriod.Seconds()),
+		"credentials_type":           config.credentialsType(),
+		"token_issuer":               config.tokenIssuer(),
+		"http_timeout":               int64(config.httpTimeout().Seconds()),
+		"ca_bundle":                  config.CABundle,
+		"request_timeout":            int64(config.requestTimeout().Seconds()),
+		"max_retries":                config.maxRetries(),
+		"retry_backoff_ms":           config.retryBackoff().Milliseconds(),
+		"circuit_breaker_threshold":  config.circuitBreakerSettings().threshold,
+		"circuit_breaker_window":     int64(config.circuitBreakerSettings().window.Seconds()),
+		"circuit_breaker_cooldown":   int64(config.circuitBreakerSettings().cooldown.Seconds()),
+	}
+
+	// Proxy URLs may embed credentials
+	if proxyURL, err := url.Parse(config.HTTPProxy); err == nil && config.HTTPProxy != "" {
+		responseData["http_proxy"] = proxyURL.Redacted()
+	}
+
+	if !config.LastRotatedAt.IsZero() {
+		responseData["last_rotated_at"] = config.LastRotatedAt.Format(time.RFC3339)
+	}
+
+	if next := config.nextRotationAt(); !next.IsZero() {
+		responseData["next_rotation_at"] = next.Format(time.RFC3339)
 	}
 
 	if config.CredentialsFilePath != "" {
-		responseData["credentials_type"] = "file_path"
 		responseData["credentials_file_path"] = config.CredentialsFilePath
-	} else {
-		responseData["credentials_type"] = "json"
 	}
 
 	return &logical.Response{
@@ -212,11 +393,17 @@ func (b *skyflowBackend) pathConfigDelete(ctx context.Context, req *logical.Requ
 	ctx, span := traces.StartConfigWrite(ctx, "delete")
 	defer span.End()
 
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
 	if err := b.deleteConfig(ctx, req.Storage); err != nil {
 		traces.RecordConfigError(span, err)
 		return nil, err
 	}
 
+	b.tokenCache.purge()
+	b.breakers.reset()
+
 	traces.RecordConfigUpdated(span)
 	b.Logger().Info("configuration deleted")
 
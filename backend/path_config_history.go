@@ -0,0 +1,221 @@
+package backend
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathConfigHistory returns the path configuration for browsing config version history
+func pathConfigHistory(b *skyflowBackend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "config/history/?$",
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.pathConfigHistoryList,
+					Summary:  "List retained configuration versions.",
+				},
+			},
+
+			HelpSynopsis:    "List configuration version history.",
+			HelpDescription: "List the configuration versions retained under the mount's history settings.",
+		},
+		{
+			Pattern: "config/history/(?P<version>\\d+)",
+
+			Fields: map[string]*framework.FieldSchema{
+				"version": {
+					Type:        framework.TypeInt,
+					Description: "Configuration version",
+					Required:    true,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathConfigHistoryRead,
+					Summary:  "Read metadata for a configuration version.",
+				},
+			},
+
+			HelpSynopsis:    "Read configuration version metadata.",
+			HelpDescription: "Read the timestamp, description, credential type and credential fingerprint recorded for a configuration version. Credentials are never returned.",
+		},
+		{
+			Pattern: "config/rollback",
+
+			Fields: map[string]*framework.FieldSchema{
+				"version": {
+					Type:        framework.TypeInt,
+					Description: "Configuration version to restore",
+					Required:    true,
+				},
+				"validate_credentials": {
+					Type:        framework.TypeBool,
+					Description: "Validate the restored credentials by generating a test token (default: true)",
+					Default:     true,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathConfigRollback,
+					Summary:  "Restore a previous configuration version.",
+				},
+			},
+
+			HelpSynopsis:    "Roll back the configuration to a previous version.",
+			HelpDescription: "Restore the credentials and settings recorded for a configuration version. The restored configuration is saved as a new version.",
+		},
+	}
+}
+
+// pathConfigHistoryList lists retained config versions
+func (b *skyflowBackend) pathConfigHistoryList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	traces := b.traces()
+	ctx, span := traces.StartConfigHistory(ctx, "list")
+	defer span.End()
+
+	versions, err := b.listConfigHistory(ctx, req.Storage)
+	if err != nil {
+		traces.RecordConfigError(span, err)
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(versions))
+	for _, version := range versions {
+		keys = append(keys, strconv.Itoa(version))
+	}
+
+	traces.RecordConfigFound(span, len(keys) > 0)
+	return logical.ListResponse(keys), nil
+}
+
+// pathConfigHistoryRead returns metadata for a single config version
+func (b *skyflowBackend) pathConfigHistoryRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	version := data.Get("version").(int)
+
+	traces := b.traces()
+	ctx, span := traces.StartConfigHistory(ctx, "read")
+	defer span.End()
+
+	history, err := b.getConfigHistory(ctx, req.Storage, version)
+	if err != nil {
+		traces.RecordConfigError(span, err)
+		return nil, err
+	}
+
+	if history == nil {
+		traces.RecordConfigFound(span, false)
+		return nil, nil
+	}
+
+	traces.RecordConfigFound(span, true)
+
+	return &logical.Response{
+		Data: history.responseData(),
+	}, nil
+}
+
+// pathConfigRollback restores a previous config version as a new version
+func (b *skyflowBackend) pathConfigRollback(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	version := data.Get("version").(int)
+
+	traces := b.traces()
+	ctx, span := traces.StartConfigRollback(ctx, version)
+	defer span.End()
+
+	m := b.metrics()
+	recordRollback := func(status string) {
+		if m != nil {
+			m.RecordConfigRollback(ctx, status)
+		}
+	}
+
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
+	history, err := b.getConfigHistory(ctx, req.Storage, version)
+	if err != nil {
+		traces.RecordConfigError(span, err)
+		recordRollback("error")
+		return nil, err
+	}
+
+	if history == nil {
+		traces.RecordConfigErrorWithMessage(span, "version not found")
+		recordRollback("not_found")
+		return logical.ErrorResponse("config version %d not found", version), nil
+	}
+
+	if history.Config == nil {
+		traces.RecordConfigErrorWithMessage(span, "version not restorable")
+		recordRollback("not_restorable")
+		return logical.ErrorResponse("config version %d has no stored configuration to restore", version), nil
+	}
+
+	restored := *history.Config
+	if err := restored.validate(); err != nil {
+		traces.RecordConfigErrorWithMessage(span, err.Error())
+		recordRollback("invalid")
+		return logical.ErrorResponse("config version %d is invalid: %s", version, err.Error()), nil
+	}
+
+	validateCreds := true
+	if val, ok := data.GetOk("validate_credentials"); ok {
+		validateCreds = val.(bool)
+	}
+
+	if validateCreds {
+		b.Logger().Info("validating credentials", "rollback_version", version)
+		if err := b.validateCredentials(ctx, &restored); err != nil {
+			traces.RecordConfigError(span, err)
+			recordRollback("validation_failed")
+			return logical.ErrorResponse("credential validation failed: %s", err.Error()), nil
+		}
+	}
+
+	// The snapshot's rotation timestamp belongs to the past; the current key keeps its own schedule
+	// and a restored older key starts a new one, so a rollback never makes a rotation due at once
+	current, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		traces.RecordConfigError(span, err)
+		recordRollback("error")
+		return nil, err
+	}
+	restored.LastRotatedAt = time.Time{}
+	if current != nil && current.CredentialsJSON == restored.CredentialsJSON && current.CredentialsFilePath == restored.CredentialsFilePath {
+		restored.LastRotatedAt = current.LastRotatedAt
+	}
+
+	// saveConfigWithHistory continues the version sequence, so the restored config gets a new version
+	if err := b.saveConfigWithHistory(ctx, req.Storage, &restored); err != nil {
+		traces.RecordConfigError(span, err)
+		recordRollback("error")
+		return nil, err
+	}
+
+	// Tokens minted with the replaced credentials must not be served
+	b.tokenCache.purge()
+	b.breakers.reset()
+
+	recordRollback("success")
+	traces.RecordConfigUpdated(span)
+
+	b.Logger().Info("configuration rolled back",
+		"restored_version", version,
+		"version", restored.Version,
+	)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"version":          restored.Version,
+			"restored_version": version,
+		},
+	}, nil
+}
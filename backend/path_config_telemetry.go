@@ -0,0 +1,263 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathConfigTelemetry returns the path configuration for a mount's telemetry settings
+func pathConfigTelemetry(b *skyflowBackend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "config/telemetry",
+
+			Fields: map[string]*framework.FieldSchema{
+				"enabled": {
+					Type:        framework.TypeBool,
+					Description: "Master switch for this mount's traces and metrics (default: TELEMETRY_ENABLED)",
+				},
+				"traces_enabled": {
+					Type:        framework.TypeBool,
+					Description: "Export traces; true needs traces_endpoint or an environment endpoint (default: TELEMETRY_TRACES_ENABLED)",
+				},
+				"metrics_enabled": {
+					Type:        framework.TypeBool,
+					Description: "Export metrics; true needs metrics_endpoint, an environment endpoint or prometheus_enabled (default: TELEMETRY_METRICS_ENABLED)",
+				},
+				"traces_endpoint": {
+					Type:        framework.TypeString,
+					Description: "OTLP traces endpoint URL; empty uses OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or the environment default",
+				},
+				"traces_protocol": {
+					Type:        framework.TypeString,
+					Description: "OTLP transport for traces: grpc or http/protobuf (default: OTEL_EXPORTER_OTLP_TRACES_PROTOCOL, then OTEL_EXPORTER_OTLP_PROTOCOL)",
+				},
+				"metrics_endpoint": {
+					Type:        framework.TypeString,
+					Description: "OTLP metrics endpoint URL; empty uses OTEL_EXPORTER_OTLP_METRICS_ENDPOINT or the environment default",
+				},
+				"metrics_protocol": {
+					Type:        framework.TypeString,
+					Description: "OTLP transport for metrics: grpc or http/protobuf (default: OTEL_EXPORTER_OTLP_METRICS_PROTOCOL, then OTEL_EXPORTER_OTLP_PROTOCOL)",
+				},
+				"traces_headers": {
+					Type:        framework.TypeKVPairs,
+					Description: "Headers sent with exported traces, e.g. collector credentials; empty sends none (default: OTEL_EXPORTER_OTLP_HEADERS)",
+				},
+				"metrics_headers": {
+					Type:        framework.TypeKVPairs,
+					Description: "Headers sent with exported metrics; empty sends none (default: OTEL_EXPORTER_OTLP_METRICS_HEADERS)",
+				},
+				"sample_rate": {
+					Type:        framework.TypeFloat,
+					Description: "Fraction of traces sampled, 0.0 to 1.0 (default: TELEMETRY_SAMPLE_RATE)",
+				},
+				"metrics_export_interval": {
+					Type:        framework.TypeDurationSecond,
+					Description: "How often metrics are exported (default: TELEMETRY_METRICS_EXPORT_INTERVAL)",
+				},
+				"prometheus_enabled": {
+					Type:        framework.TypeBool,
+					Description: "Serve this mount's metrics in the Prometheus text format on the metrics path (default: TELEMETRY_PROMETHEUS_ENABLED)",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathConfigTelemetryWrite,
+					Summary:  "Configure this mount's telemetry and apply it.",
+				},
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathConfigTelemetryRead,
+					Summary:  "Read this mount's effective telemetry configuration.",
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.pathConfigTelemetryDelete,
+					Summary:  "Remove this mount's telemetry settings and return to the environment's.",
+				},
+			},
+
+			HelpSynopsis:    "Configure telemetry for this mount.",
+			HelpDescription: "Override the telemetry endpoints, headers, sample rate and switches the plugin reads from its environment. Changes are applied without reloading the plugin.",
+		},
+	}
+}
+
+// pathConfigTelemetryWrite updates the mount's telemetry settings and applies them
+func (b *skyflowBackend) pathConfigTelemetryWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
+	config, err := b.getTelemetryConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = &telemetryConfig{}
+	}
+
+	if enabled, ok := data.GetOk("enabled"); ok {
+		v := enabled.(bool)
+		config.Enabled = &v
+	}
+
+	if tracesEnabled, ok := data.GetOk("traces_enabled"); ok {
+		v := tracesEnabled.(bool)
+		config.TracesEnabled = &v
+	}
+
+	if metricsEnabled, ok := data.GetOk("metrics_enabled"); ok {
+		v := metricsEnabled.(bool)
+		config.MetricsEnabled = &v
+	}
+
+	if endpoint, ok := data.GetOk("traces_endpoint"); ok {
+		config.TracesEndpoint = endpoint.(string)
+	}
+
+	if endpoint, ok := data.GetOk("metrics_endpoint"); ok {
+		config.MetricsEndpoint = endpoint.(string)
+	}
+
+	if protocol, ok := data.GetOk("traces_protocol"); ok {
+		config.TracesProtocol = protocol.(string)
+	}
+
+	if protocol, ok := data.GetOk("metrics_protocol"); ok {
+		config.MetricsProtocol = protocol.(string)
+	}
+
+	// An explicit empty value sends no headers instead of the environment's
+	if headers, ok := data.GetOk("traces_headers"); ok {
+		config.TracesHeaders = explicitHeaders(headers.(map[string]string))
+	}
+
+	if headers, ok := data.GetOk("metrics_headers"); ok {
+		config.MetricsHeaders = explicitHeaders(headers.(map[string]string))
+	}
+
+	if sampleRate, ok := data.GetOk("sample_rate"); ok {
+		v := sampleRate.(float64)
+		config.SampleRate = &v
+	}
+
+	if interval, ok := data.GetOk("metrics_export_interval"); ok {
+		config.MetricsExportInterval = time.Duration(interval.(int)) * time.Second
+	}
+
+	if prometheusEnabled, ok := data.GetOk("prometheus_enabled"); ok {
+		v := prometheusEnabled.(bool)
+		config.PrometheusEnabled = &v
+	}
+
+	if err := config.validate(); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	// Turning a signal on needs somewhere to send it
+	if resolved, err := b.effectiveTelemetryConfig(config); err == nil {
+		if config.TracesEnabled != nil && *config.TracesEnabled && resolved.TracesEndpoint == "" {
+			return logical.ErrorResponse("traces_enabled requires traces_endpoint: the environment has no traces collector"), nil
+		}
+		if config.MetricsEnabled != nil && *config.MetricsEnabled && resolved.MetricsEndpoint == "" && !resolved.PrometheusEnabled {
+			return logical.ErrorResponse("metrics_enabled requires metrics_endpoint or prometheus_enabled: the environment has no metrics collector"), nil
+		}
+	}
+
+	config.LastUpdated = time.Now()
+	if err := b.saveTelemetryConfig(ctx, req.Storage, config); err != nil {
+		return nil, err
+	}
+
+	if err := b.applyTelemetryConfig(ctx, config); err != nil {
+		b.Logger().Warn("failed to apply telemetry configuration", "error", err)
+		resp := &logical.Response{}
+		resp.AddWarning(fmt.Sprintf("telemetry configuration saved but not applied: %v", err))
+		return resp, nil
+	}
+
+	b.Logger().Info("telemetry configuration updated")
+
+	return nil, nil
+}
+
+// pathConfigTelemetryRead returns the mount's effective telemetry configuration. Header values
+// may hold collector credentials, so only their names are returned.
+func (b *skyflowBackend) pathConfigTelemetryRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.getTelemetryConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := b.effectiveTelemetryConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	responseData := map[string]interface{}{
+		"enabled":                 resolved.Enabled && !resolved.UseNoOp,
+		"traces_enabled":          resolved.IsTracesEnabled(),
+		"metrics_enabled":         resolved.IsMetricsEnabled(),
+		"traces_endpoint":         resolved.TracesEndpoint,
+		"metrics_endpoint":        resolved.MetricsEndpoint,
+		"traces_protocol":         resolved.TracesProtocol,
+		"metrics_protocol":        resolved.MetricsProtocol,
+		"traces_headers":          headerNames(resolved.TracesHeaders),
+		"metrics_headers":         headerNames(resolved.MetricsHeaders),
+		"sample_rate":             resolved.SampleRate,
+		"metrics_export_interval": int64(resolved.MetricsExportInterval.Seconds()),
+		"prometheus_enabled":      resolved.IsPrometheusEnabled(),
+		"runtime_local":           resolved.UseNoOp,
+		"overridden":              []string{},
+	}
+
+	if config != nil {
+		responseData["overridden"] = config.overriddenFields()
+		responseData["last_updated"] = config.LastUpdated.Format(time.RFC3339)
+	}
+
+	return &logical.Response{
+		Data: responseData,
+	}, nil
+}
+
+// pathConfigTelemetryDelete removes the mount's telemetry settings and returns to the environment's
+func (b *skyflowBackend) pathConfigTelemetryDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
+	if err := b.deleteTelemetryConfig(ctx, req.Storage); err != nil {
+		return nil, err
+	}
+
+	if err := b.applyTelemetryConfig(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	b.Logger().Info("telemetry configuration deleted")
+
+	return nil, nil
+}
+
+// explicitHeaders returns headers as a non-nil map, so an empty value is kept as an override
+func explicitHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return map[string]string{}
+	}
+	return headers
+}
+
+// headerNames returns the sorted names of a header map
+func headerNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
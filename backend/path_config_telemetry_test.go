@@ -0,0 +1,241 @@
+package backend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestPathConfigTelemetry(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	t.Setenv("ENV", "dev")
+	t.Setenv("RUNTIME_LOCAL", "")
+	t.Setenv("TELEMETRY_ENABLED", "true")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", collector.URL+"/v1/traces")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", collector.URL+"/v1/metrics")
+
+	lb, err := Factory(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+		BackendUUID: "order-mount",
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+	b := lb.(*skyflowBackend)
+	defer b.Cleanup(ctx)
+
+	writeTelemetry := func(data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "config/telemetry",
+			Storage:   storage,
+			Data:      data,
+		})
+	}
+
+	readTelemetry := func(t *testing.T) map[string]interface{} {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "config/telemetry",
+			Storage:   storage,
+		})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("failed to read config/telemetry: err=%v resp=%v", err, resp)
+		}
+		return resp.Data
+	}
+
+	t.Run("Defaults come from the environment", func(t *testing.T) {
+		data := readTelemetry(t)
+		if data["traces_endpoint"] != collector.URL+"/v1/traces" {
+			t.Errorf("unexpected traces_endpoint: %v", data["traces_endpoint"])
+		}
+		if data["traces_protocol"] != "http/protobuf" {
+			t.Errorf("unexpected traces_protocol: %v", data["traces_protocol"])
+		}
+		if len(data["overridden"].([]string)) != 0 {
+			t.Errorf("expected no overrides, got %v", data["overridden"])
+		}
+		if !b.traces().IsEnabled() {
+			t.Error("expected tracing enabled from the environment")
+		}
+	})
+
+	t.Run("Invalid settings are rejected", func(t *testing.T) {
+		for _, data := range []map[string]interface{}{
+			{"sample_rate": 1.5},
+			{"traces_endpoint": "collector:4318"},
+			{"metrics_endpoint": "ftp://collector/v1/metrics"},
+			{"traces_protocol": "thrift"},
+		} {
+			resp, err := writeTelemetry(data)
+			if err != nil || resp == nil || !resp.IsError() {
+				t.Errorf("expected %v to be rejected, got err=%v resp=%v", data, err, resp)
+			}
+		}
+	})
+
+	t.Run("Overrides are applied live", func(t *testing.T) {
+		before := b.traces()
+
+		resp, err := writeTelemetry(map[string]interface{}{
+			"sample_rate":     0.25,
+			"traces_endpoint": collector.URL + "/mount/v1/traces",
+			"traces_headers":  map[string]interface{}{"authorization": "Bearer collector-token"},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write config/telemetry: err=%v resp=%v", err, resp)
+		}
+
+		if b.traces() == before {
+			t.Error("expected new trace providers after the write")
+		}
+
+		data := readTelemetry(t)
+		if data["sample_rate"] != 0.25 {
+			t.Errorf("expected sample_rate 0.25, got %v", data["sample_rate"])
+		}
+		if data["traces_endpoint"] != collector.URL+"/mount/v1/traces" {
+			t.Errorf("unexpected traces_endpoint: %v", data["traces_endpoint"])
+		}
+		if headers := data["traces_headers"].([]string); len(headers) != 1 || headers[0] != "authorization" {
+			t.Errorf("expected only header names, got %v", headers)
+		}
+		if overridden := data["overridden"].([]string); len(overridden) != 3 {
+			t.Errorf("expected 3 overridden fields, got %v", overridden)
+		}
+	})
+
+	t.Run("Empty headers send none", func(t *testing.T) {
+		resp, err := writeTelemetry(map[string]interface{}{"traces_headers": map[string]interface{}{}})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write config/telemetry: err=%v resp=%v", err, resp)
+		}
+
+		data := readTelemetry(t)
+		if headers := data["traces_headers"].([]string); len(headers) != 0 {
+			t.Errorf("expected no traces headers, got %v", headers)
+		}
+		if !slices.Contains(data["overridden"].([]string), "traces_headers") {
+			t.Errorf("expected traces_headers to be overridden, got %v", data["overridden"])
+		}
+	})
+
+	t.Run("Switches turn a signal back on", func(t *testing.T) {
+		for _, enabled := range []bool{false, true} {
+			resp, err := writeTelemetry(map[string]interface{}{"traces_enabled": enabled})
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("failed to write config/telemetry: err=%v resp=%v", err, resp)
+			}
+			if b.traces().IsEnabled() != enabled {
+				t.Errorf("expected tracing enabled=%v", enabled)
+			}
+		}
+	})
+
+	t.Run("Disabling turns off the mount's telemetry", func(t *testing.T) {
+		resp, err := writeTelemetry(map[string]interface{}{"enabled": false})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write config/telemetry: err=%v resp=%v", err, resp)
+		}
+
+		if b.traces() != nil || b.metrics() != nil {
+			t.Error("expected no providers while disabled")
+		}
+	})
+
+	t.Run("Delete returns to the environment", func(t *testing.T) {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.DeleteOperation,
+			Path:      "config/telemetry",
+			Storage:   storage,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to delete config/telemetry: err=%v resp=%v", err, resp)
+		}
+
+		if !b.traces().IsEnabled() {
+			t.Error("expected tracing enabled from the environment again")
+		}
+		if data := readTelemetry(t); data["sample_rate"] != 1.0 {
+			t.Errorf("expected the environment's sample_rate, got %v", data["sample_rate"])
+		}
+	})
+
+	t.Run("Invalidation reloads settings written by another node", func(t *testing.T) {
+		disabled := false
+		if err := b.saveTelemetryConfig(ctx, storage, &telemetryConfig{Enabled: &disabled}); err != nil {
+			t.Fatalf("failed to save telemetry config: %v", err)
+		}
+
+		b.invalidate(ctx, telemetryConfigStorageKey)
+
+		if b.traces() != nil {
+			t.Error("expected the stored settings to be applied")
+		}
+	})
+}
+
+func TestPathConfigTelemetry_EnableNeedsEndpoint(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	// An environment without default collectors
+	t.Setenv("ENV", "sandbox")
+	t.Setenv("RUNTIME_LOCAL", "")
+	t.Setenv("TELEMETRY_ENABLED", "true")
+	t.Setenv("TELEMETRY_PROMETHEUS_ENABLED", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "")
+
+	lb, err := Factory(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+		BackendUUID: "sandbox-mount",
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+	b := lb.(*skyflowBackend)
+	defer b.Cleanup(ctx)
+
+	writeTelemetry := func(data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "config/telemetry",
+			Storage:   storage,
+			Data:      data,
+		})
+	}
+
+	for _, data := range []map[string]interface{}{
+		{"traces_enabled": true},
+		{"metrics_enabled": true},
+	} {
+		resp, err := writeTelemetry(data)
+		if err != nil || resp == nil || !resp.IsError() {
+			t.Errorf("expected %v without an endpoint to be rejected, got err=%v resp=%v", data, err, resp)
+		}
+	}
+
+	resp, err := writeTelemetry(map[string]interface{}{"metrics_enabled": true, "prometheus_enabled": true})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Errorf("expected metrics_enabled with prometheus_enabled to be accepted, got err=%v resp=%v", err, resp)
+	}
+}
@@ -0,0 +1,292 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathCredentials returns the path configuration for managing named credential sets
+func pathCredentials(b *skyflowBackend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "credentials/?$",
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.pathCredentialsList,
+					Summary:  "List all named credential sets.",
+				},
+			},
+
+			HelpSynopsis:    "List named credential sets.",
+			HelpDescription: "List all Skyflow service account credential sets configured on this mount.",
+		},
+		{
+			Pattern: "credentials/" + framework.GenericNameRegex("name"),
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the credential set",
+					Required:    true,
+				},
+				"credentials_file_path": {
+					Type:        framework.TypeString,
+					Description: "Path to Skyflow service account credentials JSON file",
+				},
+				"credentials_json": {
+					Type:        framework.TypeString,
+					Description: "Skyflow service account credentials as JSON string",
+				},
+				"description": {
+					Type:        framework.TypeString,
+					Description: "Description of this credential set",
+				},
+				"tags": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "Tags for organizing credential sets",
+				},
+				"validate_credentials": {
+					Type:        framework.TypeBool,
+					Description: "Validate credentials by generating a test token (default: true)",
+					Default:     true,
+				},
+			},
+
+			ExistenceCheck: b.pathCredentialsExistenceCheck,
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathCredentialsWrite,
+					Summary:  "Create a named Skyflow credential set.",
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathCredentialsWrite,
+					Summary:  "Update a named Skyflow credential set.",
+				},
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathCredentialsRead,
+					Summary:  "Read a named credential set's metadata.",
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.pathCredentialsDelete,
+					Summary:  "Delete a named credential set.",
+				},
+			},
+
+			HelpSynopsis:    "Manage named Skyflow credential sets.",
+			HelpDescription: "Store multiple Skyflow service account credentials on one mount. Roles select a credential set with their credential_set field.",
+		},
+	}
+}
+
+// pathCredentialsExistenceCheck checks if a credential set exists
+func (b *skyflowBackend) pathCredentialsExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	name := data.Get("name").(string)
+	set, err := b.getCredentialSet(ctx, req.Storage, name)
+	if err != nil {
+		return false, err
+	}
+
+	return set != nil, nil
+}
+
+// pathCredentialsList lists all credential sets
+func (b *skyflowBackend) pathCredentialsList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	traces := b.traces()
+	ctx, span := traces.StartCredentialsList(ctx)
+	defer span.End()
+
+	sets, err := b.listCredentialSets(ctx, req.Storage)
+	if err != nil {
+		traces.RecordConfigError(span, err)
+		return nil, err
+	}
+
+	traces.RecordConfigFound(span, len(sets) > 0)
+	return logical.ListResponse(sets), nil
+}
+
+// pathCredentialsWrite handles create and update operations for credential sets
+func (b *skyflowBackend) pathCredentialsWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	if name == "" {
+		return logical.ErrorResponse("credential set name is required"), nil
+	}
+
+	operation := "create"
+	if req.Operation == logical.UpdateOperation {
+		operation = "update"
+	}
+
+	traces := b.traces()
+	ctx, span := traces.StartCredentialsWrite(ctx, name, operation)
+	defer span.End()
+
+	// Load existing credential set or create new one
+	set := defaultCredentialSet(name)
+	if req.Operation == logical.UpdateOperation {
+		existingSet, err := b.getCredentialSet(ctx, req.Storage, name)
+		if err != nil {
+			traces.RecordConfigError(span, err)
+			return nil, err
+		}
+		if existingSet != nil {
+			set = existingSet
+		}
+	}
+
+	// Update fields from request
+	if credPath, ok := data.GetOk("credentials_file_path"); ok {
+		set.CredentialsFilePath = credPath.(string)
+		set.CredentialsJSON = "" // Clear JSON if file path is set
+	}
+
+	if credJSON, ok := data.GetOk("credentials_json"); ok {
+		set.CredentialsJSON = credJSON.(string)
+		set.CredentialsFilePath = "" // Clear file path if JSON is set
+	}
+
+	if desc, ok := data.GetOk("description"); ok {
+		set.Description = desc.(string)
+	}
+
+	if tags, ok := data.GetOk("tags"); ok {
+		set.Tags = tags.([]string)
+	}
+
+	// Validate credential set
+	if err := set.validate(); err != nil {
+		traces.RecordConfigErrorWithMessage(span, err.Error())
+		return logical.ErrorResponse("invalid credential set: %s", err.Error()), nil
+	}
+
+	// Validate credentials if requested
+	validateCreds := true
+	if val, ok := data.GetOk("validate_credentials"); ok {
+		validateCreds = val.(bool)
+	}
+
+	if validateCreds {
+		b.Logger().Info("validating credentials", "credential_set", name)
+
+		// Validate with the mount's token issuer settings, as tokens will be minted with them
+		base, err := b.getConfig(ctx, req.Storage)
+		if err != nil {
+			traces.RecordConfigError(span, err)
+			return nil, err
+		}
+		if base == nil {
+			base = defaultConfig()
+		}
+
+		if err := b.validateCredentials(ctx, set.toConfig(base)); err != nil {
+			traces.RecordConfigError(span, err)
+			return logical.ErrorResponse("credential validation failed: %s", err.Error()), nil
+		}
+		b.Logger().Info("credentials validated successfully", "credential_set", name)
+	}
+
+	// Save credential set
+	if err := b.saveCredentialSet(ctx, req.Storage, set); err != nil {
+		traces.RecordConfigError(span, err)
+		return nil, err
+	}
+
+	// Tokens minted with the previous credentials must not be served
+	b.tokenCache.purge()
+	b.breakers.resetSet(name)
+
+	// Record metrics
+	if m := b.metrics(); m != nil {
+		m.RecordConfigWrite(ctx, "credentials_"+operation)
+	}
+
+	traces.RecordConfigUpdated(span)
+
+	b.Logger().Info("credential set saved", "name", name, "operation", req.Operation)
+
+	return nil, nil
+}
+
+// pathCredentialsRead handles read operations for credential sets
+func (b *skyflowBackend) pathCredentialsRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	traces := b.traces()
+	ctx, span := traces.StartCredentialsRead(ctx, name)
+	defer span.End()
+
+	// Record metrics
+	if m := b.metrics(); m != nil {
+		m.RecordConfigRead(ctx, "credentials_"+string(req.Operation))
+	}
+
+	set, err := b.getCredentialSet(ctx, req.Storage, name)
+	if err != nil {
+		traces.RecordConfigError(span, err)
+		return nil, err
+	}
+
+	if set == nil {
+		traces.RecordConfigFound(span, false)
+		return nil, nil
+	}
+
+	traces.RecordConfigFound(span, true)
+
+	// Don't return sensitive credentials, only metadata
+	responseData := map[string]interface{}{
+		"name":             set.Name,
+		"credentials_type": set.credentialsType(),
+		"description":      set.Description,
+		"tags":             set.Tags,
+		"created_at":       set.CreatedAt.Format(time.RFC3339),
+		"updated_at":       set.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if set.CredentialsFilePath != "" {
+		responseData["credentials_file_path"] = set.CredentialsFilePath
+	}
+
+	return &logical.Response{
+		Data: responseData,
+	}, nil
+}
+
+// pathCredentialsDelete handles delete operations for credential sets
+func (b *skyflowBackend) pathCredentialsDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	traces := b.traces()
+	ctx, span := traces.StartCredentialsDelete(ctx, name)
+	defer span.End()
+
+	// Refuse to delete a credential set that roles still mint tokens with
+	users, err := b.rolesUsingCredentialSet(ctx, req.Storage, name)
+	if err != nil {
+		traces.RecordConfigError(span, err)
+		return nil, err
+	}
+	if len(users) > 0 {
+		traces.RecordConfigErrorWithMessage(span, "credential set in use")
+		return logical.ErrorResponse("credential set %q is used by roles: %s", name, strings.Join(users, ", ")), nil
+	}
+
+	if err := b.deleteCredentialSet(ctx, req.Storage, name); err != nil {
+		traces.RecordConfigError(span, err)
+		return nil, err
+	}
+
+	b.tokenCache.purge()
+	b.breakers.resetSet(name)
+
+	traces.RecordConfigUpdated(span)
+	b.Logger().Info("credential set deleted", "name", name)
+
+	return nil, nil
+}
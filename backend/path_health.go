@@ -77,10 +77,66 @@ func (b *skyflowBackend) pathHealthRead(ctx context.Context, req *logical.Reques
 		response["credentials_type"] = "json"
 	}
 
+	if next := config.nextRotationAt(); !next.IsZero() {
+		response["next_rotation_at"] = next.Format(time.RFC3339)
+	}
+
+	// An open breaker does not make the mount unhealthy, but token requests are failing fast.
+	// The top-level state is the mount config's breaker; roles on a credential set have their own.
+	breakerSettings := config.circuitBreakerSettings()
+	breakers := b.breakers.statuses()
+	if _, ok := breakers[""]; !ok {
+		breakers[""] = circuitBreakerStatus{}
+	}
+	breakerFields := func(status circuitBreakerStatus) map[string]interface{} {
+		fields := map[string]interface{}{
+			"state":                circuitStateName(status.State),
+			"consecutive_failures": status.ConsecutiveFailures,
+		}
+		if status.State == circuitOpen {
+			fields["opened_at"] = status.OpenedAt.Format(time.RFC3339)
+			fields["retry_at"] = status.OpenedAt.Add(breakerSettings.cooldown).Format(time.RFC3339)
+		}
+		return fields
+	}
+	breakerStatus := breakerFields(breakers[""])
+	breakerStatus["enabled"] = breakerSettings.threshold > 0
+	setBreakers := make(map[string]interface{})
+	for name, status := range breakers {
+		if name != "" {
+			setBreakers[name] = breakerFields(status)
+		}
+	}
+	breakerStatus["credential_sets"] = setBreakers
+	response["circuit_breaker"] = breakerStatus
+
+	// Background refreshes of prewarmed roles on this node; a failing refresh leaves creds
+	// reads for the role paying the Skyflow round-trip again
+	prewarmStatus := make(map[string]interface{})
+	for name, status := range b.prewarm.snapshot() {
+		roleStatus := map[string]interface{}{
+			"consecutive_failures": status.ConsecutiveFailures,
+		}
+		if !status.LastRefreshAt.IsZero() {
+			roleStatus["last_refresh_at"] = status.LastRefreshAt.Format(time.RFC3339)
+			roleStatus["expires_at"] = status.ExpiresAt.Format(time.RFC3339)
+		}
+		if status.ConsecutiveFailures > 0 {
+			roleStatus["last_error"] = status.LastError
+			roleStatus["last_error_at"] = status.LastErrorAt.Format(time.RFC3339)
+		}
+		prewarmStatus[name] = roleStatus
+	}
+	response["prewarm"] = prewarmStatus
+
 	traces.RecordHealthCheckSuccess(span)
 
 	if m := b.metrics(); m != nil {
 		m.RecordHealthCheck(ctx, "healthy")
+		_, skyflowVaultName := requestLabels(req)
+		for name, status := range breakers {
+			m.RecordCircuitBreakerState(ctx, skyflowVaultName, name, status.State)
+		}
 	}
 
 	return &logical.Response{Data: response}, nil
@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sundhar-perumal/vault-plugin-secrets-skyflow/backend/telemetry"
+)
+
+// prometheusUnauthenticatedEnv lets Prometheus scrape the metrics path without a Vault token
+const prometheusUnauthenticatedEnv = "TELEMETRY_PROMETHEUS_UNAUTHENTICATED"
+
+// pathMetrics returns the path configuration for Prometheus scrapes
+func pathMetrics(b *skyflowBackend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "metrics$",
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathMetricsRead,
+					Summary:  "Prometheus scrape endpoint for this mount's metrics.",
+				},
+			},
+
+			HelpSynopsis:    "Prometheus scrape endpoint.",
+			HelpDescription: "Returns this mount's token, cache and health metrics in the Prometheus text format. Requires TELEMETRY_PROMETHEUS_ENABLED=true or config/telemetry prometheus_enabled=true.",
+		},
+	}
+}
+
+// metricsUnauthenticated returns whether the metrics path is served without a Vault token
+func metricsUnauthenticated() bool {
+	val := os.Getenv(prometheusUnauthenticatedEnv)
+	return strings.ToLower(val) == "true" || val == "1"
+}
+
+// pathMetricsRead renders the mount's metrics for a Prometheus scrape
+func (b *skyflowBackend) pathMetricsRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.telemetryLock.RLock()
+	providers := b.telemetryProviders
+	b.telemetryLock.RUnlock()
+
+	if !providers.PrometheusEnabled() {
+		return logical.ErrorResponse("prometheus metrics are not enabled for this mount; set TELEMETRY_PROMETHEUS_ENABLED=true or config/telemetry prometheus_enabled=true"), nil
+	}
+
+	body, err := providers.PrometheusMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: telemetry.PrometheusContentType,
+			logical.HTTPRawBody:     body,
+			logical.HTTPStatusCode:  http.StatusOK,
+		},
+	}, nil
+}
@@ -0,0 +1,129 @@
+package backend
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sundhar-perumal/vault-plugin-secrets-skyflow/backend/telemetry"
+)
+
+func TestPathMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	// No OTLP collector for the environment
+	t.Setenv("ENV", "local")
+	t.Setenv("RUNTIME_LOCAL", "")
+	t.Setenv("TELEMETRY_ENABLED", "true")
+	t.Setenv("TELEMETRY_METRICS_ENABLED", "")
+
+	newMount := func(t *testing.T, uuid string) (*skyflowBackend, logical.Storage) {
+		t.Helper()
+		storage := &logical.InmemStorage{}
+		lb, err := Factory(ctx, &logical.BackendConfig{
+			Logger:      nil,
+			System:      &logical.StaticSystemView{},
+			StorageView: storage,
+			BackendUUID: uuid,
+		})
+		if err != nil {
+			t.Fatalf("unable to create backend: %v", err)
+		}
+		b := lb.(*skyflowBackend)
+		t.Cleanup(func() { b.Cleanup(ctx) })
+		return b, storage
+	}
+
+	readMetrics := func(b *skyflowBackend, storage logical.Storage) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "metrics",
+			Storage:   storage,
+		})
+	}
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		t.Setenv("TELEMETRY_PROMETHEUS_ENABLED", "")
+		b, storage := newMount(t, "order-mount")
+
+		resp, err := readMetrics(b, storage)
+		if err != nil || resp == nil || !resp.IsError() {
+			t.Fatalf("expected an error response, got err=%v resp=%v", err, resp)
+		}
+	})
+
+	t.Run("Serves the mount's metrics", func(t *testing.T) {
+		t.Setenv("TELEMETRY_PROMETHEUS_ENABLED", "true")
+		order, orderStorage := newMount(t, "order-mount")
+		payment, paymentStorage := newMount(t, "payment-mount")
+
+		// Health checks record skyflow_health_checks_total
+		for _, m := range []struct {
+			b       *skyflowBackend
+			storage logical.Storage
+		}{{order, orderStorage}, {payment, paymentStorage}} {
+			if _, err := m.b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.ReadOperation,
+				Path:      "health",
+				Storage:   m.storage,
+			}); err != nil {
+				t.Fatalf("health read failed: %v", err)
+			}
+		}
+
+		resp, err := readMetrics(order, orderStorage)
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("failed to read metrics: err=%v resp=%v", err, resp)
+		}
+
+		if resp.Data[logical.HTTPStatusCode] != http.StatusOK {
+			t.Errorf("unexpected status: %v", resp.Data[logical.HTTPStatusCode])
+		}
+		if resp.Data[logical.HTTPContentType] != telemetry.PrometheusContentType {
+			t.Errorf("unexpected content type: %v", resp.Data[logical.HTTPContentType])
+		}
+
+		body := string(resp.Data[logical.HTTPRawBody].([]byte))
+		if !strings.Contains(body, "skyflow_health_checks_total") || !strings.Contains(body, `otel_scope_vault_mount_uuid="order-mount"`) {
+			t.Errorf("expected the mount's health check metrics, got:\n%s", body)
+		}
+		if strings.Contains(body, "payment-mount") {
+			t.Errorf("expected no series of another mount, got:\n%s", body)
+		}
+	})
+
+	t.Run("config/telemetry can turn it on", func(t *testing.T) {
+		t.Setenv("TELEMETRY_PROMETHEUS_ENABLED", "")
+		b, storage := newMount(t, "purchase-mount")
+
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "config/telemetry",
+			Storage:   storage,
+			Data:      map[string]interface{}{"prometheus_enabled": true},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write config/telemetry: err=%v resp=%v", err, resp)
+		}
+
+		resp, err = readMetrics(b, storage)
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("failed to read metrics: err=%v resp=%v", err, resp)
+		}
+	})
+
+	t.Run("Unauthenticated access is opt-in", func(t *testing.T) {
+		t.Setenv(prometheusUnauthenticatedEnv, "")
+		if paths := unauthenticatedPaths(); len(paths) != 0 {
+			t.Errorf("expected no unauthenticated paths, got %v", paths)
+		}
+
+		t.Setenv(prometheusUnauthenticatedEnv, "true")
+		b, _ := newMount(t, "scrape-mount")
+		if paths := b.SpecialPaths().Unauthenticated; len(paths) != 1 || paths[0] != "metrics" {
+			t.Errorf("expected metrics to be unauthenticated, got %v", paths)
+		}
+	})
+}
@@ -35,9 +35,61 @@ func pathRoles(b *skyflowBackend) []*framework.Path {
 				},
 				"role_ids": {
 					Type:        framework.TypeCommaStringSlice,
-					Description: "Skyflow role IDs for token generation (required)",
+					Description: "Skyflow role IDs for token generation (required, limited by the mount's max_role_ids and allowed_role_ids)",
 					Required:    true,
 				},
+				"credential_set": {
+					Type:        framework.TypeString,
+					Description: "Name of the credential set used to mint tokens (default: mount config credentials)",
+				},
+				"ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Lease TTL for issued tokens, capped at the token's own expiry (default: token expiry)",
+				},
+				"max_ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Maximum lifetime of a token lease including renewals (default: token expiry)",
+				},
+				"allowed_ctx_pattern": {
+					Type:        framework.TypeString,
+					Description: "Pattern the caller's ctx must match: a glob, or a regular expression when prefixed with ^ (default: any ctx)",
+				},
+				"ctx_required": {
+					Type:        framework.TypeBool,
+					Description: "Reject token requests that do not supply ctx (default: false)",
+				},
+				"ctx_template": {
+					Type:        framework.TypeString,
+					Description: "Identity template rendered into ctx from the requesting entity, e.g. {{identity.entity.metadata.tenant_id}}; callers cannot override it",
+				},
+				"allow_signing": {
+					Type:        framework.TypeBool,
+					Description: "Allow sign/<role> to return signed data tokens for this role (default: false)",
+				},
+				"serve_stale_on_error": {
+					Type:        framework.TypeBool,
+					Description: "When a token cannot be minted, serve the last issued token for the same ctx if it is still valid long enough (default: false)",
+				},
+				"stale_min_remaining_ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Minimum remaining lifetime of a token served stale (default: 30s)",
+				},
+				"rate_limit": {
+					Type:        framework.TypeFloat,
+					Description: "Maximum creds reads per second for this role; 0 disables rate limiting (default: 0)",
+				},
+				"rate_limit_burst": {
+					Type:        framework.TypeInt,
+					Description: "Creds reads allowed in a burst (default: rate_limit rounded up)",
+				},
+				"rate_limit_per": {
+					Type:        framework.TypeString,
+					Description: "What rate_limit applies to: role (shared by all callers), application_source (per Application-Source header) or entity (per Vault entity) (default: role)",
+				},
+				"prewarm": {
+					Type:        framework.TypeBool,
+					Description: "Keep a fresh token (without ctx) cached for this role, refreshed in the background ahead of expiry (default: false)",
+				},
 				"description": {
 					Type:        framework.TypeString,
 					Description: "Description of the role",
@@ -136,6 +188,58 @@ func (b *skyflowBackend) pathRoleWrite(ctx context.Context, req *logical.Request
 		role.RoleIDs = roleIDs.([]string)
 	}
 
+	if credentialSet, ok := data.GetOk("credential_set"); ok {
+		role.CredentialSet = credentialSet.(string)
+	}
+
+	if ttl, ok := data.GetOk("ttl"); ok {
+		role.TTL = time.Duration(ttl.(int)) * time.Second
+	}
+
+	if maxTTL, ok := data.GetOk("max_ttl"); ok {
+		role.MaxTTL = time.Duration(maxTTL.(int)) * time.Second
+	}
+
+	if pattern, ok := data.GetOk("allowed_ctx_pattern"); ok {
+		role.AllowedCtxPattern = pattern.(string)
+	}
+
+	if ctxRequired, ok := data.GetOk("ctx_required"); ok {
+		role.CtxRequired = ctxRequired.(bool)
+	}
+
+	if ctxTemplate, ok := data.GetOk("ctx_template"); ok {
+		role.CtxTemplate = ctxTemplate.(string)
+	}
+
+	if allowSigning, ok := data.GetOk("allow_signing"); ok {
+		role.AllowSigning = allowSigning.(bool)
+	}
+
+	if serveStale, ok := data.GetOk("serve_stale_on_error"); ok {
+		role.ServeStaleOnError = serveStale.(bool)
+	}
+
+	if minRemaining, ok := data.GetOk("stale_min_remaining_ttl"); ok {
+		role.StaleMinRemainingTTL = time.Duration(minRemaining.(int)) * time.Second
+	}
+
+	if rateLimit, ok := data.GetOk("rate_limit"); ok {
+		role.RateLimit = rateLimit.(float64)
+	}
+
+	if burst, ok := data.GetOk("rate_limit_burst"); ok {
+		role.RateLimitBurst = burst.(int)
+	}
+
+	if per, ok := data.GetOk("rate_limit_per"); ok {
+		role.RateLimitPer = per.(string)
+	}
+
+	if prewarm, ok := data.GetOk("prewarm"); ok {
+		role.Prewarm = prewarm.(bool)
+	}
+
 	if desc, ok := data.GetOk("description"); ok {
 		role.Description = desc.(string)
 	}
@@ -150,12 +254,42 @@ func (b *skyflowBackend) pathRoleWrite(ctx context.Context, req *logical.Request
 		return logical.ErrorResponse("invalid role: %s", err.Error()), nil
 	}
 
+	// Referenced credential set must exist
+	if role.CredentialSet != "" {
+		set, err := b.getCredentialSet(ctx, req.Storage, role.CredentialSet)
+		if err != nil {
+			traces.RecordRoleError(span, err)
+			return nil, err
+		}
+		if set == nil {
+			traces.RecordRoleErrorWithMessage(span, "credential set not found")
+			return logical.ErrorResponse("invalid role: credential set %q not found", role.CredentialSet), nil
+		}
+	}
+
+	// Enforce the mount's role ID policy when the mount is configured
+	config, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		traces.RecordRoleError(span, err)
+		return nil, err
+	}
+	if config != nil {
+		if err := role.validateRoleIDPolicy(config); err != nil {
+			traces.RecordRoleErrorWithMessage(span, err.Error())
+			return logical.ErrorResponse("invalid role: %s", err.Error()), nil
+		}
+	}
+
 	// Save role
 	if err := b.saveRole(ctx, req.Storage, role); err != nil {
 		traces.RecordRoleError(span, err)
 		return nil, err
 	}
 
+	// Drop tokens minted with the previous role definition, and its rate limit buckets
+	b.tokenCache.purgeRole(name)
+	b.rateLimiter.purgeRole(name)
+
 	// Record metrics
 	if m := b.metrics(); m != nil {
 		m.RecordRoleWrite(ctx, name, operation)
@@ -195,12 +329,25 @@ func (b *skyflowBackend) pathRoleRead(ctx context.Context, req *logical.Request,
 	traces.RecordRoleFound(span, true)
 
 	responseData := map[string]interface{}{
-		"name":        role.Name,
-		"role_ids":    role.RoleIDs,
-		"description": role.Description,
-		"tags":        role.Tags,
-		"created_at":  role.CreatedAt.Format(time.RFC3339),
-		"updated_at":  role.UpdatedAt.Format(time.RFC3339),
+		"name":                    role.Name,
+		"role_ids":                role.RoleIDs,
+		"credential_set":          role.CredentialSet,
+		"ttl":                     int64(role.TTL.Seconds()),
+		"max_ttl":                 int64(role.MaxTTL.Seconds()),
+		"allowed_ctx_pattern":     role.AllowedCtxPattern,
+		"ctx_required":            role.CtxRequired,
+		"ctx_template":            role.CtxTemplate,
+		"allow_signing":           role.AllowSigning,
+		"serve_stale_on_error":    role.ServeStaleOnError,
+		"stale_min_remaining_ttl": int64(role.staleMinRemainingTTL().Seconds()),
+		"rate_limit":              role.RateLimit,
+		"rate_limit_burst":        role.rateLimitBurst(),
+		"rate_limit_per":          role.rateLimitPer(),
+		"prewarm":                 role.Prewarm,
+		"description":             role.Description,
+		"tags":                    role.Tags,
+		"created_at":              role.CreatedAt.Format(time.RFC3339),
+		"updated_at":              role.UpdatedAt.Format(time.RFC3339),
 	}
 
 	return &logical.Response{
@@ -221,6 +368,9 @@ func (b *skyflowBackend) pathRoleDelete(ctx context.Context, req *logical.Reques
 		return nil, err
 	}
 
+	b.tokenCache.purgeRole(name)
+	b.rateLimiter.purgeRole(name)
+
 	traces.RecordRoleDeleted(span)
 	b.Logger().Info("role deleted", "name", name)
 
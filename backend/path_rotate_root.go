@@ -0,0 +1,104 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathRotateRoot returns the path configuration for rotating the mount's service account key
+func pathRotateRoot(b *skyflowBackend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "config/rotate-root",
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathRotateRootUpdate,
+					Summary:  "Rotate the mount's Skyflow service account key.",
+				},
+			},
+
+			HelpSynopsis:    "Rotate the Skyflow service account key held in config.",
+			HelpDescription: "Create a new key for the configured service account through the Skyflow management API, validate it, store it, and revoke the previous key.",
+		},
+	}
+}
+
+// pathRotateRootUpdate rotates the service account key in the mount config
+func (b *skyflowBackend) pathRotateRootUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	traces := b.traces()
+	ctx, span := traces.StartConfigRotateRoot(ctx, rotationTriggerManual)
+	defer span.End()
+
+	m := b.metrics()
+	recordRotation := func(status string) {
+		if m != nil {
+			m.RecordRootRotation(ctx, rotationTriggerManual, status)
+		}
+	}
+
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
+	config, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		traces.RecordConfigError(span, err)
+		recordRotation("error")
+		return nil, err
+	}
+
+	if config == nil {
+		traces.RecordConfigErrorWithMessage(span, "backend not configured")
+		recordRotation("not_configured")
+		return logical.ErrorResponse("backend not configured"), nil
+	}
+
+	if config.CredentialsJSON == "" {
+		traces.RecordConfigErrorWithMessage(span, "credentials_json required")
+		recordRotation("unsupported")
+		return logical.ErrorResponse("rotate-root requires credentials_json; credentials read from a file must be rotated outside Vault"), nil
+	}
+
+	result, err := b.rotateRoot(ctx, req.Storage, config)
+	if result == nil {
+		traces.RecordConfigError(span, err)
+		recordRotation("failed")
+		return logical.ErrorResponse("root rotation failed: %s", err.Error()), nil
+	}
+
+	if result.ValidationErr != nil {
+		traces.RecordConfigError(span, result.ValidationErr)
+		recordRotation("rolled_back")
+		return logical.ErrorResponse("root rotation rolled back, new key failed validation: %s", result.ValidationErr.Error()), nil
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"version":         result.Version,
+			"key_id":          result.KeyID,
+			"previous_key_id": result.PreviousKeyID,
+		},
+	}
+
+	if err != nil {
+		// The new key is live; revoking the old key is retried from the WAL
+		b.Logger().Warn("failed to revoke previous service account key", "key_id", result.PreviousKeyID, "error", err)
+		resp.AddWarning(fmt.Sprintf("previous key %s could not be revoked and will be retried: %s", result.PreviousKeyID, err))
+		recordRotation("revoke_pending")
+	} else {
+		recordRotation("success")
+	}
+
+	traces.RecordConfigUpdated(span)
+
+	b.Logger().Info("service account key rotated",
+		"key_id", result.KeyID,
+		"previous_key_id", result.PreviousKeyID,
+		"version", result.Version,
+	)
+
+	return resp, nil
+}
@@ -0,0 +1,278 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
+	"github.com/sundhar-perumal/vault-plugin-secrets-skyflow/backend/telemetry"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// defaultSignedDataTokenTTL matches the Skyflow SDK default for signed data tokens
+	defaultSignedDataTokenTTL = 60 * time.Second
+
+	// maxSignDataTokens caps how many data tokens one sign request may carry
+	maxSignDataTokens = 100
+)
+
+// pathSign returns the path configuration for signing data tokens
+func pathSign(b *skyflowBackend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "sign/" + framework.GenericNameRegex("name"),
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the role",
+					Required:    true,
+				},
+				"data_tokens": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: fmt.Sprintf("Skyflow data tokens to sign (required, at most %d)", maxSignDataTokens),
+					Required:    true,
+				},
+				"ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Lifetime of the signed data tokens, capped at the role's max_ttl (default: 60s)",
+					Default:     int(defaultSignedDataTokenTTL.Seconds()),
+				},
+				"ctx": {
+					Type:        framework.TypeString,
+					Description: "Context data to include in the signed tokens (optional, not allowed when the role sets ctx_template)",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathSignWrite,
+					Summary:  "Sign Skyflow data tokens for scoped detokenization.",
+				},
+			},
+
+			HelpSynopsis:    "Generate signed Skyflow data tokens.",
+			HelpDescription: "Sign data tokens with the role's credentials so they can be detokenized individually for a short time. The role must set allow_signing.",
+		},
+	}
+}
+
+// pathSignWrite handles signed data token generation
+func (b *skyflowBackend) pathSignWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	start := time.Now()
+	roleName := data.Get("name").(string)
+	dataTokens := data.Get("data_tokens").([]string)
+	traces := b.traces()
+
+	// Extract trace context from traceparent header (W3C standard)
+	ctx = telemetry.ExtractTraceContext(ctx, req.Headers)
+
+	vaultServiceName, skyflowVaultName := requestLabels(req)
+
+	ctxData := ""
+	if val, ok := data.GetOk("ctx"); ok {
+		ctxData = val.(string)
+	}
+
+	ctx, span := traces.StartTokenSign(ctx, roleName, len(dataTokens))
+	defer span.End()
+
+	// Every failure is recorded like a failed creds/<role> read and audited
+	signFailed := func(errorCode string, err error) {
+		b.recordTokenFailure(ctx, span, start, roleName, vaultServiceName, skyflowVaultName, errorCode, err)
+		b.auditLog(auditEvent{
+			Timestamp: time.Now(),
+			Operation: "data_token_sign",
+			Role:      roleName,
+			Success:   false,
+			Duration:  time.Since(start).Milliseconds(),
+			ClientIP:  req.Connection.RemoteAddr,
+			TraceID:   trace.SpanContextFromContext(ctx).TraceID().String(),
+			Error:     err.Error(),
+			ErrorCode: errorCode,
+		})
+	}
+
+	if err := validateDataTokens(dataTokens); err != nil {
+		signFailed(tokenErrorInvalidRequest, err)
+		return tokenErrorResponse(tokenErrorInvalidRequest, err.Error()), nil
+	}
+
+	role, err := b.getRole(ctx, req.Storage, roleName)
+	if err != nil {
+		signFailed(tokenErrorStorage, err)
+		return nil, err
+	}
+
+	if role == nil {
+		signFailed(tokenErrorRoleNotFound, fmt.Errorf("role not found"))
+		return tokenErrorResponse(tokenErrorRoleNotFound, "role %q not found", roleName), nil
+	}
+
+	if !role.AllowSigning {
+		signFailed(tokenErrorSigningNotAllowed, fmt.Errorf("signing not allowed"))
+		return tokenErrorResponse(tokenErrorSigningNotAllowed, "role %q does not allow signing data tokens", roleName), nil
+	}
+
+	// Signed tokens carry ctx too, so the role's ctx policy applies exactly as for creds/<role>
+	ctxData, err = b.resolveCtx(role, ctxData, req.EntityID)
+	if err == nil {
+		err = role.validateCtx(ctxData)
+	}
+	if err != nil {
+		signFailed(tokenErrorCtxRejected, err)
+		return tokenErrorResponse(tokenErrorCtxRejected, err.Error()), nil
+	}
+
+	var warnings []string
+	ttl := time.Duration(data.Get("ttl").(int)) * time.Second
+	if ttl <= 0 {
+		signFailed(tokenErrorInvalidRequest, fmt.Errorf("invalid ttl"))
+		return tokenErrorResponse(tokenErrorInvalidRequest, "ttl must be positive"), nil
+	}
+	if role.MaxTTL > 0 && ttl > role.MaxTTL {
+		warnings = append(warnings, fmt.Sprintf("ttl of %s exceeds the role's max_ttl; capped at %s", ttl, role.MaxTTL))
+		ttl = role.MaxTTL
+	}
+
+	config, err := b.tokenConfig(ctx, req.Storage, role)
+	if err != nil {
+		signFailed(tokenErrorStorage, err)
+		return nil, err
+	}
+
+	if config == nil {
+		if role.CredentialSet != "" {
+			signFailed(tokenErrorCredentialSetNotFound, fmt.Errorf("credential set not found"))
+			return tokenErrorResponse(tokenErrorCredentialSetNotFound, "credential set %q not found", role.CredentialSet), nil
+		}
+		signFailed(tokenErrorNotConfigured, fmt.Errorf("backend not configured"))
+		return tokenErrorResponse(tokenErrorNotConfigured, "backend not configured"), nil
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	signed, signErr := b.signDataTokens(ctx, config, role, dataTokens, ttl, ctxData)
+	duration := time.Since(start)
+	traceID := trace.SpanContextFromContext(ctx).TraceID().String()
+
+	if m := b.metrics(); m != nil {
+		m.RecordDataTokenSign(ctx, roleName, vaultServiceName, skyflowVaultName, signErr == nil)
+	}
+
+	if signErr != nil {
+		errorCode := classifyTokenError(signErr)
+		signFailed(errorCode, signErr)
+
+		// Fail fast with 503, as creds/<role> does, while the breaker is open
+		if errors.Is(signErr, errCircuitOpen) {
+			return tokenErrorStatusResponse(http.StatusServiceUnavailable, errorCode, "failed to sign data tokens: %v", signErr)
+		}
+
+		return tokenErrorResponse(errorCode, "failed to sign data tokens: %v", signErr), nil
+	}
+
+	traces.RecordTokenSigned(span, float64(duration.Milliseconds()))
+
+	b.auditLog(auditEvent{
+		Timestamp: time.Now(),
+		Operation: "data_token_sign",
+		Role:      roleName,
+		Success:   true,
+		Duration:  duration.Milliseconds(),
+		ClientIP:  req.Connection.RemoteAddr,
+		TraceID:   traceID,
+	})
+
+	b.Logger().Info("data tokens signed", "role", roleName, "count", len(signed), "trace_id", traceID)
+
+	signedTokens := make([]map[string]interface{}, 0, len(signed))
+	for _, s := range signed {
+		signedTokens = append(signedTokens, map[string]interface{}{
+			"token":        s.Token,
+			"signed_token": s.SignedToken,
+		})
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"signed_data_tokens": signedTokens,
+			"expires_at":         expiresAt.UTC().Format(time.RFC3339),
+			"ttl_seconds":        int64(ttl.Seconds()),
+		},
+		Warnings: warnings,
+	}, nil
+}
+
+// validateDataTokens checks the data tokens in a sign request
+func validateDataTokens(dataTokens []string) error {
+	if len(dataTokens) == 0 {
+		return fmt.Errorf("data_tokens is required")
+	}
+
+	if len(dataTokens) > maxSignDataTokens {
+		return fmt.Errorf("at most %d data_tokens can be signed per request, got %d", maxSignDataTokens, len(dataTokens))
+	}
+
+	for _, token := range dataTokens {
+		if token == "" {
+			return fmt.Errorf("data_tokens cannot contain empty values")
+		}
+	}
+
+	return nil
+}
+
+// signDataTokens signs data tokens with the config's issuer, behind the same circuit breaker,
+// request_timeout and retries as generateToken
+func (b *skyflowBackend) signDataTokens(ctx context.Context, config *skyflowConfig, role *skyflowRole, dataTokens []string, ttl time.Duration, ctxData string) ([]common.SignedDataTokensResponse, error) {
+	if config.CredentialsFilePath == "" && config.CredentialsJSON == "" {
+		return nil, errNoCredentials
+	}
+
+	issuer, err := b.issuerFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := issuer.(DataTokenSigner)
+	if !ok {
+		return nil, errSigningUnsupported
+	}
+
+	// Each credential set has its own breaker, so one failing service account does not fail
+	// roles on the others
+	breaker := b.breakers.get(role.CredentialSet)
+	breakerSettings := config.circuitBreakerSettings()
+	if err := breaker.allow(breakerSettings, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var signed []common.SignedDataTokensResponse
+	err = b.withRetry(ctx, config, role, func(ctx context.Context) error {
+		var err error
+		signed, err = signer.SignDataTokens(ctx, &SignRequest{
+			CredentialsJSON:     config.CredentialsJSON,
+			CredentialsFilePath: config.CredentialsFilePath,
+			DataTokens:          dataTokens,
+			TTL:                 ttl,
+			Ctx:                 ctxData,
+		})
+		return err
+	})
+	breaker.record(breakerSettings, err != nil && tripsCircuitBreaker(err), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signed) != len(dataTokens) {
+		return nil, fmt.Errorf("signing returned %d tokens for %d data tokens", len(signed), len(dataTokens))
+	}
+
+	return signed, nil
+}
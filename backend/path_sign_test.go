@@ -0,0 +1,247 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// signedTokenClaims decodes the claims of a signed data token returned by sign/<role>
+func signedTokenClaims(t *testing.T, signed string) map[string]interface{} {
+	t.Helper()
+
+	parts := strings.Split(strings.TrimPrefix(signed, "signed_token_"), ".")
+	if len(parts) != 3 {
+		t.Fatalf("signed token is not a JWT: %s", signed)
+	}
+	claims := map[string]interface{}{}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		t.Fatalf("failed to decode claims: %v", err)
+	}
+	return claims
+}
+
+func TestPathSign(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	config := &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"credentials_json":     testServiceAccountJSON(t, "sa-payment", "key-1", "https://example.invalid/token", generateTestKey(t)),
+			"validate_credentials": false,
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+	}
+
+	writeRole := func(t *testing.T, name string, data map[string]interface{}) {
+		t.Helper()
+		data["role_ids"] = "skyflow-role-read"
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "roles/" + name,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write role: err=%v resp=%v", err, resp)
+		}
+	}
+
+	sign := func(t *testing.T, name string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.UpdateOperation,
+			Path:       "sign/" + name,
+			Storage:    storage,
+			Data:       data,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil {
+			t.Fatal("expected a response")
+		}
+		return resp
+	}
+
+	writeRole(t, "creds-only", map[string]interface{}{})
+	writeRole(t, "detokenizer", map[string]interface{}{
+		"allow_signing":       true,
+		"max_ttl":             "5m",
+		"allowed_ctx_pattern": "txn:*",
+	})
+
+	t.Run("Role without allow_signing is rejected", func(t *testing.T) {
+		resp := sign(t, "creds-only", map[string]interface{}{"data_tokens": "tok-1"})
+		if !resp.IsError() || !strings.Contains(resp.Error().Error(), "does not allow signing") {
+			t.Errorf("expected signing not allowed error, got: %v", resp.Error())
+		}
+	})
+
+	t.Run("Missing data tokens are rejected", func(t *testing.T) {
+		resp := sign(t, "detokenizer", map[string]interface{}{})
+		if !resp.IsError() {
+			t.Error("expected error response for missing data_tokens")
+		}
+	})
+
+	t.Run("ctx policy applies", func(t *testing.T) {
+		resp := sign(t, "detokenizer", map[string]interface{}{"data_tokens": "tok-1", "ctx": "admin"})
+		if !resp.IsError() || !strings.Contains(resp.Error().Error(), "allowed_ctx_pattern") {
+			t.Errorf("expected ctx pattern error, got: %v", resp.Error())
+		}
+	})
+
+	t.Run("Data tokens are signed", func(t *testing.T) {
+		resp := sign(t, "detokenizer", map[string]interface{}{
+			"data_tokens": "tok-1,tok-2",
+			"ttl":         "2m",
+			"ctx":         "txn:PAY-1",
+		})
+		if resp.IsError() {
+			t.Fatalf("unexpected error response: %v", resp.Error())
+		}
+
+		signed, ok := resp.Data["signed_data_tokens"].([]map[string]interface{})
+		if !ok || len(signed) != 2 {
+			t.Fatalf("expected 2 signed data tokens, got %v", resp.Data["signed_data_tokens"])
+		}
+		if signed[0]["token"] != "tok-1" {
+			t.Errorf("expected signed tokens in request order, got %v", signed[0]["token"])
+		}
+
+		claims := signedTokenClaims(t, signed[0]["signed_token"].(string))
+		if claims["tok"] != "tok-1" || claims["ctx"] != "txn:PAY-1" {
+			t.Errorf("unexpected claims: %v", claims)
+		}
+		if resp.Data["ttl_seconds"] != int64(120) {
+			t.Errorf("expected ttl_seconds 120, got %v", resp.Data["ttl_seconds"])
+		}
+	})
+
+	t.Run("ttl is capped at the role's max_ttl", func(t *testing.T) {
+		resp := sign(t, "detokenizer", map[string]interface{}{
+			"data_tokens": "tok-1",
+			"ttl":         "1h",
+			"ctx":         "txn:PAY-1",
+		})
+		if resp.IsError() {
+			t.Fatalf("unexpected error response: %v", resp.Error())
+		}
+		if resp.Data["ttl_seconds"] != int64(300) {
+			t.Errorf("expected ttl_seconds 300, got %v", resp.Data["ttl_seconds"])
+		}
+		if len(resp.Warnings) == 0 {
+			t.Error("expected a warning about the capped ttl")
+		}
+	})
+}
+
+func TestPathSign_TokenIssuer(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, b logical.Backend, storage logical.Storage, config map[string]interface{}) {
+		t.Helper()
+		writes := []struct {
+			path string
+			data map[string]interface{}
+		}{
+			{path: "config", data: config},
+			{path: "roles/detokenizer", data: map[string]interface{}{"role_ids": "skyflow-role-read", "allow_signing": true}},
+		}
+		for _, w := range writes {
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      w.path,
+				Storage:   storage,
+				Data:      w.data,
+			})
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("failed to write %s: err=%v resp=%v", w.path, err, resp)
+			}
+		}
+	}
+
+	sign := func(t *testing.T, b logical.Backend, storage logical.Storage) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.UpdateOperation,
+			Path:       "sign/detokenizer",
+			Storage:    storage,
+			Data:       map[string]interface{}{"data_tokens": "tok-1", "ctx": "txn:PAY-1"},
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil || resp == nil {
+			t.Fatalf("sign failed: err=%v resp=%v", err, resp)
+		}
+		return resp
+	}
+
+	t.Run("Native mounts sign without the SDK", func(t *testing.T) {
+		storage := &logical.InmemStorage{}
+		b, err := Factory(ctx, &logical.BackendConfig{
+			Logger:      nil,
+			System:      &logical.StaticSystemView{},
+			StorageView: storage,
+		})
+		if err != nil {
+			t.Fatalf("unable to create backend: %v", err)
+		}
+		setup(t, b, storage, map[string]interface{}{
+			"credentials_json":     testServiceAccountJSON(t, "sa-payment", "key-1", "https://example.invalid/token", generateTestKey(t)),
+			"token_issuer":         tokenIssuerNative,
+			"validate_credentials": false,
+		})
+
+		resp := sign(t, b, storage)
+		if resp.IsError() {
+			t.Fatalf("unexpected error response: %v", resp.Error())
+		}
+
+		signed := resp.Data["signed_data_tokens"].([]map[string]interface{})
+		claims := signedTokenClaims(t, signed[0]["signed_token"].(string))
+		if claims["tok"] != "tok-1" || claims["ctx"] != "txn:PAY-1" || claims["sub"] != "sa-payment" || claims["key"] != "key-1" {
+			t.Errorf("unexpected claims: %v", claims)
+		}
+	})
+
+	t.Run("Issuers that cannot sign are rejected", func(t *testing.T) {
+		storage := &logical.InmemStorage{}
+		b, err := FactoryWithOptions(WithTokenIssuer(&recordingTokenIssuer{}))(ctx, &logical.BackendConfig{
+			Logger:      nil,
+			System:      &logical.StaticSystemView{},
+			StorageView: storage,
+		})
+		if err != nil {
+			t.Fatalf("unable to create backend: %v", err)
+		}
+		setup(t, b, storage, map[string]interface{}{
+			"credentials_json":     `{"test": "creds"}`,
+			"validate_credentials": false,
+		})
+
+		resp := sign(t, b, storage)
+		if !resp.IsError() || !strings.Contains(resp.Error().Error(), "does not support signing") {
+			t.Errorf("expected signing unsupported error, got: %v", resp.Error())
+		}
+	})
+}
@@ -2,17 +2,18 @@ package backend
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
-	"github.com/skyflowapi/skyflow-go/v2/serviceaccount"
 	"github.com/skyflowapi/skyflow-go/v2/utils/common"
-	skyflowError "github.com/skyflowapi/skyflow-go/v2/utils/error"
-	"github.com/skyflowapi/skyflow-go/v2/utils/logger"
 	"github.com/sundhar-perumal/vault-plugin-secrets-skyflow/backend/telemetry"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -31,7 +32,7 @@ func pathToken(b *skyflowBackend) []*framework.Path {
 				},
 				"ctx": {
 					Type:        framework.TypeString,
-					Description: "Context data to include in the token (optional)",
+					Description: "Context data to include in the token (optional, not allowed when the role sets ctx_template)",
 				},
 			},
 
@@ -62,7 +63,7 @@ func (b *skyflowBackend) pathTokenRead(ctx context.Context, req *logical.Request
 		"RUNTIME_LOCAL", os.Getenv("RUNTIME_LOCAL"),
 		"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"),
 		"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"),
-		"telemetry_providers_nil", b.telemetryProviders == nil,
+		"telemetry_providers_nil", traces == nil && b.metrics() == nil,
 		"traces_nil", traces == nil,
 		"traces_enabled", traces != nil && traces.IsEnabled(),
 	)
@@ -70,18 +71,7 @@ func (b *skyflowBackend) pathTokenRead(ctx context.Context, req *logical.Request
 	// Extract trace context from traceparent header (W3C standard)
 	ctx = telemetry.ExtractTraceContext(ctx, req.Headers)
 
-	// Extract skyflowVaultName from mount point (e.g., "skyflow/order/" -> "order")
-	skyflowVaultName := "unknown"
-	parts := strings.Split(strings.Trim(req.MountPoint, "/"), "/")
-	if len(parts) > 0 {
-		skyflowVaultName = parts[len(parts)-1]
-	}
-
-	// Extract vaultServiceName from header (sent by client)
-	vaultServiceName := "direct"
-	if vals, ok := req.Headers["Application-Source"]; ok && len(vals) > 0 && vals[0] != "" {
-		vaultServiceName = vals[0]
-	}
+	vaultServiceName, skyflowVaultName := requestLabels(req)
 
 	// Get optional context data
 	ctxData := ""
@@ -98,27 +88,98 @@ func (b *skyflowBackend) pathTokenRead(ctx context.Context, req *logical.Request
 	// Get role
 	role, err := b.getRole(ctx, req.Storage, roleName)
 	if err != nil {
-		traces.RecordTokenFailed(span, float64(time.Since(start).Milliseconds()), err)
+		b.recordTokenFailure(ctx, span, start, roleName, vaultServiceName, skyflowVaultName, tokenErrorStorage, err)
 		return nil, err
 	}
 
 	if role == nil {
-		traces.RecordTokenFailed(span, float64(time.Since(start).Milliseconds()), fmt.Errorf("role not found"))
-		return logical.ErrorResponse("role %q not found", roleName), nil
+		b.recordTokenFailure(ctx, span, start, roleName, vaultServiceName, skyflowVaultName, tokenErrorRoleNotFound, fmt.Errorf("role not found"))
+		return tokenErrorResponse(tokenErrorRoleNotFound, "role %q not found", roleName), nil
+	}
+
+	// Throttle the caller before any work is done on its behalf
+	if role.RateLimit > 0 {
+		key := rateLimitKey(roleName, rateLimitClient(role, req, vaultServiceName))
+		if ok, retryAfter := b.rateLimiter.allow(key, role.RateLimit, role.rateLimitBurst(), time.Now()); !ok {
+			retrySeconds := int(math.Ceil(retryAfter.Seconds()))
+			b.recordTokenFailure(ctx, span, start, roleName, vaultServiceName, skyflowVaultName, tokenErrorRateLimited, fmt.Errorf("rate limit exceeded"))
+			b.Logger().Debug("token request rate limited", "role", roleName, "retry_after_seconds", retrySeconds)
+
+			resp, err := tokenErrorStatusResponse(http.StatusTooManyRequests, tokenErrorRateLimited, "rate limit exceeded for role %q; retry after %ds", roleName, retrySeconds)
+			if err != nil {
+				return nil, err
+			}
+			resp.Headers = map[string][]string{"Retry-After": {strconv.Itoa(retrySeconds)}}
+			return resp, nil
+		}
 	}
 
-	// Get config
-	config, err := b.getConfig(ctx, req.Storage)
+	// Resolve and enforce the role's ctx policy before any cached token can be served
+	ctxData, err = b.resolveCtx(role, ctxData, req.EntityID)
+	if err == nil {
+		err = role.validateCtx(ctxData)
+	}
 	if err != nil {
-		traces.RecordTokenFailed(span, float64(time.Since(start).Milliseconds()), err)
+		b.recordTokenFailure(ctx, span, start, roleName, vaultServiceName, skyflowVaultName, tokenErrorCtxRejected, err)
+		return tokenErrorResponse(tokenErrorCtxRejected, err.Error()), nil
+	}
+
+	// Get config, resolving the role's credential set if it has one
+	config, err := b.tokenConfig(ctx, req.Storage, role)
+	if err != nil {
+		b.recordTokenFailure(ctx, span, start, roleName, vaultServiceName, skyflowVaultName, tokenErrorStorage, err)
 		return nil, err
 	}
 
 	if config == nil {
-		traces.RecordTokenFailed(span, float64(time.Since(start).Milliseconds()), fmt.Errorf("backend not configured"))
-		return logical.ErrorResponse("backend not configured"), nil
+		if role.CredentialSet != "" {
+			b.recordTokenFailure(ctx, span, start, roleName, vaultServiceName, skyflowVaultName, tokenErrorCredentialSetNotFound, fmt.Errorf("credential set not found"))
+			return tokenErrorResponse(tokenErrorCredentialSetNotFound, "credential set %q not found", role.CredentialSet), nil
+		}
+		b.recordTokenFailure(ctx, span, start, roleName, vaultServiceName, skyflowVaultName, tokenErrorNotConfigured, fmt.Errorf("backend not configured"))
+		return tokenErrorResponse(tokenErrorNotConfigured, "backend not configured"), nil
 	}
 
+	// Roles written before the mount's role ID policy was tightened are rejected here
+	if err := role.validateRoleIDPolicy(config); err != nil {
+		b.recordTokenFailure(ctx, span, start, roleName, vaultServiceName, skyflowVaultName, tokenErrorPolicyViolation, err)
+		return tokenErrorResponse(tokenErrorPolicyViolation, "role %q violates mount policy: %s", roleName, err.Error()), nil
+	}
+
+	// Serve a cached token while it is outside the refresh window
+	if cached, ok := b.tokenCache.get(roleName, ctxData, config.tokenCacheRefreshWindow()); ok {
+		duration := time.Since(start)
+
+		traces.RecordTokenCacheHit(span, true)
+		traces.RecordTokenGenerated(span, float64(duration.Milliseconds()))
+
+		if m := b.metrics(); m != nil {
+			m.RecordTokenCacheHit(ctx, roleName, vaultServiceName, skyflowVaultName)
+		}
+
+		traceID := trace.SpanContextFromContext(ctx).TraceID().String()
+		b.auditLog(auditEvent{
+			Timestamp: time.Now(),
+			Operation: "token_generate",
+			Role:      roleName,
+			Success:   true,
+			Duration:  duration.Milliseconds(),
+			ClientIP:  req.Connection.RemoteAddr,
+			TraceID:   traceID,
+			Cached:    true,
+		})
+
+		b.Logger().Debug("token served from cache", "role", roleName, "trace_id", traceID)
+
+		return b.tokenResponse(cached, role), nil
+	}
+
+	traces.RecordTokenCacheHit(span, false)
+	if m := b.metrics(); m != nil {
+		m.RecordTokenCacheMiss(ctx, roleName, vaultServiceName, skyflowVaultName)
+	}
+	cacheEpoch := b.tokenCache.currentEpoch()
+
 	// Determine credential type for telemetry
 	credentialType := "json"
 	if config.CredentialsFilePath != "" {
@@ -128,29 +189,78 @@ func (b *skyflowBackend) pathTokenRead(ctx context.Context, req *logical.Request
 	// Start inner span for Skyflow SDK authentication
 	ctx, sdkSpan := traces.StartSDKAuth(ctx, roleName, credentialType, len(role.RoleIDs))
 
-	// Generate token using config credentials and role's Skyflow role IDs
+	// Generate token using config credentials and role's Skyflow role IDs; concurrent requests
+	// for the same role+ctx+config share one Skyflow call
 	sdkCallStart := time.Now()
-	token, tokenErr := b.generateToken(config, role, ctxData)
+	flightKey := tokenFlightKey(roleName, ctxData, config.Version, cacheEpoch)
+	token, shared, tokenErr := b.tokenFlight.do(ctx, flightKey, func(ctx context.Context) (*common.TokenResponse, error) {
+		return b.generateToken(ctx, config, role, ctxData)
+	})
 	sdkCallDuration := time.Since(sdkCallStart)
 	duration := time.Since(start)
 
+	if shared {
+		traces.RecordSDKAuthShared(sdkSpan)
+	}
+
 	// End SDK auth span
+	errorCode := classifyTokenError(tokenErr)
 	if tokenErr != nil {
 		traces.RecordSDKAuthFailed(sdkSpan, float64(sdkCallDuration.Milliseconds()), tokenErr)
+		traces.RecordErrorType(sdkSpan, errorCode)
 	} else {
 		traces.RecordSDKAuthSuccess(sdkSpan, float64(sdkCallDuration.Milliseconds()))
 	}
 	sdkSpan.End()
 
+	breakerOpen := errors.Is(tokenErr, errCircuitOpen)
+	if m := b.metrics(); m != nil {
+		m.RecordCircuitBreakerState(ctx, skyflowVaultName, role.CredentialSet, b.breakers.get(role.CredentialSet).status().State)
+		// Only the request that made the Skyflow call counts its failure
+		if tokenErr != nil && !shared && !breakerOpen {
+			m.RecordSkyflowSDKCallError(ctx, roleName, errorCode)
+		}
+	}
+
+	// An unexpired token beats an outage for roles that opt in
+	if tokenErr != nil && role.ServeStaleOnError {
+		if stale, ok := b.tokenCache.getStale(roleName, ctxData, role.staleMinRemainingTTL()); ok {
+			traces.RecordTokenServedStale(span, float64(duration.Milliseconds()), tokenErr)
+
+			if m := b.metrics(); m != nil {
+				m.RecordTokenServedStale(ctx, roleName, vaultServiceName, skyflowVaultName)
+			}
+
+			traceID := trace.SpanContextFromContext(ctx).TraceID().String()
+			b.auditLog(auditEvent{
+				Timestamp: time.Now(),
+				Operation: "token_generate",
+				Role:      roleName,
+				Success:   true,
+				Duration:  duration.Milliseconds(),
+				ClientIP:  req.Connection.RemoteAddr,
+				TraceID:   traceID,
+				Cached:    true,
+				Stale:     true,
+				Error:     tokenErr.Error(),
+				ErrorCode: errorCode,
+			})
+
+			b.Logger().Warn("serving stale token", "role", roleName, "expires_at", stale.ExpiresAt, "error", tokenErr, "trace_id", traceID)
+
+			resp := b.tokenResponse(stale, role)
+			resp.Data["stale"] = true
+			resp.AddWarning(fmt.Sprintf("a new token could not be generated (%v); serving a previously issued token", tokenErr))
+			return resp, nil
+		}
+	}
+
 	if tokenErr != nil {
 		// Record telemetry failure
-		traces.RecordTokenFailed(span, float64(duration.Milliseconds()), tokenErr)
-
-		// Record metrics
 		if m := b.metrics(); m != nil {
 			m.RecordTokenGenerate(ctx, roleName, vaultServiceName, skyflowVaultName, float64(duration.Milliseconds()), false)
-			m.RecordTokenError(ctx, roleName, vaultServiceName, skyflowVaultName, "generation_failed")
 		}
+		b.recordTokenFailure(ctx, span, start, roleName, vaultServiceName, skyflowVaultName, errorCode, tokenErr)
 
 		// Audit log
 		traceID := trace.SpanContextFromContext(ctx).TraceID().String()
@@ -163,9 +273,23 @@ func (b *skyflowBackend) pathTokenRead(ctx context.Context, req *logical.Request
 			ClientIP:  req.Connection.RemoteAddr,
 			TraceID:   traceID,
 			Error:     tokenErr.Error(),
+			ErrorCode: errorCode,
 		})
 
-		return logical.ErrorResponse("failed to generate token: %v", tokenErr), nil
+		// Fail fast with 503 so callers can tell an open breaker from a rejected request
+		if breakerOpen {
+			return tokenErrorStatusResponse(http.StatusServiceUnavailable, errorCode, "failed to generate token: %v", tokenErr)
+		}
+
+		return tokenErrorResponse(errorCode, "failed to generate token: %v", tokenErr), nil
+	}
+
+	// Cache the token until it enters the refresh window; tokens without readable expiry are not cached
+	issued, claimsErr := newIssuedToken(token, role, config)
+	if claimsErr == nil {
+		b.tokenCache.put(cacheEpoch, roleName, ctxData, issued)
+	} else {
+		b.Logger().Warn("unable to read token expiry, token not cached", "role", roleName, "error", claimsErr)
 	}
 
 	// Record telemetry success
@@ -174,7 +298,9 @@ func (b *skyflowBackend) pathTokenRead(ctx context.Context, req *logical.Request
 	// Record metrics
 	if m := b.metrics(); m != nil {
 		m.RecordTokenGenerate(ctx, roleName, vaultServiceName, skyflowVaultName, float64(duration.Milliseconds()), true)
-		m.RecordSkyflowSDKCall(ctx, roleName, "success", float64(sdkCallDuration.Milliseconds()))
+		if !shared {
+			m.RecordSkyflowSDKCall(ctx, roleName, "success", float64(sdkCallDuration.Milliseconds()))
+		}
 	}
 
 	// Audit log
@@ -191,51 +317,118 @@ func (b *skyflowBackend) pathTokenRead(ctx context.Context, req *logical.Request
 
 	b.Logger().Info("token generated", "role", roleName, "trace_id", traceID, "duration_ms", duration.Milliseconds())
 
-	return &logical.Response{
-		Data: map[string]interface{}{
-			"access_token": token.AccessToken,
-			"token_type":   token.TokenType,
-		},
-	}, nil
+	return b.tokenResponse(issued, role), nil
 }
 
-// generateToken generates a Skyflow token using config credentials and role's Skyflow role IDs
-func (b *skyflowBackend) generateToken(config *skyflowConfig, role *skyflowRole, ctxData string) (token *common.TokenResponse, returnErr error) {
-	// Recover from SDK panics - defensive measure
-	defer func() {
-		if r := recover(); r != nil {
-			returnErr = fmt.Errorf("token generation panic: %v", r)
-		}
-	}()
+// requestLabels returns the calling service (from the Application-Source header) and the
+// Skyflow vault name (from the mount point, e.g. "skyflow/order/" -> "order") used to label metrics
+func requestLabels(req *logical.Request) (vaultServiceName, skyflowVaultName string) {
+	skyflowVaultName = "unknown"
+	parts := strings.Split(strings.Trim(req.MountPoint, "/"), "/")
+	if len(parts) > 0 {
+		skyflowVaultName = parts[len(parts)-1]
+	}
+
+	vaultServiceName = "direct"
+	if vals, ok := req.Headers["Application-Source"]; ok && len(vals) > 0 && vals[0] != "" {
+		vaultServiceName = vals[0]
+	}
 
-	var sdkErr *skyflowError.SkyflowError
+	return vaultServiceName, skyflowVaultName
+}
 
-	// Use role's Skyflow role IDs for scoped token generation
-	opts := common.BearerTokenOptions{
-		LogLevel: logger.DEBUG,
-		RoleIDs:  role.RoleIDs,
-		Ctx:      ctxData,
+// rateLimitClient returns the caller a role's rate limit bucket belongs to: the Application-Source
+// service, the Vault entity, or "" when the whole role shares one bucket
+func rateLimitClient(role *skyflowRole, req *logical.Request, vaultServiceName string) string {
+	switch role.rateLimitPer() {
+	case rateLimitPerApplicationSource:
+		return vaultServiceName
+	case rateLimitPerEntity:
+		return req.EntityID
+	default:
+		return ""
 	}
+}
 
-	// Use config credentials (file path or JSON)
-	if config.CredentialsFilePath != "" {
-		if _, statErr := os.Stat(config.CredentialsFilePath); os.IsNotExist(statErr) {
-			return nil, fmt.Errorf("credentials file not found: %s: %w", config.CredentialsFilePath, statErr)
-		}
-		token, sdkErr = serviceaccount.GenerateBearerToken(config.CredentialsFilePath, opts)
-	} else if config.CredentialsJSON != "" {
-		token, sdkErr = serviceaccount.GenerateBearerTokenFromCreds(config.CredentialsJSON, opts)
-	} else {
-		return nil, fmt.Errorf("no credentials configured")
+// generateToken generates a Skyflow token using config credentials and role's Skyflow role IDs
+func (b *skyflowBackend) generateToken(ctx context.Context, config *skyflowConfig, role *skyflowRole, ctxData string) (*common.TokenResponse, error) {
+	if config.CredentialsFilePath == "" && config.CredentialsJSON == "" {
+		return nil, errNoCredentials
 	}
 
-	if sdkErr != nil {
-		return nil, fmt.Errorf("failed to generate bearer token: %w", sdkErr)
+	issuer, err := b.issuerFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each credential set has its own breaker, so one failing service account does not fail
+	// roles on the others
+	breaker := b.breakers.get(role.CredentialSet)
+	breakerSettings := config.circuitBreakerSettings()
+	if err := breaker.allow(breakerSettings, time.Now()); err != nil {
+		return nil, err
+	}
+
+	token, err := b.issueWithRetry(ctx, issuer, config, role, &TokenRequest{
+		CredentialsJSON:     config.CredentialsJSON,
+		CredentialsFilePath: config.CredentialsFilePath,
+		RoleIDs:             role.RoleIDs,
+		Ctx:                 ctxData,
+	})
+	breaker.record(breakerSettings, err != nil && tripsCircuitBreaker(err), time.Now())
+	if err != nil {
+		return nil, err
 	}
 
 	if token == nil || token.AccessToken == "" {
-		return nil, fmt.Errorf("token generation returned empty token")
+		return nil, errEmptyToken
 	}
 
 	return token, nil
 }
+
+// issueWithRetry calls the issuer within the config's request_timeout, retrying transient failures
+func (b *skyflowBackend) issueWithRetry(ctx context.Context, issuer TokenIssuer, config *skyflowConfig, role *skyflowRole, tokenReq *TokenRequest) (*common.TokenResponse, error) {
+	var token *common.TokenResponse
+	err := b.withRetry(ctx, config, role, func(ctx context.Context) error {
+		var err error
+		token, err = issuer.IssueToken(ctx, tokenReq)
+		return err
+	})
+	return token, err
+}
+
+// withRetry runs call within the config's request_timeout, retrying transient failures
+func (b *skyflowBackend) withRetry(ctx context.Context, config *skyflowConfig, role *skyflowRole, call func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, config.requestTimeout())
+	defer cancel()
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = call(ctx)
+		if err == nil || attempt > config.maxRetries() || !isRetryableTokenError(err) {
+			break
+		}
+
+		delay := retryDelay(config.retryBackoff(), attempt)
+		b.traces().RecordSDKAuthRetry(trace.SpanFromContext(ctx), attempt, float64(delay.Milliseconds()), err)
+		if m := b.metrics(); m != nil {
+			m.RecordSkyflowSDKCallRetry(ctx, role.Name, attempt)
+		}
+		b.Logger().Debug("retrying token request", "role", role.Name, "attempt", attempt, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("token request timed out after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+	}
+
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("token request exceeded request_timeout of %s: %w", config.requestTimeout(), err)
+	}
+
+	return err
+}
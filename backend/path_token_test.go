@@ -2,9 +2,14 @@ package backend
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
 )
 
 func TestPathToken_GenerateToken_PanicRecovery(t *testing.T) {
@@ -64,7 +69,7 @@ func TestPathToken_GenerateToken_PanicRecovery(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// This should NOT panic, but return an error gracefully
-			token, err := backend.generateToken(tt.config, tt.role, "")
+			token, err := backend.generateToken(ctx, tt.config, tt.role, "")
 
 			if token != nil {
 				t.Error("expected nil token for invalid credentials")
@@ -103,7 +108,7 @@ func TestPathToken_GenerateToken_NoCredentials(t *testing.T) {
 		RoleIDs: []string{"test-role-id"},
 	}
 
-	token, err := backend.generateToken(cfg, role, "")
+	token, err := backend.generateToken(ctx, cfg, role, "")
 
 	if token != nil {
 		t.Error("expected nil token when no credentials configured")
@@ -145,7 +150,7 @@ func TestPathToken_GenerateToken_ConfigCredentials(t *testing.T) {
 	}
 
 	// This will fail because credentials are invalid, but proves config creds are used
-	token, err := backend.generateToken(cfg, role, "")
+	token, err := backend.generateToken(ctx, cfg, role, "")
 
 	if token != nil {
 		t.Error("expected nil token for invalid credentials")
@@ -157,3 +162,263 @@ func TestPathToken_GenerateToken_ConfigCredentials(t *testing.T) {
 
 	t.Logf("Got expected error: %v", err)
 }
+
+func TestPathToken_GenerateToken_Success(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	server := newFakeTokenServer(t)
+	server.useAsDefaultTransport(t)
+
+	config := &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	// Credentials are validated against the fake token server
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"credentials_json": server.credentialsJSON(t),
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "roles/payment-risk-engine",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"role_ids": "skyflow-role-read,skyflow-role-write",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write role: err=%v resp=%v", err, resp)
+	}
+
+	readCreds := func(t *testing.T) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.ReadOperation,
+			Path:       "creds/payment-risk-engine",
+			Storage:    storage,
+			Data:       map[string]interface{}{"ctx": "txn:PAY-8934"},
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("token request failed: err=%v resp=%v", err, resp)
+		}
+		return resp
+	}
+
+	t.Run("Token is minted with role IDs and ctx", func(t *testing.T) {
+		resp := readCreds(t)
+
+		if resp.Data["access_token"] == "" || resp.Data["token_type"] != "Bearer" {
+			t.Errorf("unexpected token response: %v", resp.Data)
+		}
+		if _, ok := resp.Data["expires_at"]; !ok {
+			t.Error("expected expires_at from the token claims")
+		}
+		if resp.Secret == nil || resp.Secret.TTL <= 0 {
+			t.Error("expected a lease on the token")
+		}
+
+		exchanges := server.accepted()
+		last := exchanges[len(exchanges)-1]
+		if last.Scope != "role:skyflow-role-read role:skyflow-role-write" {
+			t.Errorf("unexpected scope: %q", last.Scope)
+		}
+		if last.Ctx != "txn:PAY-8934" {
+			t.Errorf("unexpected ctx: %q", last.Ctx)
+		}
+	})
+
+	t.Run("Second read is served from cache", func(t *testing.T) {
+		before := len(server.accepted())
+		first := readCreds(t)
+		second := readCreds(t)
+
+		if first.Data["access_token"] != second.Data["access_token"] {
+			t.Error("expected the cached token")
+		}
+		if len(server.accepted()) != before {
+			t.Errorf("expected no new token exchanges, got %d", len(server.accepted())-before)
+		}
+	})
+}
+
+// recordingTokenIssuer is a TokenIssuer that records requests and returns a fixed result
+type recordingTokenIssuer struct {
+	requests []*TokenRequest
+	token    *common.TokenResponse
+	err      error
+}
+
+func (r *recordingTokenIssuer) IssueToken(ctx context.Context, req *TokenRequest) (*common.TokenResponse, error) {
+	r.requests = append(r.requests, req)
+	return r.token, r.err
+}
+
+func TestPathToken_TokenIssuerOption(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	issuer := &recordingTokenIssuer{err: fmt.Errorf("issuer unavailable")}
+
+	config := &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	}
+
+	b, err := FactoryWithOptions(WithTokenIssuer(issuer))(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	backend := b.(*skyflowBackend)
+
+	cfg := &skyflowConfig{CredentialsJSON: `{"test": "creds"}`}
+	role := &skyflowRole{
+		Name:    "test-role",
+		RoleIDs: []string{"test-role-id"},
+	}
+
+	t.Run("Issuer errors are returned", func(t *testing.T) {
+		_, err := backend.generateToken(ctx, cfg, role, "order:1")
+		if err == nil || !strings.Contains(err.Error(), "issuer unavailable") {
+			t.Errorf("expected issuer error, got %v", err)
+		}
+
+		req := issuer.requests[len(issuer.requests)-1]
+		if req.CredentialsJSON != cfg.CredentialsJSON || req.Ctx != "order:1" || len(req.RoleIDs) != 1 {
+			t.Errorf("unexpected token request: %+v", req)
+		}
+	})
+
+	t.Run("Empty tokens are rejected", func(t *testing.T) {
+		issuer.err = nil
+		issuer.token = &common.TokenResponse{}
+
+		if _, err := backend.generateToken(ctx, cfg, role, ""); err == nil {
+			t.Error("expected error for empty token")
+		}
+	})
+}
+
+func TestPathToken_ServeStaleOnError(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	issuer := &recordingTokenIssuer{err: &tokenExchangeError{StatusCode: http.StatusServiceUnavailable}}
+
+	b, err := FactoryWithOptions(WithTokenIssuer(issuer))(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	backend := b.(*skyflowBackend)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"credentials_json":     `{"test": "creds"}`,
+			"validate_credentials": false,
+			"max_retries":          0,
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+	}
+
+	writeRole := func(t *testing.T, data map[string]interface{}) {
+		t.Helper()
+		data["role_ids"] = "skyflow-role-read"
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "roles/payment-risk-engine",
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write role: err=%v resp=%v", err, resp)
+		}
+	}
+
+	// Role writes purge the cache, so the last issued token is seeded after each one
+	seedToken := func(remaining time.Duration) *issuedToken {
+		token := testIssuedToken(time.Now().Add(remaining))
+		token.Role = "payment-risk-engine"
+		backend.tokenCache.put(backend.tokenCache.currentEpoch(), "payment-risk-engine", "order:1", token)
+		return token
+	}
+
+	readCreds := func(t *testing.T) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.ReadOperation,
+			Path:       "creds/payment-risk-engine",
+			Storage:    storage,
+			Data:       map[string]interface{}{"ctx": "order:1"},
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil || resp == nil {
+			t.Fatalf("unexpected result: err=%v resp=%v", err, resp)
+		}
+		return resp
+	}
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		writeRole(t, map[string]interface{}{})
+		seedToken(45 * time.Second)
+
+		if resp := readCreds(t); !resp.IsError() {
+			t.Error("expected error without serve_stale_on_error")
+		}
+	})
+
+	t.Run("Last token is served stale", func(t *testing.T) {
+		writeRole(t, map[string]interface{}{"serve_stale_on_error": true})
+		token := seedToken(45 * time.Second)
+
+		resp := readCreds(t)
+		if resp.IsError() {
+			t.Fatalf("unexpected error response: %v", resp.Error())
+		}
+		if resp.Data["access_token"] != token.AccessToken {
+			t.Error("expected the last issued token")
+		}
+		if resp.Data["stale"] != true {
+			t.Error("expected stale: true")
+		}
+		if len(resp.Warnings) == 0 {
+			t.Error("expected a warning explaining the stale token")
+		}
+	})
+
+	t.Run("Token below the minimum remaining lifetime is not served", func(t *testing.T) {
+		writeRole(t, map[string]interface{}{"serve_stale_on_error": true, "stale_min_remaining_ttl": "50s"})
+		seedToken(45 * time.Second)
+
+		if resp := readCreds(t); !resp.IsError() {
+			t.Error("expected error when the stale token expires too soon")
+		}
+	})
+}
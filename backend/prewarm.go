@@ -0,0 +1,196 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
+)
+
+// prewarmLead is how long before a token enters the cache refresh window the periodic worker
+// replaces it. It spans more than one periodic tick so a late tick does not leave a gap.
+const prewarmLead = 2 * time.Minute
+
+// prewarmStatus is the outcome of the background refreshes of one prewarmed role
+type prewarmStatus struct {
+	LastRefreshAt       time.Time
+	ExpiresAt           time.Time
+	LastError           string
+	LastErrorAt         time.Time
+	ConsecutiveFailures int
+}
+
+// prewarmTracker records the refresh status of prewarmed roles on this node for health
+type prewarmTracker struct {
+	mu    sync.Mutex
+	roles map[string]*prewarmStatus
+}
+
+// newPrewarmTracker creates a tracker with no roles
+func newPrewarmTracker() *prewarmTracker {
+	return &prewarmTracker{
+		roles: make(map[string]*prewarmStatus),
+	}
+}
+
+// status returns the status entry of a role, creating it; callers hold mu
+func (p *prewarmTracker) status(roleName string) *prewarmStatus {
+	status, ok := p.roles[roleName]
+	if !ok {
+		status = &prewarmStatus{}
+		p.roles[roleName] = status
+	}
+	return status
+}
+
+// recordSuccess records a refreshed token for a role
+func (p *prewarmTracker) recordSuccess(roleName string, expiresAt, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := p.status(roleName)
+	status.LastRefreshAt = now
+	status.ExpiresAt = expiresAt
+	status.ConsecutiveFailures = 0
+}
+
+// recordFailure records a failed refresh for a role
+func (p *prewarmTracker) recordFailure(roleName string, err error, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := p.status(roleName)
+	status.LastError = err.Error()
+	status.LastErrorAt = now
+	status.ConsecutiveFailures++
+}
+
+// track sets the roles being prewarmed, dropping the status of roles that no longer are
+func (p *prewarmTracker) track(roleNames map[string]bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for name := range p.roles {
+		if !roleNames[name] {
+			delete(p.roles, name)
+		}
+	}
+	for name := range roleNames {
+		p.status(name)
+	}
+}
+
+// snapshot returns a copy of every prewarmed role's status
+func (p *prewarmTracker) snapshot() map[string]prewarmStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot := make(map[string]prewarmStatus, len(p.roles))
+	for name, status := range p.roles {
+		snapshot[name] = *status
+	}
+	return snapshot
+}
+
+// prewarmTokens keeps a fresh token in this node's cache for every role with prewarm set,
+// so creds reads for those roles never wait on Skyflow. Failures are logged and tracked
+// for health; they never fail the periodic tick.
+func (b *skyflowBackend) prewarmTokens(ctx context.Context, req *logical.Request) {
+	names, err := b.listRoles(ctx, req.Storage)
+	if err != nil {
+		b.Logger().Warn("failed to list roles to prewarm", "error", err)
+		return
+	}
+
+	_, skyflowVaultName := requestLabels(req)
+	prewarmed := make(map[string]bool)
+
+	for _, name := range names {
+		role, err := b.getRole(ctx, req.Storage, name)
+		if err != nil {
+			b.Logger().Warn("failed to load role to prewarm", "role", name, "error", err)
+			continue
+		}
+		if role == nil || !role.Prewarm {
+			continue
+		}
+
+		prewarmed[name] = true
+
+		start := time.Now()
+		refreshed, err := b.prewarmRole(ctx, req.Storage, role)
+		if !refreshed && err == nil {
+			continue
+		}
+
+		if m := b.metrics(); m != nil {
+			m.RecordTokenPrewarm(ctx, name, skyflowVaultName, err == nil)
+		}
+
+		if err != nil {
+			b.prewarm.recordFailure(name, err, time.Now())
+			b.Logger().Warn("failed to prewarm token", "role", name, "error", err)
+			continue
+		}
+
+		b.Logger().Debug("token prewarmed", "role", name, "duration_ms", time.Since(start).Milliseconds())
+	}
+
+	b.prewarm.track(prewarmed)
+}
+
+// prewarmRole mints a new token for a role without ctx if its cached one is about to enter the
+// refresh window. refreshed reports whether a token was minted.
+func (b *skyflowBackend) prewarmRole(ctx context.Context, s logical.Storage, role *skyflowRole) (refreshed bool, err error) {
+	config, err := b.tokenConfig(ctx, s, role)
+	if err != nil {
+		return false, err
+	}
+
+	if config == nil {
+		if role.CredentialSet != "" {
+			return false, fmt.Errorf("credential set %q not found", role.CredentialSet)
+		}
+		return false, errors.New("backend not configured")
+	}
+
+	if err := role.validateRoleIDPolicy(config); err != nil {
+		return false, fmt.Errorf("role violates mount policy: %w", err)
+	}
+
+	if _, ok := b.tokenCache.get(role.Name, "", config.tokenCacheRefreshWindow()+prewarmLead); ok {
+		return false, nil
+	}
+
+	// Share the mint with any creds read for the same role that is already waiting on Skyflow
+	cacheEpoch := b.tokenCache.currentEpoch()
+	sdkCallStart := time.Now()
+	flightKey := tokenFlightKey(role.Name, "", config.Version, cacheEpoch)
+	token, shared, err := b.tokenFlight.do(ctx, flightKey, func(ctx context.Context) (*common.TokenResponse, error) {
+		return b.generateToken(ctx, config, role, "")
+	})
+	if err != nil {
+		if m := b.metrics(); m != nil && !shared && !errors.Is(err, errCircuitOpen) {
+			m.RecordSkyflowSDKCallError(ctx, role.Name, classifyTokenError(err))
+		}
+		return true, err
+	}
+
+	if m := b.metrics(); m != nil && !shared {
+		m.RecordSkyflowSDKCall(ctx, role.Name, "success", float64(time.Since(sdkCallStart).Milliseconds()))
+	}
+
+	issued, err := newIssuedToken(token, role, config)
+	if err != nil {
+		return true, fmt.Errorf("token cannot be cached: %w", err)
+	}
+
+	b.tokenCache.put(cacheEpoch, role.Name, "", issued)
+	b.prewarm.recordSuccess(role.Name, issued.ExpiresAt, time.Now())
+
+	return true, nil
+}
@@ -0,0 +1,163 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestPrewarm_PeriodicRefresh(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+	server := newFakeTokenServer(t)
+
+	lb, err := Factory(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+	b := lb.(*skyflowBackend)
+
+	writes := []struct {
+		path string
+		data map[string]interface{}
+	}{
+		{path: "config", data: map[string]interface{}{
+			"credentials_json":          server.credentialsJSON(t),
+			"token_issuer":              tokenIssuerNative,
+			"ca_bundle":                 fakeServerCABundle(server),
+			"validate_credentials":      false,
+			"max_retries":               0,
+			"circuit_breaker_threshold": 0,
+		}},
+		{path: "roles/payment-risk-engine", data: map[string]interface{}{"role_ids": "skyflow-role-read", "prewarm": true}},
+		{path: "roles/order-producer", data: map[string]interface{}{"role_ids": "skyflow-role-write"}},
+	}
+	for _, w := range writes {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      w.path,
+			Storage:   storage,
+			Data:      w.data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write %s: err=%v resp=%v", w.path, err, resp)
+		}
+	}
+
+	tick := func(t *testing.T) {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.RollbackOperation,
+			Storage:   storage,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("periodic tick failed: err=%v resp=%v", err, resp)
+		}
+	}
+
+	readPrewarmHealth := func(t *testing.T) map[string]interface{} {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "health",
+			Storage:   storage,
+		})
+		if err != nil || resp == nil {
+			t.Fatalf("failed to read health: err=%v", err)
+		}
+		return resp.Data["prewarm"].(map[string]interface{})
+	}
+
+	// expireSoon replaces the prewarmed token with one about to enter the refresh window
+	expireSoon := func() {
+		token := testIssuedToken(time.Now().Add(defaultTokenCacheRefreshWindow + prewarmLead/2))
+		b.tokenCache.put(b.tokenCache.currentEpoch(), "payment-risk-engine", "", token)
+	}
+
+	t.Run("Tick mints tokens for prewarmed roles only", func(t *testing.T) {
+		tick(t)
+
+		exchanges := server.accepted()
+		if len(exchanges) != 1 || exchanges[0].Scope != "role:skyflow-role-read" {
+			t.Fatalf("expected one exchange for the prewarmed role, got %v", exchanges)
+		}
+
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.ReadOperation,
+			Path:       "creds/payment-risk-engine",
+			Storage:    storage,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("token request failed: err=%v resp=%v", err, resp)
+		}
+		if len(server.accepted()) != 1 {
+			t.Error("expected creds to be served from the prewarmed token")
+		}
+
+		status := readPrewarmHealth(t)["payment-risk-engine"].(map[string]interface{})
+		if _, ok := status["last_refresh_at"]; !ok {
+			t.Errorf("expected last_refresh_at in health, got %v", status)
+		}
+	})
+
+	t.Run("Fresh token is not refreshed", func(t *testing.T) {
+		tick(t)
+
+		if len(server.accepted()) != 1 {
+			t.Errorf("expected no new exchange, got %d", len(server.accepted()))
+		}
+	})
+
+	t.Run("Token is refreshed ahead of the refresh window", func(t *testing.T) {
+		expireSoon()
+		tick(t)
+
+		if len(server.accepted()) != 2 {
+			t.Errorf("expected a refresh, got %d exchanges", len(server.accepted()))
+		}
+	})
+
+	t.Run("Refresh failures are reported in health", func(t *testing.T) {
+		expireSoon()
+		server.failNext(1)
+		tick(t)
+
+		status := readPrewarmHealth(t)["payment-risk-engine"].(map[string]interface{})
+		if status["consecutive_failures"] != 1 {
+			t.Errorf("expected 1 consecutive failure, got %v", status["consecutive_failures"])
+		}
+		if _, ok := status["last_error"]; !ok {
+			t.Error("expected last_error in health")
+		}
+
+		tick(t)
+		status = readPrewarmHealth(t)["payment-risk-engine"].(map[string]interface{})
+		if status["consecutive_failures"] != 0 {
+			t.Errorf("expected the next refresh to clear failures, got %v", status["consecutive_failures"])
+		}
+	})
+
+	t.Run("Role without prewarm drops out of health", func(t *testing.T) {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "roles/payment-risk-engine",
+			Storage:   storage,
+			Data:      map[string]interface{}{"prewarm": false},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write role: err=%v resp=%v", err, resp)
+		}
+		tick(t)
+
+		if _, ok := readPrewarmHealth(t)["payment-risk-engine"]; ok {
+			t.Error("expected the role to be dropped from prewarm health")
+		}
+	})
+}
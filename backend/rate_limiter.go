@@ -0,0 +1,119 @@
+package backend
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Values of a role's rate_limit_per
+const (
+	rateLimitPerRole              = "role"
+	rateLimitPerApplicationSource = "application_source"
+	rateLimitPerEntity            = "entity"
+)
+
+// maxRateLimitBuckets bounds the per-client buckets kept in memory; idle full buckets are
+// dropped first since a new bucket starts full anyway, then the least recently used one
+const maxRateLimitBuckets = 10000
+
+// tokenBucket is one rate limit bucket, refilled at rate per second up to burst
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	rate   float64
+	burst  int
+}
+
+// refilledBy reports whether the bucket would have refilled completely by now
+func (b *tokenBucket) refilledBy(now time.Time) bool {
+	refill := time.Duration(float64(b.burst) / b.rate * float64(time.Second))
+	return now.Sub(b.last) >= refill
+}
+
+// rateLimiter holds the token buckets that throttle creds/<role> reads on this node.
+// Each mount owns its own; buckets for a role are dropped when the role changes.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// newRateLimiter creates a rate limiter with no buckets
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// rateLimitKey builds the bucket key for a role and, when limiting per client, the client
+func rateLimitKey(roleName, client string) string {
+	return roleName + "\x00" + client
+}
+
+// allow takes a token from key's bucket, refilling it at rate per second up to burst.
+// If the bucket is empty it returns false and how long until a token is available.
+func (l *rateLimiter) allow(key string, rate float64, burst int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxRateLimitBuckets {
+			l.pruneIdle(now)
+		}
+		if len(l.buckets) >= maxRateLimitBuckets {
+			l.evictOldest()
+		}
+		bucket = &tokenBucket{tokens: float64(burst), last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.rate = rate
+	bucket.burst = burst
+
+	bucket.tokens = math.Min(float64(burst), bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+// pruneIdle drops buckets that would have refilled completely by now at their own rate; callers hold mu
+func (l *rateLimiter) pruneIdle(now time.Time) {
+	for key, bucket := range l.buckets {
+		if bucket.refilledBy(now) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// evictOldest drops the least recently used bucket; callers hold mu
+func (l *rateLimiter) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, bucket := range l.buckets {
+		if oldestKey == "" || bucket.last.Before(oldest) {
+			oldestKey, oldest = key, bucket.last
+		}
+	}
+	delete(l.buckets, oldestKey)
+}
+
+// purgeRole drops every bucket of a role
+func (l *rateLimiter) purgeRole(roleName string) {
+	prefix := rateLimitKey(roleName, "")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key := range l.buckets {
+		if strings.HasPrefix(key, prefix) {
+			delete(l.buckets, key)
+		}
+	}
+}
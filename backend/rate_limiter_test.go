@@ -0,0 +1,216 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	start := time.Now()
+
+	t.Run("Burst then reject", func(t *testing.T) {
+		limiter := newRateLimiter()
+		for i := 0; i < 3; i++ {
+			if ok, _ := limiter.allow("key", 1, 3, start); !ok {
+				t.Fatalf("request %d rejected within burst", i)
+			}
+		}
+
+		ok, retryAfter := limiter.allow("key", 1, 3, start)
+		if ok {
+			t.Fatal("expected request beyond burst to be rejected")
+		}
+		if retryAfter != time.Second {
+			t.Errorf("expected retry after 1s, got %s", retryAfter)
+		}
+	})
+
+	t.Run("Bucket refills at rate", func(t *testing.T) {
+		limiter := newRateLimiter()
+		limiter.allow("key", 2, 1, start)
+
+		if ok, _ := limiter.allow("key", 2, 1, start.Add(100*time.Millisecond)); ok {
+			t.Error("expected rejection before a token is refilled")
+		}
+		if ok, _ := limiter.allow("key", 2, 1, start.Add(600*time.Millisecond)); !ok {
+			t.Error("expected a refilled token after 500ms at 2/s")
+		}
+	})
+
+	t.Run("Keys have separate buckets", func(t *testing.T) {
+		limiter := newRateLimiter()
+		limiter.allow(rateLimitKey("role-a", "svc-1"), 1, 1, start)
+
+		if ok, _ := limiter.allow(rateLimitKey("role-a", "svc-2"), 1, 1, start); !ok {
+			t.Error("expected another client to have its own bucket")
+		}
+	})
+
+	t.Run("Pruning keeps buckets still refilling at their own rate", func(t *testing.T) {
+		limiter := newRateLimiter()
+		strict := rateLimitKey("ledger-writer", "")
+		limiter.allow(strict, 0.01, 2, start)
+		limiter.allow(strict, 0.01, 2, start)
+
+		for i := 1; i < maxRateLimitBuckets; i++ {
+			limiter.allow(rateLimitKey("order-reader", fmt.Sprintf("svc-%d", i)), 10, 1, start)
+		}
+
+		// The new bucket prunes the lenient buckets, which refilled within 100ms
+		now := start.Add(time.Second)
+		limiter.allow(rateLimitKey("order-reader", "svc-new"), 10, 1, now)
+
+		if ok, _ := limiter.allow(strict, 0.01, 2, now); ok {
+			t.Error("expected the drained strict bucket to survive pruning")
+		}
+		if len(limiter.buckets) != 2 {
+			t.Errorf("expected 2 buckets after pruning, got %d", len(limiter.buckets))
+		}
+	})
+
+	t.Run("Buckets stay bounded when none are idle", func(t *testing.T) {
+		limiter := newRateLimiter()
+		for i := 0; i < maxRateLimitBuckets; i++ {
+			limiter.allow(rateLimitKey("ledger-writer", fmt.Sprintf("svc-%d", i)), 0.01, 1, start.Add(time.Duration(i)*time.Millisecond))
+		}
+
+		limiter.allow(rateLimitKey("ledger-writer", "svc-new"), 0.01, 1, start.Add(time.Hour/100))
+
+		if len(limiter.buckets) != maxRateLimitBuckets {
+			t.Errorf("expected %d buckets, got %d", maxRateLimitBuckets, len(limiter.buckets))
+		}
+		if _, ok := limiter.buckets[rateLimitKey("ledger-writer", "svc-0")]; ok {
+			t.Error("expected the least recently used bucket to be evicted")
+		}
+	})
+
+	t.Run("Purge drops only the role's buckets", func(t *testing.T) {
+		limiter := newRateLimiter()
+		limiter.allow(rateLimitKey("role-a", ""), 1, 1, start)
+		limiter.allow(rateLimitKey("role-ab", ""), 1, 1, start)
+
+		limiter.purgeRole("role-a")
+
+		if ok, _ := limiter.allow(rateLimitKey("role-a", ""), 1, 1, start); !ok {
+			t.Error("expected a fresh bucket after purge")
+		}
+		if ok, _ := limiter.allow(rateLimitKey("role-ab", ""), 1, 1, start); ok {
+			t.Error("expected role-ab's bucket to be kept")
+		}
+	})
+}
+
+func TestPathToken_RateLimit(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+	issuer := &slowTokenIssuer{}
+
+	b, err := FactoryWithOptions(WithTokenIssuer(issuer))(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"credentials_json":     `{"test": "creds"}`,
+			"validate_credentials": false,
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+	}
+
+	writeRole := func(t *testing.T, data map[string]interface{}) {
+		t.Helper()
+		data["role_ids"] = "skyflow-role-read"
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "roles/payment-risk-engine",
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write role: err=%v resp=%v", err, resp)
+		}
+	}
+
+	readCreds := func(t *testing.T, source string) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.ReadOperation,
+			Path:       "creds/payment-risk-engine",
+			Storage:    storage,
+			Headers:    map[string][]string{"Application-Source": {source}},
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil || resp == nil {
+			t.Fatalf("unexpected result: err=%v resp=%v", err, resp)
+		}
+		return resp
+	}
+
+	t.Run("Requests beyond the burst get a 429", func(t *testing.T) {
+		writeRole(t, map[string]interface{}{"rate_limit": 0.5, "rate_limit_burst": 2})
+		calls := issuer.calls.Load()
+
+		for i := 0; i < 2; i++ {
+			if resp := readCreds(t, "checkout-service"); resp.IsError() {
+				t.Fatalf("request %d rejected within burst: %v", i, resp.Error())
+			}
+		}
+
+		resp := readCreds(t, "checkout-service")
+		body := decodeRawErrorBody(t, resp, http.StatusTooManyRequests)
+		if body.Data.ErrorCode != tokenErrorRateLimited {
+			t.Errorf("error_code = %q, want %q", body.Data.ErrorCode, tokenErrorRateLimited)
+		}
+		if got := resp.Headers["Retry-After"]; len(got) != 1 || got[0] != "2" {
+			t.Errorf("expected Retry-After 2, got %v", got)
+		}
+		if issuer.calls.Load() != calls+2 {
+			t.Error("expected the rejected request not to reach the issuer")
+		}
+	})
+
+	t.Run("Role limit is shared by all callers", func(t *testing.T) {
+		if resp := readCreds(t, "ledger-service"); resp.Data[logical.HTTPStatusCode] != http.StatusTooManyRequests {
+			t.Error("expected another caller to share the role's bucket")
+		}
+	})
+
+	t.Run("Per application_source limits each caller separately", func(t *testing.T) {
+		writeRole(t, map[string]interface{}{"rate_limit": 0.5, "rate_limit_burst": 1, "rate_limit_per": "application_source"})
+
+		if resp := readCreds(t, "checkout-service"); resp.IsError() {
+			t.Fatalf("unexpected error after role update: %v", resp.Error())
+		}
+		if resp := readCreds(t, "ledger-service"); resp.IsError() {
+			t.Errorf("expected ledger-service to have its own bucket: %v", resp.Error())
+		}
+		if resp := readCreds(t, "checkout-service"); resp.Data[logical.HTTPStatusCode] != http.StatusTooManyRequests {
+			t.Error("expected checkout-service to be limited")
+		}
+	})
+
+	t.Run("Disabled when rate_limit is 0", func(t *testing.T) {
+		writeRole(t, map[string]interface{}{"rate_limit": 0})
+
+		for i := 0; i < 5; i++ {
+			if resp := readCreds(t, "checkout-service"); resp.IsError() {
+				t.Fatalf("request %d rejected without a rate limit: %v", i, resp.Error())
+			}
+		}
+	})
+}
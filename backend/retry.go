@@ -0,0 +1,103 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	skyflowError "github.com/skyflowapi/skyflow-go/v2/utils/error"
+)
+
+const (
+	// defaultRequestTimeout bounds a token call, including its retries
+	defaultRequestTimeout = 30 * time.Second
+
+	// defaultMaxRetries is how many times a transient token call failure is retried
+	defaultMaxRetries = 2
+
+	// maxMaxRetries caps max_retries so a request cannot retry indefinitely
+	maxMaxRetries = 10
+
+	// defaultRetryBackoff is the base delay before the first retry
+	defaultRetryBackoff = 250 * time.Millisecond
+
+	// maxRetryDelay caps the delay between two attempts
+	maxRetryDelay = 5 * time.Second
+)
+
+// retryableStatusCodes are the HTTP statuses from Skyflow worth retrying
+var retryableStatusCodes = map[int]bool{
+	408: true,
+	429: true,
+	500: true,
+	502: true,
+	503: true,
+	504: true,
+}
+
+// transientNetworkErrors are fragments of transport errors the Skyflow SDK folds into its error message
+var transientNetworkErrors = []string{
+	"connection refused",
+	"connection reset",
+	"broken pipe",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"Client.Timeout exceeded",
+	"unexpected EOF",
+}
+
+// isRetryableTokenError reports whether a failed token call may succeed if tried again.
+// Cancelled and expired contexts are never retried.
+func isRetryableTokenError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var exchangeErr *tokenExchangeError
+	if errors.As(err, &exchangeErr) {
+		return retryableStatusCodes[exchangeErr.StatusCode]
+	}
+
+	var sdkErr *skyflowError.SkyflowError
+	if errors.As(err, &sdkErr) {
+		code, convErr := strconv.Atoi(strings.TrimPrefix(sdkErr.GetCode(), "Code: "))
+		if convErr == nil && retryableStatusCodes[code] {
+			return true
+		}
+		// The SDK reports transport failures as 400s carrying the original error text
+		message := sdkErr.Error()
+		for _, fragment := range transientNetworkErrors {
+			if strings.Contains(message, fragment) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryDelay returns the jittered exponential delay before retry number attempt (starting at 1)
+func retryDelay(backoff time.Duration, attempt int) time.Duration {
+	delay := backoff
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	// Equal jitter: keep half the delay, randomise the rest so retrying callers spread out
+	half := delay / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,179 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
+	skyflowError "github.com/skyflowapi/skyflow-go/v2/utils/error"
+)
+
+func TestRetry_IsRetryableTokenError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "Nil", err: nil, want: false},
+		{name: "Exchange 503", err: &tokenExchangeError{StatusCode: 503}, want: true},
+		{name: "Exchange 429", err: &tokenExchangeError{StatusCode: 429}, want: true},
+		{name: "Exchange 401", err: &tokenExchangeError{StatusCode: 401}, want: false},
+		{name: "SDK server error", err: fmt.Errorf("failed: %w", skyflowError.NewSkyflowError(skyflowError.SERVER, "boom")), want: true},
+		{name: "SDK invalid credentials", err: fmt.Errorf("failed: %w", skyflowError.NewSkyflowError(skyflowError.INVALID_INPUT_CODE, "invalid credentials")), want: false},
+		{name: "SDK connection refused", err: fmt.Errorf("failed: %w", skyflowError.NewSkyflowError(skyflowError.INVALID_INPUT_CODE, "dial tcp 127.0.0.1:443: connect: connection refused")), want: true},
+		{name: "Network error", err: fmt.Errorf("token request failed: %w", &net.OpError{Op: "dial", Err: fmt.Errorf("refused")}), want: true},
+		{name: "Unexpected EOF", err: fmt.Errorf("token request failed: %w", io.ErrUnexpectedEOF), want: true},
+		{name: "Context cancelled", err: fmt.Errorf("abandoned: %w", context.Canceled), want: false},
+		{name: "Context deadline", err: fmt.Errorf("abandoned: %w", context.DeadlineExceeded), want: false},
+		{name: "Plain error", err: fmt.Errorf("no credentials configured"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableTokenError(tt.err); got != tt.want {
+				t.Errorf("isRetryableTokenError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetry_RetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{attempt: 1, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{attempt: 2, min: 100 * time.Millisecond, max: 200 * time.Millisecond},
+		{attempt: 3, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{attempt: 20, min: maxRetryDelay / 2, max: maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("Attempt %d", tt.attempt), func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				delay := retryDelay(100*time.Millisecond, tt.attempt)
+				if delay < tt.min || delay > tt.max {
+					t.Fatalf("delay %s outside [%s, %s]", delay, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+// blockingTokenIssuer is a TokenIssuer that never answers before ctx is done
+type blockingTokenIssuer struct{}
+
+func (blockingTokenIssuer) IssueToken(ctx context.Context, req *TokenRequest) (*common.TokenResponse, error) {
+	<-ctx.Done()
+	return nil, fmt.Errorf("token request abandoned: %w", ctx.Err())
+}
+
+func TestRetry_GenerateToken(t *testing.T) {
+	ctx := context.Background()
+
+	newBackend := func(t *testing.T, opts ...Option) *skyflowBackend {
+		t.Helper()
+		b, err := FactoryWithOptions(opts...)(ctx, &logical.BackendConfig{
+			Logger:      nil,
+			System:      &logical.StaticSystemView{},
+			StorageView: &logical.InmemStorage{},
+		})
+		if err != nil {
+			t.Fatalf("unable to create backend: %v", err)
+		}
+		return b.(*skyflowBackend)
+	}
+
+	role := &skyflowRole{Name: "payment-risk-engine", RoleIDs: []string{"skyflow-role-read"}}
+
+	// The native issuer is used because the SDK also retries 503s inside its own HTTP client
+	t.Run("Transient failures are retried", func(t *testing.T) {
+		server := newFakeTokenServer(t)
+		server.failNext(2)
+
+		backend := newBackend(t)
+		cfg := &skyflowConfig{
+			CredentialsJSON: server.credentialsJSON(t),
+			TokenIssuer:     tokenIssuerNative,
+			CABundle:        fakeServerCABundle(server),
+			MaxRetries:      intPtr(2),
+			RetryBackoff:    time.Millisecond,
+		}
+
+		token, err := backend.generateToken(ctx, cfg, role, "")
+		if err != nil {
+			t.Fatalf("expected success after retries, got %v", err)
+		}
+		if token.AccessToken == "" {
+			t.Error("expected an access token")
+		}
+		if got := len(server.accepted()); got != 1 {
+			t.Errorf("expected 1 accepted exchange, got %d", got)
+		}
+	})
+
+	t.Run("Retries are bounded by max_retries", func(t *testing.T) {
+		server := newFakeTokenServer(t)
+		server.failNext(2)
+
+		backend := newBackend(t)
+		cfg := &skyflowConfig{
+			CredentialsJSON: server.credentialsJSON(t),
+			TokenIssuer:     tokenIssuerNative,
+			CABundle:        fakeServerCABundle(server),
+			MaxRetries:      intPtr(1),
+			RetryBackoff:    time.Millisecond,
+		}
+
+		if _, err := backend.generateToken(ctx, cfg, role, ""); err == nil {
+			t.Fatal("expected failure once retries are exhausted")
+		}
+		if got := len(server.accepted()); got != 0 {
+			t.Errorf("expected no accepted exchange, got %d", got)
+		}
+	})
+
+	t.Run("Permanent failures are not retried", func(t *testing.T) {
+		issuer := &recordingTokenIssuer{err: &tokenExchangeError{StatusCode: 401}}
+		backend := newBackend(t, WithTokenIssuer(issuer))
+		cfg := &skyflowConfig{CredentialsJSON: `{"test": "creds"}`, MaxRetries: intPtr(3), RetryBackoff: time.Millisecond}
+
+		if _, err := backend.generateToken(ctx, cfg, role, ""); err == nil {
+			t.Fatal("expected error")
+		}
+		if len(issuer.requests) != 1 {
+			t.Errorf("expected 1 attempt, got %d", len(issuer.requests))
+		}
+	})
+
+	t.Run("Request timeout bounds a hung call", func(t *testing.T) {
+		backend := newBackend(t, WithTokenIssuer(blockingTokenIssuer{}))
+		cfg := &skyflowConfig{CredentialsJSON: `{"test": "creds"}`, RequestTimeout: 50 * time.Millisecond}
+
+		start := time.Now()
+		_, err := backend.generateToken(ctx, cfg, role, "")
+		if err == nil || !strings.Contains(err.Error(), "exceeded request_timeout") {
+			t.Errorf("expected request_timeout error, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("generateToken took %s, expected it to stop at the timeout", elapsed)
+		}
+	})
+}
+
+func TestRetry_SDKIssuerHonoursContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := sdkTokenIssuer{}.IssueToken(ctx, &TokenRequest{CredentialsJSON: `{"test": "creds"}`})
+	if err == nil || !strings.Contains(err.Error(), "abandoned") {
+		t.Errorf("expected abandoned error, got %v", err)
+	}
+}
@@ -3,9 +3,14 @@ package backend
 import (
 	"fmt"
 	"context"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/ryanuber/go-glob"
 )
 
 // skyflowRole represents a role configuration for token generation
@@ -17,6 +22,36 @@ type skyflowRole struct {
 	// Skyflow role IDs (mandatory) - passed to SDK for token generation
 	RoleIDs []string `json:"role_ids"`
 
+	// Named credential set used to mint tokens (optional, defaults to mount config credentials)
+	CredentialSet string `json:"credential_set,omitempty"`
+
+	// Lease limits (optional) - cap the lease on issued tokens below the token's own expiry
+	TTL    time.Duration `json:"ttl,omitempty"`
+	MaxTTL time.Duration `json:"max_ttl,omitempty"`
+
+	// Caller ctx policy (optional) - a glob, or a regular expression when prefixed with "^"
+	AllowedCtxPattern string `json:"allowed_ctx_pattern,omitempty"`
+	CtxRequired       bool   `json:"ctx_required,omitempty"`
+
+	// Identity template rendered into ctx from the requesting entity (optional); callers cannot override it
+	CtxTemplate string `json:"ctx_template,omitempty"`
+
+	// Allow sign/<role> to sign data tokens with the role's credentials (optional, default false)
+	AllowSigning bool `json:"allow_signing,omitempty"`
+
+	// Serve the last issued token when Skyflow fails, if it has at least StaleMinRemainingTTL left (optional)
+	ServeStaleOnError    bool          `json:"serve_stale_on_error,omitempty"`
+	StaleMinRemainingTTL time.Duration `json:"stale_min_remaining_ttl,omitempty"`
+
+	// Token-bucket limit on creds reads in requests per second, shared by the whole role or kept
+	// per Application-Source or entity (optional, 0 disables)
+	RateLimit      float64 `json:"rate_limit,omitempty"`
+	RateLimitBurst int     `json:"rate_limit_burst,omitempty"`
+	RateLimitPer   string  `json:"rate_limit_per,omitempty"`
+
+	// Keep a fresh token without ctx in the cache, refreshed by the periodic worker (optional)
+	Prewarm bool `json:"prewarm,omitempty"`
+
 	// Metadata
 	Tags      []string  `json:"tags,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
@@ -39,12 +74,192 @@ func (r *skyflowRole) validate() error {
 		return fmt.Errorf("role name is required")
 	}
 
-	// Role IDs - exactly one required (future: may support multiple)
+	// Role IDs - at least one required; the mount caps how many (see validateRoleIDPolicy)
 	if len(r.RoleIDs) == 0 {
 		return fmt.Errorf("role_ids is required")
 	}
-	if len(r.RoleIDs) > 1 {
-		return fmt.Errorf("only one role_id is supported. for multiple roles please contact plugin admin")
+
+	seen := make(map[string]bool, len(r.RoleIDs))
+	for _, id := range r.RoleIDs {
+		if id == "" {
+			return fmt.Errorf("role_ids cannot contain empty values")
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate role_id %q", id)
+		}
+		seen[id] = true
+	}
+
+	if r.TTL < 0 {
+		return fmt.Errorf("ttl cannot be negative")
+	}
+	if r.MaxTTL < 0 {
+		return fmt.Errorf("max_ttl cannot be negative")
+	}
+	if r.MaxTTL > 0 && r.TTL > r.MaxTTL {
+		return fmt.Errorf("ttl (%s) cannot exceed max_ttl (%s)", r.TTL, r.MaxTTL)
+	}
+
+	if isRegexCtxPattern(r.AllowedCtxPattern) {
+		if _, err := compileCtxPattern(r.AllowedCtxPattern); err != nil {
+			return fmt.Errorf("invalid allowed_ctx_pattern: %w", err)
+		}
+	}
+
+	if err := validateCtxTemplate(r.CtxTemplate); err != nil {
+		return err
+	}
+
+	if r.StaleMinRemainingTTL < 0 {
+		return fmt.Errorf("stale_min_remaining_ttl cannot be negative")
+	}
+
+	if r.RateLimit < 0 {
+		return fmt.Errorf("rate_limit cannot be negative")
+	}
+	if r.RateLimitBurst < 0 {
+		return fmt.Errorf("rate_limit_burst cannot be negative")
+	}
+	switch r.RateLimitPer {
+	case "", rateLimitPerRole, rateLimitPerApplicationSource, rateLimitPerEntity:
+	default:
+		return fmt.Errorf("rate_limit_per must be %q, %q or %q", rateLimitPerRole, rateLimitPerApplicationSource, rateLimitPerEntity)
+	}
+
+	// Prewarmed tokens are minted without ctx, which these roles never serve
+	if r.Prewarm && r.CtxRequired {
+		return fmt.Errorf("prewarm cannot be used with ctx_required")
+	}
+	if r.Prewarm && r.CtxTemplate != "" {
+		return fmt.Errorf("prewarm cannot be used with ctx_template")
+	}
+
+	return nil
+}
+
+// staleMinRemainingTTL returns how much lifetime a token needs left to be served stale
+func (r *skyflowRole) staleMinRemainingTTL() time.Duration {
+	if r.StaleMinRemainingTTL <= 0 {
+		return defaultStaleMinRemainingTTL
+	}
+	return r.StaleMinRemainingTTL
+}
+
+// rateLimitBurst returns how many creds reads the role's bucket holds, at least one second's worth
+func (r *skyflowRole) rateLimitBurst() int {
+	if r.RateLimitBurst <= 0 {
+		return int(math.Max(1, math.Ceil(r.RateLimit)))
+	}
+	return r.RateLimitBurst
+}
+
+// rateLimitPer returns what the role's rate limit is applied to
+func (r *skyflowRole) rateLimitPer() string {
+	if r.RateLimitPer == "" {
+		return rateLimitPerRole
+	}
+	return r.RateLimitPer
+}
+
+// isRegexCtxPattern reports whether an allowed_ctx_pattern is a regular expression rather than a glob
+func isRegexCtxPattern(pattern string) bool {
+	return strings.HasPrefix(pattern, "^")
+}
+
+// ctxPatternCache holds compiled regular expression ctx patterns, keyed by allowed_ctx_pattern
+var ctxPatternCache sync.Map
+
+// compileCtxPattern compiles a regular expression ctx pattern anchored at both ends, so that like
+// a glob it must match the whole ctx. Patterns are compiled once per process.
+func compileCtxPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := ctxPatternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	// Compile the pattern as written first so errors refer to what the operator wrote
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, err
+	}
+
+	ctxPatternCache.Store(pattern, re)
+	return re, nil
+}
+
+// validateCtx checks a caller-supplied ctx against the role's ctx policy
+func (r *skyflowRole) validateCtx(ctxData string) error {
+	if ctxData == "" {
+		if r.CtxRequired {
+			return fmt.Errorf("ctx is required for role %q", r.Name)
+		}
+		return nil
+	}
+
+	if r.AllowedCtxPattern == "" {
+		return nil
+	}
+
+	var matched bool
+	if isRegexCtxPattern(r.AllowedCtxPattern) {
+		re, err := compileCtxPattern(r.AllowedCtxPattern)
+		if err != nil {
+			return fmt.Errorf("invalid allowed_ctx_pattern: %w", err)
+		}
+		matched = re.MatchString(ctxData)
+	} else {
+		matched = glob.Glob(r.AllowedCtxPattern, ctxData)
+	}
+
+	if !matched {
+		return fmt.Errorf("ctx does not match the allowed_ctx_pattern of role %q", r.Name)
+	}
+
+	return nil
+}
+
+// leaseTTL returns the lease TTL for a token with the given remaining lifetime, capped by the
+// role's ttl and max_ttl. A tokenTTL of 0 means the token expiry is unknown.
+func (r *skyflowRole) leaseTTL(tokenTTL time.Duration) time.Duration {
+	ttl := tokenTTL
+	for _, limit := range []time.Duration{r.TTL, r.MaxTTL} {
+		if limit > 0 && (ttl == 0 || limit < ttl) {
+			ttl = limit
+		}
+	}
+	return ttl
+}
+
+// leaseMaxTTL returns the maximum lifetime of a lease on a token with the given remaining
+// lifetime: the role's max_ttl when it is sooner than the token's expiry
+func (r *skyflowRole) leaseMaxTTL(tokenTTL time.Duration) time.Duration {
+	if r.MaxTTL > 0 && (tokenTTL == 0 || r.MaxTTL < tokenTTL) {
+		return r.MaxTTL
+	}
+	return tokenTTL
+}
+
+// validateRoleIDPolicy checks the role's Skyflow role IDs against the mount's role ID cap and allowlist
+func (r *skyflowRole) validateRoleIDPolicy(c *skyflowConfig) error {
+	if len(r.RoleIDs) > c.maxRoleIDs() {
+		return fmt.Errorf("role has %d role_ids but the mount allows at most %d", len(r.RoleIDs), c.maxRoleIDs())
+	}
+
+	if len(c.AllowedRoleIDs) == 0 {
+		return nil
+	}
+
+	allowed := make(map[string]bool, len(c.AllowedRoleIDs))
+	for _, id := range c.AllowedRoleIDs {
+		allowed[id] = true
+	}
+
+	for _, id := range r.RoleIDs {
+		if !allowed[id] {
+			return fmt.Errorf("role_id %q is not in the mount's allowed_role_ids", id)
+		}
 	}
 
 	return nil
@@ -2,6 +2,7 @@ package backend
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -66,13 +67,116 @@ func TestRole_Validate(t *testing.T) {
 			errorMsg:  "role_ids is required",
 		},
 		{
-			name: "Multiple role_ids not allowed",
+			name: "Multiple role_ids",
 			role: &skyflowRole{
 				Name:    "test-role",
 				RoleIDs: []string{"role-id-1", "role-id-2", "role-id-3"},
 			},
+			wantError: false,
+		},
+		{
+			name: "Duplicate role_ids",
+			role: &skyflowRole{
+				Name:    "test-role",
+				RoleIDs: []string{"role-id-1", "role-id-1"},
+			},
+			wantError: true,
+			errorMsg:  "duplicate role_id",
+		},
+		{
+			name: "Empty role_id value",
+			role: &skyflowRole{
+				Name:    "test-role",
+				RoleIDs: []string{"role-id-1", ""},
+			},
+			wantError: true,
+			errorMsg:  "role_ids cannot contain empty values",
+		},
+		{
+			name: "Valid role with lease limits and ctx policy",
+			role: &skyflowRole{
+				Name:              "test-role",
+				RoleIDs:           []string{"role-id-1"},
+				TTL:               10 * time.Minute,
+				MaxTTL:            30 * time.Minute,
+				AllowedCtxPattern: "^tenant-[0-9]+$",
+				CtxRequired:       true,
+			},
+			wantError: false,
+		},
+		{
+			name: "ttl above max_ttl",
+			role: &skyflowRole{
+				Name:    "test-role",
+				RoleIDs: []string{"role-id-1"},
+				TTL:     time.Hour,
+				MaxTTL:  time.Minute,
+			},
+			wantError: true,
+			errorMsg:  "cannot exceed max_ttl",
+		},
+		{
+			name: "Negative ttl",
+			role: &skyflowRole{
+				Name:    "test-role",
+				RoleIDs: []string{"role-id-1"},
+				TTL:     -time.Minute,
+			},
+			wantError: true,
+			errorMsg:  "ttl cannot be negative",
+		},
+		{
+			name: "Invalid ctx regex",
+			role: &skyflowRole{
+				Name:              "test-role",
+				RoleIDs:           []string{"role-id-1"},
+				AllowedCtxPattern: "^tenant-(",
+			},
+			wantError: true,
+			errorMsg:  "invalid allowed_ctx_pattern",
+		},
+		{
+			name: "Valid rate limit",
+			role: &skyflowRole{
+				Name:           "test-role",
+				RoleIDs:        []string{"role-id-1"},
+				RateLimit:      2.5,
+				RateLimitBurst: 5,
+				RateLimitPer:   "application_source",
+			},
+			wantError: false,
+		},
+		{
+			name: "Negative rate limit",
+			role: &skyflowRole{
+				Name:      "test-role",
+				RoleIDs:   []string{"role-id-1"},
+				RateLimit: -1,
+			},
+			wantError: true,
+			errorMsg:  "rate_limit cannot be negative",
+		},
+		{
+			name: "Unknown rate_limit_per",
+			role: &skyflowRole{
+				Name:         "test-role",
+				RoleIDs:      []string{"role-id-1"},
+				RateLimit:    1,
+				RateLimitPer: "ip",
+			},
+			wantError: true,
+			errorMsg:  "rate_limit_per must be",
+		},
+		{
+			name: "Prewarm with ctx_required",
+			role: &skyflowRole{
+				Name:        "test-role",
+				RoleIDs:     []string{"role-id-1"},
+				Prewarm:     true,
+				CtxRequired: true,
+			},
 			wantError: true,
-			errorMsg:  "only one role_id is supported",
+			errorMsg:  "prewarm cannot be used with ctx_required",
 		},
 		{
 			name: "Valid role with description and tags",
@@ -288,3 +392,353 @@ func TestRole_UpdateTimestamp(t *testing.T) {
 		t.Error("UpdatedAt should be updated on save")
 	}
 }
+
+func TestRole_ValidateRoleIDPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		role      *skyflowRole
+		config    *skyflowConfig
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "Default cap allows multiple role_ids",
+			role: &skyflowRole{
+				Name:    "test-role",
+				RoleIDs: []string{"role-id-1", "role-id-2"},
+			},
+			config:    &skyflowConfig{},
+			wantError: false,
+		},
+		{
+			name: "Exceeds mount cap",
+			role: &skyflowRole{
+				Name:    "test-role",
+				RoleIDs: []string{"role-id-1", "role-id-2", "role-id-3"},
+			},
+			config:    &skyflowConfig{MaxRoleIDs: 2},
+			wantError: true,
+			errorMsg:  "mount allows at most 2",
+		},
+		{
+			name: "All role_ids allowlisted",
+			role: &skyflowRole{
+				Name:    "test-role",
+				RoleIDs: []string{"role-id-1", "role-id-2"},
+			},
+			config: &skyflowConfig{
+				AllowedRoleIDs: []string{"role-id-1", "role-id-2", "role-id-3"},
+			},
+			wantError: false,
+		},
+		{
+			name: "Role_id not allowlisted",
+			role: &skyflowRole{
+				Name:    "test-role",
+				RoleIDs: []string{"role-id-1", "role-id-4"},
+			},
+			config: &skyflowConfig{
+				AllowedRoleIDs: []string{"role-id-1", "role-id-2"},
+			},
+			wantError: true,
+			errorMsg:  "not in the mount's allowed_role_ids",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.role.validateRoleIDPolicy(tt.config)
+			if tt.wantError {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("expected error containing '%s', got %v", tt.errorMsg, err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRole_MultipleRoleIDsTokenPath(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	config := &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	writeConfig := func(t *testing.T, data map[string]interface{}) {
+		t.Helper()
+		data["credentials_json"] = `{"test": "creds"}`
+		data["validate_credentials"] = false
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "config",
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+		}
+	}
+
+	writeConfig(t, map[string]interface{}{"max_role_ids": 2})
+
+	t.Run("Role over mount cap is rejected", func(t *testing.T) {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.CreateOperation,
+			Path:      "roles/payment-risk-engine",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"role_ids": "skyflow-role-read,skyflow-role-write,skyflow-role-admin",
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected error response for role over mount cap")
+		}
+	})
+
+	t.Run("Multi-ID role reaches token generation", func(t *testing.T) {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.CreateOperation,
+			Path:      "roles/payment-risk-engine",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"role_ids": "skyflow-role-read,skyflow-role-write",
+			},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write role: err=%v resp=%v", err, resp)
+		}
+
+		role, err := b.(*skyflowBackend).getRole(ctx, storage, "payment-risk-engine")
+		if err != nil || role == nil {
+			t.Fatalf("failed to read role: %v", err)
+		}
+		if len(role.RoleIDs) != 2 {
+			t.Fatalf("expected 2 role_ids, got %d", len(role.RoleIDs))
+		}
+
+		resp, err = b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.ReadOperation,
+			Path:       "creds/payment-risk-engine",
+			Storage:    storage,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected error response for invalid credentials")
+		}
+		// Credentials are fake, so the request must fail in the SDK rather than on role policy
+		if !strings.Contains(resp.Error().Error(), "failed to generate token") {
+			t.Errorf("expected token generation failure, got: %v", resp.Error())
+		}
+	})
+
+	t.Run("Tightened allowlist rejects existing role", func(t *testing.T) {
+		writeConfig(t, map[string]interface{}{"allowed_role_ids": "skyflow-role-read"})
+
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.ReadOperation,
+			Path:       "creds/payment-risk-engine",
+			Storage:    storage,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected error response for role outside allowlist")
+		}
+		if !strings.Contains(resp.Error().Error(), "violates mount policy") {
+			t.Errorf("expected mount policy error, got: %v", resp.Error())
+		}
+	})
+}
+
+func TestRole_ValidateCtx(t *testing.T) {
+	tests := []struct {
+		name      string
+		role      *skyflowRole
+		ctx       string
+		wantError bool
+	}{
+		{name: "No policy allows any ctx", role: &skyflowRole{}, ctx: "anything"},
+		{name: "No policy allows empty ctx", role: &skyflowRole{}, ctx: ""},
+		{name: "Required ctx missing", role: &skyflowRole{CtxRequired: true}, ctx: "", wantError: true},
+		{name: "Required ctx present", role: &skyflowRole{CtxRequired: true}, ctx: "tenant-1"},
+		{name: "Glob match", role: &skyflowRole{AllowedCtxPattern: "tenant-*"}, ctx: "tenant-42"},
+		{name: "Glob mismatch", role: &skyflowRole{AllowedCtxPattern: "tenant-*"}, ctx: "admin", wantError: true},
+		{name: "Regex match", role: &skyflowRole{AllowedCtxPattern: "^tenant-[0-9]+$"}, ctx: "tenant-42"},
+		{name: "Regex mismatch", role: &skyflowRole{AllowedCtxPattern: "^tenant-[0-9]+$"}, ctx: "tenant-x", wantError: true},
+		{name: "Regex must match the whole ctx", role: &skyflowRole{AllowedCtxPattern: "^tenant-1"}, ctx: "tenant-10", wantError: true},
+		{name: "Regex prefix bypass", role: &skyflowRole{AllowedCtxPattern: "^tenant-1"}, ctx: "tenant-1-admin", wantError: true},
+		{name: "Unanchored regex alternation", role: &skyflowRole{AllowedCtxPattern: "^tenant-1|tenant-2"}, ctx: "tenant-2-admin", wantError: true},
+		{name: "Regex exact match", role: &skyflowRole{AllowedCtxPattern: "^tenant-1"}, ctx: "tenant-1"},
+		{name: "Pattern without ctx_required allows empty ctx", role: &skyflowRole{AllowedCtxPattern: "tenant-*"}, ctx: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.role.validateCtx(tt.ctx)
+			if tt.wantError && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRole_LeaseTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     *skyflowRole
+		tokenTTL time.Duration
+		want     time.Duration
+	}{
+		{name: "No limits uses token lifetime", role: &skyflowRole{}, tokenTTL: time.Hour, want: time.Hour},
+		{name: "ttl below token lifetime", role: &skyflowRole{TTL: 10 * time.Minute}, tokenTTL: time.Hour, want: 10 * time.Minute},
+		{name: "ttl above token lifetime", role: &skyflowRole{TTL: 2 * time.Hour}, tokenTTL: time.Hour, want: time.Hour},
+		{name: "max_ttl below ttl", role: &skyflowRole{TTL: 30 * time.Minute, MaxTTL: 5 * time.Minute}, tokenTTL: time.Hour, want: 5 * time.Minute},
+		{name: "Unknown token lifetime uses ttl", role: &skyflowRole{TTL: 10 * time.Minute}, tokenTTL: 0, want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.role.leaseTTL(tt.tokenTTL); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRole_CtxPolicyTokenPath(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	config := &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	backend := b.(*skyflowBackend)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"credentials_json":     `{"test": "creds"}`,
+			"validate_credentials": false,
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "roles/payment-risk-engine",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"role_ids":            "skyflow-role-read",
+			"ttl":                 "10m",
+			"max_ttl":             "30m",
+			"allowed_ctx_pattern": "tenant-*",
+			"ctx_required":        true,
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write role: err=%v resp=%v", err, resp)
+	}
+
+	t.Run("Role read returns lease limits and ctx policy", func(t *testing.T) {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "roles/payment-risk-engine",
+			Storage:   storage,
+		})
+		if err != nil || resp == nil {
+			t.Fatalf("failed to read role: err=%v", err)
+		}
+		if resp.Data["ttl"] != int64(600) || resp.Data["max_ttl"] != int64(1800) {
+			t.Errorf("unexpected ttl/max_ttl: %v/%v", resp.Data["ttl"], resp.Data["max_ttl"])
+		}
+		if resp.Data["allowed_ctx_pattern"] != "tenant-*" || resp.Data["ctx_required"] != true {
+			t.Errorf("unexpected ctx policy: %v/%v", resp.Data["allowed_ctx_pattern"], resp.Data["ctx_required"])
+		}
+	})
+
+	readCreds := func(t *testing.T, ctxData string) *logical.Response {
+		t.Helper()
+		data := map[string]interface{}{}
+		if ctxData != "" {
+			data["ctx"] = ctxData
+		}
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.ReadOperation,
+			Path:       "creds/payment-risk-engine",
+			Storage:    storage,
+			Data:       data,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil {
+			t.Fatal("expected a response")
+		}
+		return resp
+	}
+
+	t.Run("Missing ctx is rejected", func(t *testing.T) {
+		resp := readCreds(t, "")
+		if !resp.IsError() || !strings.Contains(resp.Error().Error(), "ctx is required") {
+			t.Errorf("expected ctx required error, got: %v", resp.Error())
+		}
+	})
+
+	t.Run("Non-matching ctx is rejected", func(t *testing.T) {
+		resp := readCreds(t, "admin")
+		if !resp.IsError() || !strings.Contains(resp.Error().Error(), "allowed_ctx_pattern") {
+			t.Errorf("expected ctx pattern error, got: %v", resp.Error())
+		}
+	})
+
+	t.Run("Matching ctx gets a lease capped at the role ttl", func(t *testing.T) {
+		token := testIssuedToken(time.Now().Add(time.Hour))
+		token.Role = "payment-risk-engine"
+		backend.tokenCache.put(backend.tokenCache.currentEpoch(), "payment-risk-engine", "tenant-7", token)
+
+		resp := readCreds(t, "tenant-7")
+		if resp.IsError() {
+			t.Fatalf("unexpected error response: %v", resp.Error())
+		}
+		if resp.Secret == nil || resp.Secret.TTL != 10*time.Minute {
+			t.Errorf("expected lease TTL of 10m, got %v", resp.Secret)
+		}
+		if resp.Data["effective_ttl_seconds"] != int64(600) {
+			t.Errorf("expected effective_ttl_seconds 600, got %v", resp.Data["effective_ttl_seconds"])
+		}
+	})
+}
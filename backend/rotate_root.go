@@ -0,0 +1,400 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// walRotateRootKind is the WAL kind recorded while a root rotation is in flight
+const walRotateRootKind = "rotateRoot"
+
+// walRollbackMinAge is how old a WAL entry must be before the periodic rollback picks it up
+const walRollbackMinAge = 5 * time.Minute
+
+// Rotation triggers recorded in telemetry
+const (
+	rotationTriggerManual    = "manual"
+	rotationTriggerScheduled = "scheduled"
+)
+
+// minRotationPeriod is the shortest allowed scheduled rotation period
+const minRotationPeriod = time.Hour
+
+// scheduledRotationRetryInterval is how long the periodic worker waits after a failed scheduled rotation
+const scheduledRotationRetryInterval = 10 * time.Minute
+
+// rotateRootClockSkew is how far the management API's key creation times may run behind Vault's clock
+const rotateRootClockSkew = time.Minute
+
+// rotateRootWAL records an in-flight root rotation. Once the new key exists its
+// credentials are recorded too, so an interrupted rotation can be finished or undone.
+// Skyflow assigns the key ID, so a key created before it was recorded is found by StartedAt.
+type rotateRootWAL struct {
+	ClientID           string    `json:"client_id"`
+	OldKeyID           string    `json:"old_key_id"`
+	StartedAt          time.Time `json:"started_at"`
+	NewKeyID           string    `json:"new_key_id,omitempty"`
+	NewCredentialsJSON string    `json:"new_credentials_json,omitempty"`
+}
+
+// rotateRootResult describes the outcome of a root rotation
+type rotateRootResult struct {
+	Version       int
+	KeyID         string
+	PreviousKeyID string
+
+	// ValidationErr is set when the new key did not work and was revoked again
+	ValidationErr error
+}
+
+// rotateRoot replaces the mount's service account key with a new one created through the
+// management API. The caller must hold configLock. A non-nil result with a non-nil error means
+// the new key is in use but the old key could not be revoked yet; the WAL is kept to retry.
+func (b *skyflowBackend) rotateRoot(ctx context.Context, s logical.Storage, config *skyflowConfig) (*rotateRootResult, error) {
+	oldCreds, err := parseServiceAccountCredentials(config.CredentialsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	wal := &rotateRootWAL{
+		ClientID:  oldCreds.ClientID,
+		OldKeyID:  oldCreds.KeyID,
+		StartedAt: time.Now(),
+	}
+
+	walID, err := framework.PutWAL(ctx, s, walRotateRootKind, wal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write WAL: %w", err)
+	}
+
+	newCredentialsJSON, err := b.newManagementClient(config).createKey(ctx, config.CredentialsJSON)
+	if err != nil {
+		b.deleteRotateRootWAL(ctx, s, walID)
+		return nil, err
+	}
+
+	newCreds, err := parseServiceAccountCredentials(newCredentialsJSON)
+	if err != nil {
+		b.deleteRotateRootWAL(ctx, s, walID)
+		return nil, err
+	}
+
+	// Record the new key before using it so an interrupted rotation can be resumed
+	wal.NewKeyID = newCreds.KeyID
+	wal.NewCredentialsJSON = newCredentialsJSON
+
+	newWALID, err := framework.PutWAL(ctx, s, walRotateRootKind, wal)
+	if err != nil {
+		if revokeErr := b.newManagementClient(config).deleteKey(ctx, config.CredentialsJSON, newCreds.KeyID); revokeErr != nil {
+			b.Logger().Error("failed to revoke new service account key", "client_id", wal.ClientID, "key_id", newCreds.KeyID, "error", revokeErr)
+		}
+		b.deleteRotateRootWAL(ctx, s, walID)
+		return nil, fmt.Errorf("failed to write WAL: %w", err)
+	}
+	b.deleteRotateRootWAL(ctx, s, walID)
+
+	result, err := b.finishRootRotation(ctx, s, config, wal)
+	if err != nil {
+		return result, err
+	}
+
+	b.deleteRotateRootWAL(ctx, s, newWALID)
+	return result, nil
+}
+
+// finishRootRotation drives a rotation whose new key is recorded in wal to completion:
+// the new key is validated and stored, then the old key is revoked. If the new key does
+// not work it is revoked instead and the result carries the validation error.
+// A nil error means nothing is left to do for this WAL entry.
+func (b *skyflowBackend) finishRootRotation(ctx context.Context, s logical.Storage, config *skyflowConfig, wal *rotateRootWAL) (*rotateRootResult, error) {
+	current, err := parseServiceAccountCredentials(config.CredentialsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &rotateRootResult{
+		Version:       config.Version,
+		KeyID:         wal.NewKeyID,
+		PreviousKeyID: wal.OldKeyID,
+	}
+
+	client := b.newManagementClient(config)
+
+	if current.KeyID == wal.OldKeyID {
+		if _, err := b.managementToken(wal.NewCredentialsJSON); err != nil {
+			if revokeErr := client.deleteKey(ctx, config.CredentialsJSON, wal.NewKeyID); revokeErr != nil {
+				return nil, revokeErr
+			}
+			result.KeyID = wal.OldKeyID
+			result.ValidationErr = err
+			return result, nil
+		}
+
+		config.CredentialsJSON = wal.NewCredentialsJSON
+		config.CredentialsFilePath = ""
+		config.LastRotatedAt = time.Now()
+		if err := b.saveConfigWithHistory(ctx, s, config); err != nil {
+			return nil, err
+		}
+		result.Version = config.Version
+
+		// Tokens minted with the previous key must not be served
+		b.tokenCache.purge()
+		b.breakers.resetSet("")
+	} else if current.KeyID != wal.NewKeyID {
+		return nil, fmt.Errorf("config no longer uses key %s or %s", wal.OldKeyID, wal.NewKeyID)
+	}
+
+	if err := client.deleteKey(ctx, wal.NewCredentialsJSON, wal.OldKeyID); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// resumeRootRotation finishes or rolls back an interrupted root rotation recorded in wal
+func (b *skyflowBackend) resumeRootRotation(ctx context.Context, s logical.Storage, wal *rotateRootWAL) error {
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
+	config, err := b.getConfig(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if wal.NewCredentialsJSON == "" {
+		return b.revokeUnrecordedKeys(ctx, config, wal)
+	}
+
+	var current *serviceAccountCredentials
+	if config != nil && config.CredentialsJSON != "" {
+		current, _ = parseServiceAccountCredentials(config.CredentialsJSON)
+	}
+
+	if current == nil || (current.KeyID != wal.OldKeyID && current.KeyID != wal.NewKeyID) {
+		b.Logger().Warn("config changed during root rotation; revoke the unused key manually",
+			"client_id", wal.ClientID, "key_id", wal.NewKeyID)
+		return nil
+	}
+
+	result, err := b.finishRootRotation(ctx, s, config, wal)
+	if err != nil {
+		return err
+	}
+
+	if result.ValidationErr != nil {
+		b.Logger().Warn("rolled back interrupted root rotation", "client_id", wal.ClientID, "key_id", wal.NewKeyID, "error", result.ValidationErr)
+	} else {
+		b.Logger().Info("resumed interrupted root rotation", "client_id", wal.ClientID, "key_id", wal.NewKeyID, "version", result.Version)
+	}
+
+	return nil
+}
+
+// revokeUnrecordedKeys rolls back a root rotation interrupted before its new key was recorded:
+// every key the service account gained since the rotation started, other than the one the
+// config uses, is revoked
+func (b *skyflowBackend) revokeUnrecordedKeys(ctx context.Context, config *skyflowConfig, wal *rotateRootWAL) error {
+	var current *serviceAccountCredentials
+	if config != nil && config.CredentialsJSON != "" {
+		current, _ = parseServiceAccountCredentials(config.CredentialsJSON)
+	}
+
+	// WAL entries written before StartedAt was recorded cannot tell the new key apart
+	if wal.StartedAt.IsZero() || current == nil || current.ClientID != wal.ClientID {
+		b.Logger().Warn("root rotation was interrupted before the new key was recorded; revoke any extra keys on the service account manually",
+			"client_id", wal.ClientID, "key_id", wal.OldKeyID)
+		return nil
+	}
+
+	client := b.newManagementClient(config)
+	keys, err := client.listKeys(ctx, config.CredentialsJSON)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key.KeyID == wal.OldKeyID || key.KeyID == current.KeyID || key.CreatedAt.Before(wal.StartedAt.Add(-rotateRootClockSkew)) {
+			continue
+		}
+
+		if err := client.deleteKey(ctx, config.CredentialsJSON, key.KeyID); err != nil {
+			return err
+		}
+		b.Logger().Info("revoked key left by interrupted root rotation", "client_id", wal.ClientID, "key_id", key.KeyID)
+	}
+
+	return nil
+}
+
+// walRollback is called by Vault's rollback manager for WAL entries older than walRollbackMinAge
+func (b *skyflowBackend) walRollback(ctx context.Context, req *logical.Request, kind string, data interface{}) error {
+	if kind != walRotateRootKind {
+		return fmt.Errorf("unknown WAL kind %q", kind)
+	}
+
+	wal, err := decodeRotateRootWAL(data)
+	if err != nil {
+		return err
+	}
+
+	return b.resumeRootRotation(ctx, req.Storage, wal)
+}
+
+// resumeRootRotations processes every pending root rotation WAL entry regardless of age.
+// It runs when the mount is initialized, so no rotation can be in flight.
+func (b *skyflowBackend) resumeRootRotations(ctx context.Context, s logical.Storage) error {
+	ids, err := framework.ListWAL(ctx, s)
+	if err != nil {
+		return fmt.Errorf("failed to list WAL: %w", err)
+	}
+
+	for _, id := range ids {
+		entry, err := framework.GetWAL(ctx, s, id)
+		if err != nil {
+			return fmt.Errorf("failed to read WAL: %w", err)
+		}
+		if entry == nil || entry.Kind != walRotateRootKind {
+			continue
+		}
+
+		wal, err := decodeRotateRootWAL(entry.Data)
+		if err != nil {
+			return err
+		}
+
+		if err := b.resumeRootRotation(ctx, s, wal); err != nil {
+			return err
+		}
+
+		b.deleteRotateRootWAL(ctx, s, id)
+	}
+
+	return nil
+}
+
+// deleteRotateRootWAL removes a WAL entry, logging rather than failing on error
+func (b *skyflowBackend) deleteRotateRootWAL(ctx context.Context, s logical.Storage, id string) {
+	if err := framework.DeleteWAL(ctx, s, id); err != nil {
+		b.Logger().Warn("failed to delete WAL entry", "id", id, "error", err)
+	}
+}
+
+// decodeRotateRootWAL converts WAL data read from storage back into a rotateRootWAL
+func decodeRotateRootWAL(data interface{}) (*rotateRootWAL, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode WAL: %w", err)
+	}
+
+	wal := &rotateRootWAL{}
+	if err := json.Unmarshal(raw, wal); err != nil {
+		return nil, fmt.Errorf("failed to decode WAL: %w", err)
+	}
+
+	return wal, nil
+}
+
+// periodicFunc is called by Vault about once a minute. It rotates the root key when it is due
+// and drops expired entries from the revoked token denylist. Every node, including standbys
+// that serve reads, refreshes the tokens of prewarmed roles in its own cache.
+func (b *skyflowBackend) periodicFunc(ctx context.Context, req *logical.Request) error {
+	defer b.prewarmTokens(ctx, req)
+
+	if !b.canWriteStorage() {
+		return nil
+	}
+
+	if err := b.pruneRevokedTokens(ctx, req.Storage); err != nil {
+		b.Logger().Warn("failed to prune revoked tokens", "error", err)
+	}
+
+	return b.rotateRootIfDue(ctx, req.Storage)
+}
+
+// canWriteStorage reports whether this node may write the mount's replicated storage.
+// Performance standbys and secondaries forward writes, so only the active primary rotates.
+func (b *skyflowBackend) canWriteStorage() bool {
+	system := b.System()
+	if system == nil {
+		return true
+	}
+
+	state := system.ReplicationState()
+	if state.HasState(consts.ReplicationDRSecondary | consts.ReplicationPerformanceStandby) {
+		return false
+	}
+
+	return system.LocalMount() || !state.HasState(consts.ReplicationPerformanceSecondary)
+}
+
+// rotateRootIfDue runs a scheduled rotation if the config's rotation period has passed
+func (b *skyflowBackend) rotateRootIfDue(ctx context.Context, s logical.Storage) error {
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
+	now := time.Now()
+	if now.Before(b.scheduledRotationRetryAt) {
+		return nil
+	}
+
+	config, err := b.getConfig(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if config == nil {
+		return nil
+	}
+
+	next := config.nextRotationAt()
+	if next.IsZero() || now.Before(next) {
+		return nil
+	}
+
+	traces := b.traces()
+	ctx, span := traces.StartConfigRotateRoot(ctx, rotationTriggerScheduled)
+	defer span.End()
+
+	recordRotation := func(status string) {
+		if m := b.metrics(); m != nil {
+			m.RecordRootRotation(ctx, rotationTriggerScheduled, status)
+		}
+	}
+
+	result, err := b.rotateRoot(ctx, s, config)
+	switch {
+	case result == nil:
+		b.scheduledRotationRetryAt = now.Add(scheduledRotationRetryInterval)
+		traces.RecordConfigError(span, err)
+		recordRotation("failed")
+		return fmt.Errorf("scheduled root rotation failed: %w", err)
+	case result.ValidationErr != nil:
+		b.scheduledRotationRetryAt = now.Add(scheduledRotationRetryInterval)
+		traces.RecordConfigError(span, result.ValidationErr)
+		recordRotation("rolled_back")
+		return fmt.Errorf("scheduled root rotation rolled back, new key failed validation: %w", result.ValidationErr)
+	case err != nil:
+		// The new key is live; revoking the old key is retried from the WAL
+		b.Logger().Warn("failed to revoke previous service account key", "key_id", result.PreviousKeyID, "error", err)
+		recordRotation("revoke_pending")
+	default:
+		recordRotation("success")
+	}
+
+	traces.RecordConfigUpdated(span)
+
+	b.Logger().Info("scheduled service account key rotation complete",
+		"key_id", result.KeyID,
+		"previous_key_id", result.PreviousKeyID,
+		"version", result.Version,
+	)
+
+	return nil
+}
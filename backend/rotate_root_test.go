@@ -0,0 +1,561 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// fakeManagementAPI is a local stand-in for the Skyflow management API key endpoints
+type fakeManagementAPI struct {
+	mu        sync.Mutex
+	keys      map[string]bool
+	createdAt map[string]time.Time
+	nextKey   int
+
+	// rejectNewKeys makes newly created keys fail token validation
+	rejectNewKeys bool
+	created       map[string]bool
+}
+
+func newFakeManagementAPI(activeKeys ...string) *fakeManagementAPI {
+	api := &fakeManagementAPI{
+		keys:      map[string]bool{},
+		createdAt: map[string]time.Time{},
+		created:   map[string]bool{},
+	}
+	for _, key := range activeKeys {
+		api.keys[key] = true
+		api.createdAt[key] = time.Now().Add(-24 * time.Hour)
+	}
+	return api
+}
+
+func testCredentialsJSON(keyID string) string {
+	return fmt.Sprintf(`{"clientID":"sa-payment","keyID":%q,"privateKey":"fake","tokenURI":"https://example.invalid/token"}`, keyID)
+}
+
+// bearerToken stands in for the SDK: only active keys can mint tokens
+func (f *fakeManagementAPI) bearerToken(credentialsJSON string) (string, error) {
+	creds, err := parseServiceAccountCredentials(credentialsJSON)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.keys[creds.KeyID] || (f.rejectNewKeys && f.created[creds.KeyID]) {
+		return "", fmt.Errorf("key %s is not valid", creds.KeyID)
+	}
+	return "token-" + creds.KeyID, nil
+}
+
+func (f *fakeManagementAPI) activeKeys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var keys []string
+	for key := range f.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (f *fakeManagementAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer token-")
+	if !f.keys[token] {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	prefix := "/v1/serviceAccounts/sa-payment/keys"
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == prefix:
+		f.nextKey++
+		keyID := fmt.Sprintf("key-new-%d", f.nextKey)
+		f.keys[keyID] = true
+		f.createdAt[keyID] = time.Now()
+		f.created[keyID] = true
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testCredentialsJSON(keyID)))
+	case r.Method == http.MethodGet && r.URL.Path == prefix:
+		var keys []serviceAccountKey
+		for keyID := range f.keys {
+			keys = append(keys, serviceAccountKey{KeyID: keyID, CreatedAt: f.createdAt[keyID]})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, prefix+"/"):
+		keyID := strings.TrimPrefix(r.URL.Path, prefix+"/")
+		if !f.keys[keyID] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.keys, keyID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func setupRotateRootTest(t *testing.T) (*skyflowBackend, logical.Storage, *fakeManagementAPI) {
+	t.Helper()
+	return setupRotateRootTestWithSystem(t, &logical.StaticSystemView{})
+}
+
+func setupRotateRootTestWithSystem(t *testing.T, system logical.SystemView) (*skyflowBackend, logical.Storage, *fakeManagementAPI) {
+	t.Helper()
+
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	b, err := Factory(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      system,
+		StorageView: storage,
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	api := newFakeManagementAPI("key-old")
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	backend := b.(*skyflowBackend)
+	backend.managementToken = api.bearerToken
+
+	config := &skyflowConfig{
+		CredentialsJSON: testCredentialsJSON("key-old"),
+		ManagementURL:   server.URL,
+	}
+	if err := backend.saveConfigWithHistory(ctx, storage, config); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	return backend, storage, api
+}
+
+func currentKeyID(t *testing.T, b *skyflowBackend, s logical.Storage) string {
+	t.Helper()
+
+	creds, err := parseServiceAccountCredentials(mustConfig(t, b, s).CredentialsJSON)
+	if err != nil {
+		t.Fatalf("failed to parse credentials: %v", err)
+	}
+	return creds.KeyID
+}
+
+func TestRotateRoot(t *testing.T) {
+	ctx := context.Background()
+
+	request := func(b *skyflowBackend, s logical.Storage, op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation:  op,
+			Path:       path,
+			Storage:    s,
+			Data:       data,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+	}
+
+	t.Run("Rotates key and revokes the old one", func(t *testing.T) {
+		b, s, api := setupRotateRootTest(t)
+
+		resp, err := request(b, s, logical.UpdateOperation, "config/rotate-root", nil)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("rotate-root failed: err=%v resp=%v", err, resp)
+		}
+		if resp.Data["key_id"] != "key-new-1" || resp.Data["previous_key_id"] != "key-old" {
+			t.Errorf("unexpected response: %v", resp.Data)
+		}
+		if _, ok := resp.Data["credentials_json"]; ok {
+			t.Error("credentials must not be returned")
+		}
+
+		if got := currentKeyID(t, b, s); got != "key-new-1" {
+			t.Errorf("expected config to use key-new-1, got %s", got)
+		}
+		if keys := api.activeKeys(); len(keys) != 1 || keys[0] != "key-new-1" {
+			t.Errorf("expected only key-new-1 to be active, got %v", keys)
+		}
+
+		wals, _ := framework.ListWAL(ctx, s)
+		if len(wals) != 0 {
+			t.Errorf("expected no WAL entries, got %d", len(wals))
+		}
+	})
+
+	t.Run("New key failing validation is rolled back", func(t *testing.T) {
+		b, s, api := setupRotateRootTest(t)
+		api.rejectNewKeys = true
+
+		resp, err := request(b, s, logical.UpdateOperation, "config/rotate-root", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected error response")
+		}
+
+		if got := currentKeyID(t, b, s); got != "key-old" {
+			t.Errorf("expected config to keep key-old, got %s", got)
+		}
+		if keys := api.activeKeys(); len(keys) != 1 || keys[0] != "key-old" {
+			t.Errorf("expected only key-old to be active, got %v", keys)
+		}
+	})
+
+	t.Run("File path credentials are refused", func(t *testing.T) {
+		b, s, _ := setupRotateRootTest(t)
+
+		config, _ := b.getConfig(ctx, s)
+		config.CredentialsJSON = ""
+		config.CredentialsFilePath = "/etc/vault/creds/payment.json"
+		if err := b.saveConfig(ctx, s, config); err != nil {
+			t.Fatalf("failed to save config: %v", err)
+		}
+
+		resp, err := request(b, s, logical.UpdateOperation, "config/rotate-root", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatal("expected error response")
+		}
+	})
+
+	t.Run("Interrupted rotation resumes on initialize", func(t *testing.T) {
+		b, s, api := setupRotateRootTest(t)
+
+		// Simulate a crash after the new key was created and recorded
+		newCreds, err := b.newManagementClient(mustConfig(t, b, s)).createKey(ctx, testCredentialsJSON("key-old"))
+		if err != nil {
+			t.Fatalf("failed to create key: %v", err)
+		}
+		if _, err := framework.PutWAL(ctx, s, walRotateRootKind, &rotateRootWAL{
+			ClientID:           "sa-payment",
+			OldKeyID:           "key-old",
+			NewKeyID:           "key-new-1",
+			NewCredentialsJSON: newCreds,
+		}); err != nil {
+			t.Fatalf("failed to write WAL: %v", err)
+		}
+
+		if err := b.Initialize(ctx, &logical.InitializationRequest{Storage: s}); err != nil {
+			t.Fatalf("initialize failed: %v", err)
+		}
+
+		if got := currentKeyID(t, b, s); got != "key-new-1" {
+			t.Errorf("expected config to use key-new-1, got %s", got)
+		}
+		if keys := api.activeKeys(); len(keys) != 1 || keys[0] != "key-new-1" {
+			t.Errorf("expected only key-new-1 to be active, got %v", keys)
+		}
+		wals, _ := framework.ListWAL(ctx, s)
+		if len(wals) != 0 {
+			t.Errorf("expected no WAL entries, got %d", len(wals))
+		}
+	})
+
+	t.Run("Rotation interrupted before the new key was recorded is rolled back", func(t *testing.T) {
+		b, s, api := setupRotateRootTest(t)
+
+		// Simulate a crash after the new key was created but before it was recorded
+		startedAt := time.Now()
+		if _, err := b.newManagementClient(mustConfig(t, b, s)).createKey(ctx, testCredentialsJSON("key-old")); err != nil {
+			t.Fatalf("failed to create key: %v", err)
+		}
+		if _, err := framework.PutWAL(ctx, s, walRotateRootKind, &rotateRootWAL{
+			ClientID:  "sa-payment",
+			OldKeyID:  "key-old",
+			StartedAt: startedAt,
+		}); err != nil {
+			t.Fatalf("failed to write WAL: %v", err)
+		}
+
+		if err := b.Initialize(ctx, &logical.InitializationRequest{Storage: s}); err != nil {
+			t.Fatalf("initialize failed: %v", err)
+		}
+
+		if got := currentKeyID(t, b, s); got != "key-old" {
+			t.Errorf("expected config to keep key-old, got %s", got)
+		}
+		if keys := api.activeKeys(); len(keys) != 1 || keys[0] != "key-old" {
+			t.Errorf("expected only key-old to be active, got %v", keys)
+		}
+		wals, _ := framework.ListWAL(ctx, s)
+		if len(wals) != 0 {
+			t.Errorf("expected no WAL entries, got %d", len(wals))
+		}
+	})
+
+	t.Run("Interrupted rotation with bad key rolls back on periodic tick", func(t *testing.T) {
+		b, s, api := setupRotateRootTest(t)
+
+		newCreds, err := b.newManagementClient(mustConfig(t, b, s)).createKey(ctx, testCredentialsJSON("key-old"))
+		if err != nil {
+			t.Fatalf("failed to create key: %v", err)
+		}
+		api.rejectNewKeys = true
+		if _, err := framework.PutWAL(ctx, s, walRotateRootKind, &rotateRootWAL{
+			ClientID:           "sa-payment",
+			OldKeyID:           "key-old",
+			NewKeyID:           "key-new-1",
+			NewCredentialsJSON: newCreds,
+		}); err != nil {
+			t.Fatalf("failed to write WAL: %v", err)
+		}
+
+		resp, err := request(b, s, logical.RollbackOperation, "", map[string]interface{}{"immediate": true})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("rollback failed: err=%v resp=%v", err, resp)
+		}
+
+		if got := currentKeyID(t, b, s); got != "key-old" {
+			t.Errorf("expected config to keep key-old, got %s", got)
+		}
+		if keys := api.activeKeys(); len(keys) != 1 || keys[0] != "key-old" {
+			t.Errorf("expected only key-old to be active, got %v", keys)
+		}
+		wals, _ := framework.ListWAL(ctx, s)
+		if len(wals) != 0 {
+			t.Errorf("expected no WAL entries, got %d", len(wals))
+		}
+	})
+}
+
+func TestRotateRoot_Scheduled(t *testing.T) {
+	ctx := context.Background()
+
+	// schedule sets a rotation period and backdates the last rotation
+	schedule := func(t *testing.T, b *skyflowBackend, s logical.Storage, lastRotated time.Time) {
+		config := mustConfig(t, b, s)
+		config.RotationPeriod = 24 * time.Hour
+		config.LastRotatedAt = lastRotated
+		if err := b.saveConfig(ctx, s, config); err != nil {
+			t.Fatalf("failed to save config: %v", err)
+		}
+	}
+
+	tick := func(t *testing.T, b *skyflowBackend, s logical.Storage) {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.RollbackOperation,
+			Storage:   s,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("periodic tick failed: err=%v resp=%v", err, resp)
+		}
+	}
+
+	t.Run("Rotates once the period has passed", func(t *testing.T) {
+		b, s, api := setupRotateRootTest(t)
+		schedule(t, b, s, time.Now().Add(-25*time.Hour))
+
+		tick(t, b, s)
+
+		if got := currentKeyID(t, b, s); got != "key-new-1" {
+			t.Errorf("expected config to use key-new-1, got %s", got)
+		}
+		if keys := api.activeKeys(); len(keys) != 1 {
+			t.Errorf("expected one active key, got %v", keys)
+		}
+
+		config := mustConfig(t, b, s)
+		if time.Since(config.LastRotatedAt) > time.Minute {
+			t.Errorf("expected last_rotated_at to be updated, got %v", config.LastRotatedAt)
+		}
+		if next := config.nextRotationAt(); next.Before(time.Now().Add(23 * time.Hour)) {
+			t.Errorf("expected next rotation about a day away, got %v", next)
+		}
+	})
+
+	t.Run("Does nothing before the period has passed", func(t *testing.T) {
+		b, s, _ := setupRotateRootTest(t)
+		schedule(t, b, s, time.Now().Add(-time.Hour))
+
+		tick(t, b, s)
+
+		if got := currentKeyID(t, b, s); got != "key-old" {
+			t.Errorf("expected config to keep key-old, got %s", got)
+		}
+	})
+
+	t.Run("New credentials restart the schedule", func(t *testing.T) {
+		b, s, _ := setupRotateRootTest(t)
+		schedule(t, b, s, time.Now().Add(-25*time.Hour))
+
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "config",
+			Storage:   s,
+			Data: map[string]interface{}{
+				"credentials_json":     testCredentialsJSON("key-replaced"),
+				"validate_credentials": false,
+			},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write config: err=%v resp=%v", err, resp)
+		}
+
+		tick(t, b, s)
+
+		if got := currentKeyID(t, b, s); got != "key-replaced" {
+			t.Errorf("expected config to keep key-replaced, got %s", got)
+		}
+		if next := mustConfig(t, b, s).nextRotationAt(); next.Before(time.Now().Add(23 * time.Hour)) {
+			t.Errorf("expected next rotation about a day away, got %v", next)
+		}
+	})
+
+	t.Run("Rollback does not make a rotation due", func(t *testing.T) {
+		b, s, _ := setupRotateRootTest(t)
+
+		// Version 2 holds key-old with a last rotation that is long past
+		config := mustConfig(t, b, s)
+		config.RotationPeriod = 24 * time.Hour
+		config.LastRotatedAt = time.Now().Add(-25 * time.Hour)
+		if err := b.saveConfigWithHistory(ctx, s, config); err != nil {
+			t.Fatalf("failed to save config: %v", err)
+		}
+
+		// Version 3 holds a recently rotated key
+		config.CredentialsJSON = testCredentialsJSON("key-current")
+		config.LastRotatedAt = time.Now().Add(-time.Hour)
+		if err := b.saveConfigWithHistory(ctx, s, config); err != nil {
+			t.Fatalf("failed to save config: %v", err)
+		}
+
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "config/rollback",
+			Storage:   s,
+			Data: map[string]interface{}{
+				"version":              2,
+				"validate_credentials": false,
+			},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to roll back: err=%v resp=%v", err, resp)
+		}
+
+		tick(t, b, s)
+
+		if got := currentKeyID(t, b, s); got != "key-old" {
+			t.Errorf("expected config to keep the restored key-old, got %s", got)
+		}
+		if next := mustConfig(t, b, s).nextRotationAt(); next.Before(time.Now().Add(23 * time.Hour)) {
+			t.Errorf("expected next rotation about a day away, got %v", next)
+		}
+	})
+
+	t.Run("Skips on performance standby", func(t *testing.T) {
+		b, s, _ := setupRotateRootTestWithSystem(t, &logical.StaticSystemView{
+			ReplicationStateVal: consts.ReplicationPerformanceStandby,
+		})
+		schedule(t, b, s, time.Now().Add(-25*time.Hour))
+
+		tick(t, b, s)
+
+		if got := currentKeyID(t, b, s); got != "key-old" {
+			t.Errorf("expected config to keep key-old, got %s", got)
+		}
+	})
+
+	t.Run("Reports next_rotation_at", func(t *testing.T) {
+		b, s, _ := setupRotateRootTest(t)
+		lastRotated := time.Now().Add(-time.Hour).Truncate(time.Second)
+		schedule(t, b, s, lastRotated)
+		want := lastRotated.Add(24 * time.Hour).Format(time.RFC3339)
+
+		for _, path := range []string{"config", "health"} {
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation:  logical.ReadOperation,
+				Path:       path,
+				Storage:    s,
+				Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+			})
+			if err != nil || resp == nil {
+				t.Fatalf("failed to read %s: err=%v", path, err)
+			}
+			if resp.Data["next_rotation_at"] != want {
+				t.Errorf("%s: expected next_rotation_at %s, got %v", path, want, resp.Data["next_rotation_at"])
+			}
+		}
+	})
+}
+
+func TestConfig_ValidateRotationPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *skyflowConfig
+		wantError bool
+	}{
+		{
+			name:      "Disabled",
+			config:    &skyflowConfig{CredentialsJSON: `{"key": "value"}`},
+			wantError: false,
+		},
+		{
+			name:      "Valid period",
+			config:    &skyflowConfig{CredentialsJSON: `{"key": "value"}`, RotationPeriod: 30 * 24 * time.Hour},
+			wantError: false,
+		},
+		{
+			name:      "Below minimum",
+			config:    &skyflowConfig{CredentialsJSON: `{"key": "value"}`, RotationPeriod: time.Minute},
+			wantError: true,
+		},
+		{
+			name:      "File path credentials",
+			config:    &skyflowConfig{CredentialsFilePath: "/path/to/creds.json", RotationPeriod: 24 * time.Hour},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.validate()
+			if tt.wantError && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func mustConfig(t *testing.T, b *skyflowBackend, s logical.Storage) *skyflowConfig {
+	t.Helper()
+
+	config, err := b.getConfig(context.Background(), s)
+	if err != nil || config == nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	return config
+}
+
+func TestParseServiceAccountCredentials(t *testing.T) {
+	if _, err := parseServiceAccountCredentials(testCredentialsJSON("key-1")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	raw, _ := json.Marshal(map[string]string{"clientID": "sa-payment"})
+	if _, err := parseServiceAccountCredentials(string(raw)); err == nil {
+		t.Error("expected error for credentials without keyID")
+	}
+}
@@ -0,0 +1,300 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// secretTokenType is the Vault secret type for leased Skyflow bearer tokens
+const secretTokenType = "skyflow_token"
+
+// revokedTokenStoragePrefix is the storage prefix for the revoked token denylist
+const revokedTokenStoragePrefix = "revoked_tokens/"
+
+// minTokenLeaseTTL is the lease TTL of a token that is already at its expiry. Vault reads a
+// zero TTL as "use the mount default", which would let the lease outlive the token.
+const minTokenLeaseTTL = time.Second
+
+// revokedToken is a denylist entry for a token whose lease was revoked. Skyflow has no
+// token revocation API, so the token stays valid at Skyflow until it expires; the
+// denylist only stops this mount from serving it again.
+type revokedToken struct {
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// secretToken returns the secret type for leased Skyflow bearer tokens
+func secretToken(b *skyflowBackend) *framework.Secret {
+	return &framework.Secret{
+		Type: secretTokenType,
+		Fields: map[string]*framework.FieldSchema{
+			"access_token": {
+				Type:        framework.TypeString,
+				Description: "Skyflow bearer token",
+			},
+			"token_type": {
+				Type:        framework.TypeString,
+				Description: "Token type",
+			},
+		},
+
+		Renew:  b.secretTokenRenew,
+		Revoke: b.secretTokenRevoke,
+	}
+}
+
+// tokenResponse returns the creds/<role> response with a lease that ends when the token expires
+// or when the role's ttl runs out, whichever is sooner
+func (b *skyflowBackend) tokenResponse(token *issuedToken, role *skyflowRole) *logical.Response {
+	data := token.responseData()
+
+	// The role's limits are copied into the lease so renewals honour the role as it was at issue
+	resp := b.Secret(secretTokenType).Response(data, map[string]interface{}{
+		"role":              token.Role,
+		"token_fingerprint": token.fingerprint(),
+		"expires_at":        token.ExpiresAt.Unix(),
+		"role_ttl":          int64(role.TTL.Seconds()),
+		"role_max_ttl":      int64(role.MaxTTL.Seconds()),
+	})
+
+	tokenTTL := token.ttl()
+	ttl := role.leaseTTL(tokenTTL)
+	maxTTL := role.leaseMaxTTL(tokenTTL)
+	if !token.ExpiresAt.IsZero() && tokenTTL < minTokenLeaseTTL {
+		// leaseTTL reads a zero token TTL as "no expiry", so an expired token is capped here
+		ttl, maxTTL = minTokenLeaseTTL, minTokenLeaseTTL
+	}
+
+	if ttl > 0 {
+		resp.Secret.TTL = ttl
+		resp.Secret.MaxTTL = maxTTL
+		resp.Secret.InternalData["lease_expires_at"] = time.Now().Add(ttl).Unix()
+		data["effective_ttl_seconds"] = int64(ttl.Seconds())
+	}
+	resp.Secret.Renewable = !token.ExpiresAt.IsZero()
+
+	return resp
+}
+
+// secretTokenRenew extends a lease up to, but never past, the token's own expiry or the
+// role's max_ttl measured from when the lease was issued
+func (b *skyflowBackend) secretTokenRenew(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	fingerprint, _ := req.Secret.InternalData["token_fingerprint"].(string)
+	expiresAt, err := secretTokenExpiry(req.Secret.InternalData)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := b.getRevokedToken(ctx, req.Storage, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if revoked != nil {
+		return nil, fmt.Errorf("token was revoked")
+	}
+
+	tokenTTL := time.Until(expiresAt)
+	if expiresAt.IsZero() || tokenTTL <= 0 {
+		return nil, fmt.Errorf("token has expired and cannot be renewed")
+	}
+
+	role := &skyflowRole{
+		TTL:    secretDurationSeconds(req.Secret.InternalData, "role_ttl"),
+		MaxTTL: secretDurationSeconds(req.Secret.InternalData, "role_max_ttl"),
+	}
+
+	ttl := role.leaseTTL(tokenTTL)
+	maxTTL := role.leaseMaxTTL(tokenTTL)
+	if role.MaxTTL > 0 && !req.Secret.IssueTime.IsZero() {
+		// max_ttl bounds the whole lease, so the renewal only gets what is left of it
+		remaining := role.MaxTTL - time.Since(req.Secret.IssueTime)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("lease has reached the role's max_ttl and cannot be renewed")
+		}
+		if remaining < ttl {
+			ttl = remaining
+		}
+	}
+
+	resp := &logical.Response{Secret: req.Secret}
+	resp.Secret.TTL = ttl
+	resp.Secret.MaxTTL = maxTTL
+	resp.Secret.InternalData["lease_expires_at"] = time.Now().Add(ttl).Unix()
+
+	return resp, nil
+}
+
+// secretTokenRevoke denylists the token and evicts it from the token cache. Vault also calls
+// it when a lease runs out; that is not a revocation of the token, which other leases on the
+// role may share, so an expired lease is only let go.
+func (b *skyflowBackend) secretTokenRevoke(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	fingerprint, _ := req.Secret.InternalData["token_fingerprint"].(string)
+	roleName, _ := req.Secret.InternalData["role"].(string)
+	if fingerprint == "" {
+		return nil, nil
+	}
+
+	// Leases issued before lease_expires_at was recorded are treated as explicit revokes
+	if leaseExpiresAt := secretTimestamp(req.Secret.InternalData, "lease_expires_at"); !leaseExpiresAt.IsZero() && !time.Now().Before(leaseExpiresAt) {
+		b.Logger().Debug("token lease expired", "role", roleName)
+		return nil, nil
+	}
+
+	expiresAt, err := secretTokenExpiry(req.Secret.InternalData)
+	if err != nil {
+		return nil, err
+	}
+
+	traces := b.traces()
+	ctx, span := traces.StartTokenRevoke(ctx, roleName)
+	defer span.End()
+
+	// Expired tokens are useless to an attacker; nothing to denylist
+	if expiresAt.IsZero() || time.Now().Before(expiresAt) {
+		if err := b.saveRevokedToken(ctx, req.Storage, fingerprint, &revokedToken{
+			Role:      roleName,
+			ExpiresAt: expiresAt,
+			RevokedAt: time.Now(),
+		}); err != nil {
+			traces.RecordTokenRevokeFailed(span, err)
+			return nil, err
+		}
+	}
+
+	b.tokenCache.purgeToken(fingerprint)
+
+	if m := b.metrics(); m != nil {
+		m.RecordTokenRevoke(ctx, roleName)
+	}
+
+	traces.RecordTokenRevoked(span)
+	b.Logger().Info("token lease revoked", "role", roleName)
+
+	return nil, nil
+}
+
+// secretTimestamp reads a unix timestamp from a lease's internal data, or the zero time
+func secretTimestamp(internalData map[string]interface{}, key string) time.Time {
+	// Internal data round-trips through JSON, so numbers may come back as float64
+	switch v := internalData[key].(type) {
+	case int64:
+		return unixOrZero(v)
+	case float64:
+		return unixOrZero(int64(v))
+	case int:
+		return unixOrZero(int64(v))
+	default:
+		return time.Time{}
+	}
+}
+
+// secretTokenExpiry reads the token expiry recorded in a lease's internal data
+func secretTokenExpiry(internalData map[string]interface{}) (time.Time, error) {
+	raw, ok := internalData["expires_at"]
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	// Internal data round-trips through JSON, so numbers may come back as float64
+	switch v := raw.(type) {
+	case int64:
+		return unixOrZero(v), nil
+	case float64:
+		return unixOrZero(int64(v)), nil
+	case int:
+		return unixOrZero(int64(v)), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid expires_at in lease data: %v", raw)
+	}
+}
+
+// secretDurationSeconds reads a duration stored in seconds in a lease's internal data
+func secretDurationSeconds(internalData map[string]interface{}, key string) time.Duration {
+	// Internal data round-trips through JSON, so numbers may come back as float64
+	switch v := internalData[key].(type) {
+	case int64:
+		return time.Duration(v) * time.Second
+	case float64:
+		return time.Duration(v) * time.Second
+	case int:
+		return time.Duration(v) * time.Second
+	default:
+		return 0
+	}
+}
+
+// unixOrZero converts a unix timestamp, treating values <= 0 as "no expiry"
+func unixOrZero(sec int64) time.Time {
+	if sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// getRevokedToken returns the denylist entry for a token fingerprint
+func (b *skyflowBackend) getRevokedToken(ctx context.Context, s logical.Storage, fingerprint string) (*revokedToken, error) {
+	if fingerprint == "" {
+		return nil, nil
+	}
+
+	entry, err := s.Get(ctx, revokedTokenStoragePrefix+fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revoked token: %w", err)
+	}
+
+	if entry == nil {
+		return nil, nil
+	}
+
+	revoked := &revokedToken{}
+	if err := entry.DecodeJSON(revoked); err != nil {
+		return nil, fmt.Errorf("failed to decode revoked token: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// saveRevokedToken adds a token fingerprint to the denylist
+func (b *skyflowBackend) saveRevokedToken(ctx context.Context, s logical.Storage, fingerprint string, revoked *revokedToken) error {
+	entry, err := logical.StorageEntryJSON(revokedTokenStoragePrefix+fingerprint, revoked)
+	if err != nil {
+		return fmt.Errorf("failed to create storage entry: %w", err)
+	}
+
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("failed to save revoked token: %w", err)
+	}
+
+	return nil
+}
+
+// pruneRevokedTokens removes denylist entries for tokens that have expired
+func (b *skyflowBackend) pruneRevokedTokens(ctx context.Context, s logical.Storage) error {
+	fingerprints, err := s.List(ctx, revokedTokenStoragePrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list revoked tokens: %w", err)
+	}
+
+	now := time.Now()
+	for _, fingerprint := range fingerprints {
+		revoked, err := b.getRevokedToken(ctx, s, fingerprint)
+		if err != nil {
+			return err
+		}
+
+		if revoked == nil || revoked.ExpiresAt.IsZero() || now.Before(revoked.ExpiresAt) {
+			continue
+		}
+
+		if err := s.Delete(ctx, revokedTokenStoragePrefix+fingerprint); err != nil {
+			return fmt.Errorf("failed to delete revoked token: %w", err)
+		}
+	}
+
+	return nil
+}
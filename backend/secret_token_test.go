@@ -0,0 +1,172 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestSecretToken_Lease(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	config := &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	backend := b.(*skyflowBackend)
+
+	token := testIssuedToken(time.Now().Add(time.Hour))
+	token.Role = "payment-risk-engine"
+
+	t.Run("Lease TTL matches token expiry", func(t *testing.T) {
+		resp := backend.tokenResponse(token, &skyflowRole{})
+		if resp.Secret == nil {
+			t.Fatal("expected a lease")
+		}
+		if resp.Secret.TTL > time.Hour || resp.Secret.TTL < 59*time.Minute {
+			t.Errorf("expected TTL of about an hour, got %v", resp.Secret.TTL)
+		}
+		if resp.Secret.InternalData["secret_type"] != secretTokenType {
+			t.Errorf("unexpected secret type: %v", resp.Secret.InternalData["secret_type"])
+		}
+		if _, ok := resp.Secret.InternalData["access_token"]; ok {
+			t.Error("access token must not be stored in lease data")
+		}
+		if resp.Data["access_token"] != token.AccessToken {
+			t.Error("expected access_token in response data")
+		}
+	})
+
+	t.Run("Renew is capped at token expiry", func(t *testing.T) {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.RenewOperation,
+			Storage:   storage,
+			Secret:    backend.tokenResponse(token, &skyflowRole{}).Secret,
+		})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("renew failed: err=%v resp=%v", err, resp)
+		}
+		if resp.Secret.TTL > time.Hour {
+			t.Errorf("renewed TTL %v exceeds token expiry", resp.Secret.TTL)
+		}
+	})
+
+	t.Run("Renew is capped at the role's max_ttl", func(t *testing.T) {
+		secret := backend.tokenResponse(token, &skyflowRole{TTL: 10 * time.Minute, MaxTTL: 15 * time.Minute}).Secret
+		if secret.TTL != 10*time.Minute {
+			t.Fatalf("expected lease TTL of 10m, got %v", secret.TTL)
+		}
+
+		secret.IssueTime = time.Now().Add(-10 * time.Minute)
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.RenewOperation,
+			Storage:   storage,
+			Secret:    secret,
+		})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("renew failed: err=%v resp=%v", err, resp)
+		}
+		if resp.Secret.TTL > 5*time.Minute {
+			t.Errorf("renewed TTL %v exceeds what is left of max_ttl", resp.Secret.TTL)
+		}
+
+		secret.IssueTime = time.Now().Add(-20 * time.Minute)
+		if _, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.RenewOperation,
+			Storage:   storage,
+			Secret:    secret,
+		}); err == nil {
+			t.Error("expected renew past max_ttl to fail")
+		}
+	})
+
+	t.Run("Expired token gets the shortest lease", func(t *testing.T) {
+		expired := testIssuedToken(time.Now().Add(-time.Minute))
+		secret := backend.tokenResponse(expired, &skyflowRole{TTL: 10 * time.Minute}).Secret
+		if secret.TTL != minTokenLeaseTTL || secret.MaxTTL != minTokenLeaseTTL {
+			t.Errorf("expected a lease of %v, got TTL %v max_ttl %v", minTokenLeaseTTL, secret.TTL, secret.MaxTTL)
+		}
+	})
+
+	t.Run("Expired lease leaves the shared token alone", func(t *testing.T) {
+		backend.tokenCache.put(backend.tokenCache.currentEpoch(), token.Role, "", token)
+		secret := backend.tokenResponse(token, &skyflowRole{TTL: time.Minute}).Secret
+		secret.InternalData["lease_expires_at"] = time.Now().Add(-time.Second).Unix()
+
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.RevokeOperation,
+			Storage:   storage,
+			Secret:    secret,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("revoke failed: err=%v resp=%v", err, resp)
+		}
+
+		if _, ok := backend.tokenCache.get(token.Role, "", 0); !ok {
+			t.Error("expected the token to stay cached for other leases")
+		}
+		if keys, _ := storage.List(ctx, revokedTokenStoragePrefix); len(keys) != 0 {
+			t.Errorf("expected no denylist entry, got %v", keys)
+		}
+	})
+
+	t.Run("Revoke denylists and evicts the token", func(t *testing.T) {
+		backend.tokenCache.put(backend.tokenCache.currentEpoch(), token.Role, "", token)
+		secret := backend.tokenResponse(token, &skyflowRole{}).Secret
+
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.RevokeOperation,
+			Storage:   storage,
+			Secret:    secret,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("revoke failed: err=%v resp=%v", err, resp)
+		}
+
+		if _, ok := backend.tokenCache.get(token.Role, "", 0); ok {
+			t.Error("expected revoked token to be evicted from the cache")
+		}
+
+		revoked, err := backend.getRevokedToken(ctx, storage, token.fingerprint())
+		if err != nil || revoked == nil {
+			t.Fatalf("expected denylist entry: err=%v", err)
+		}
+
+		_, err = b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.RenewOperation,
+			Storage:   storage,
+			Secret:    secret,
+		})
+		if err == nil {
+			t.Error("expected renew of a revoked token to fail")
+		}
+	})
+
+	t.Run("Expired denylist entries are pruned", func(t *testing.T) {
+		if err := backend.saveRevokedToken(ctx, storage, "expired", &revokedToken{
+			Role:      token.Role,
+			ExpiresAt: time.Now().Add(-time.Minute),
+		}); err != nil {
+			t.Fatalf("failed to save revoked token: %v", err)
+		}
+
+		if err := backend.pruneRevokedTokens(ctx, storage); err != nil {
+			t.Fatalf("failed to prune: %v", err)
+		}
+
+		keys, _ := storage.List(ctx, revokedTokenStoragePrefix)
+		if len(keys) != 1 || keys[0] != token.fingerprint() {
+			t.Errorf("expected only the unexpired entry to remain, got %v", keys)
+		}
+	})
+}
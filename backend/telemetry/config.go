@@ -36,6 +36,24 @@ var otelEndpoints = map[string]struct {
 	},
 }
 
+// ============================================================================
+// OTLP Protocols
+// ============================================================================
+
+// OTLP transport protocols (OTEL_EXPORTER_OTLP_PROTOCOL values)
+const (
+	ProtocolHTTPProtobuf = "http/protobuf" // OTLP/HTTP, default port 4318
+	ProtocolGRPC         = "grpc"          // OTLP/gRPC, default port 4317
+)
+
+// defaultGRPCPort is used for gRPC endpoints given without a port
+const defaultGRPCPort = "4317"
+
+// ValidProtocol reports whether protocol is a supported OTLP transport
+func ValidProtocol(protocol string) bool {
+	return protocol == ProtocolHTTPProtobuf || protocol == ProtocolGRPC
+}
+
 // ============================================================================
 // BuildConfig - Unified config builder
 // ============================================================================
@@ -69,16 +87,21 @@ type ResolvedConfig struct {
 
 	// Traces configuration
 	TracesEndpoint string
+	TracesProtocol string
 	TracesHeaders  map[string]string
 	TracesInsecure bool
 	TracesTimeout  time.Duration
 
 	// Metrics configuration
 	MetricsEndpoint       string
+	MetricsProtocol       string
 	MetricsHeaders        map[string]string
 	MetricsInsecure       bool
 	MetricsExportInterval time.Duration
 
+	// PrometheusEnabled adds a pull reader whose metrics each mount serves on its metrics path
+	PrometheusEnabled bool
+
 	// Sample rate for traces (0.0 to 1.0)
 	SampleRate float64
 }
@@ -90,9 +113,14 @@ func (c *ResolvedConfig) IsTracesEnabled() bool {
 }
 
 // IsMetricsEnabled returns true if metrics should be active
-// Requires: master enabled + not NoOp + metrics endpoint available
+// Requires: master enabled + not NoOp + metrics endpoint or Prometheus reader available
 func (c *ResolvedConfig) IsMetricsEnabled() bool {
-	return c.Enabled && !c.UseNoOp && c.MetricsEndpoint != ""
+	return c.Enabled && !c.UseNoOp && (c.MetricsEndpoint != "" || c.PrometheusEnabled)
+}
+
+// IsPrometheusEnabled returns true if metrics should be served for Prometheus scrapes
+func (c *ResolvedConfig) IsPrometheusEnabled() bool {
+	return c.Enabled && !c.UseNoOp && c.PrometheusEnabled
 }
 
 // BuildConfig builds ResolvedConfig with the following priority (highest to lowest):
@@ -178,6 +206,14 @@ func BuildConfig(input BuildConfigInput) (*ResolvedConfig, error) {
 		}
 	}
 
+	// Traces protocol
+	// Priority: per-signal ENV > OTEL_EXPORTER_OTLP_PROTOCOL > http/protobuf
+	tracesProtocol, err := resolveProtocol("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if err != nil {
+		return nil, err
+	}
+	config.TracesProtocol = tracesProtocol
+
 	// Traces insecure (auto-detect from URL or ENV)
 	config.TracesInsecure = resolveBoolFlag(
 		nil,
@@ -202,13 +238,24 @@ func BuildConfig(input BuildConfigInput) (*ResolvedConfig, error) {
 		getDefaultMetricsEndpoint(config.Environment),
 	)
 
+	// Prometheus scrape reader (off by default, for clusters without an OTLP collector)
+	config.PrometheusEnabled = resolveBoolFlag(nil, "TELEMETRY_PROMETHEUS_ENABLED", false)
+
 	// Check TELEMETRY_METRICS_ENABLED to override
 	if val := os.Getenv("TELEMETRY_METRICS_ENABLED"); val != "" {
 		if strings.ToLower(val) == "false" || val == "0" {
 			config.MetricsEndpoint = "" // Disable metrics
+			config.PrometheusEnabled = false
 		}
 	}
 
+	// Metrics protocol
+	metricsProtocol, err := resolveProtocol("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL")
+	if err != nil {
+		return nil, err
+	}
+	config.MetricsProtocol = metricsProtocol
+
 	// Metrics insecure
 	config.MetricsInsecure = resolveBoolFlag(
 		nil,
@@ -232,6 +279,96 @@ func BuildConfig(input BuildConfigInput) (*ResolvedConfig, error) {
 	return config, nil
 }
 
+// ============================================================================
+// Overrides - per-mount settings merged over the environment
+// ============================================================================
+
+// Overrides are per-mount telemetry settings. Unset fields keep the environment-derived value;
+// a non-nil empty headers map sends no headers.
+type Overrides struct {
+	Enabled        *bool
+	TracesEnabled  *bool
+	MetricsEnabled *bool
+
+	TracesEndpoint  string
+	MetricsEndpoint string
+	TracesProtocol  string
+	MetricsProtocol string
+	TracesHeaders   map[string]string
+	MetricsHeaders  map[string]string
+
+	SampleRate            *float64
+	MetricsExportInterval time.Duration
+
+	PrometheusEnabled *bool
+}
+
+// WithOverrides returns a copy of the config with the overrides applied. RUNTIME_LOCAL (UseNoOp)
+// is not overridable so a developer's NoOp switch always wins.
+func (c *ResolvedConfig) WithOverrides(o *Overrides) *ResolvedConfig {
+	merged := *c
+	if o == nil {
+		return &merged
+	}
+
+	if o.Enabled != nil {
+		merged.Enabled = *o.Enabled
+	}
+
+	if o.TracesEndpoint != "" {
+		merged.TracesEndpoint = o.TracesEndpoint
+		merged.TracesInsecure = strings.HasPrefix(o.TracesEndpoint, "http://")
+	}
+	if o.TracesProtocol != "" {
+		merged.TracesProtocol = o.TracesProtocol
+	}
+	if o.TracesEnabled != nil {
+		if !*o.TracesEnabled {
+			merged.TracesEndpoint = "" // Disable traces
+		} else if merged.TracesEndpoint == "" {
+			// TELEMETRY_TRACES_ENABLED=false cleared the environment's endpoint; restore it
+			merged.TracesEndpoint = resolveStringValue("", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", getDefaultTracesEndpoint(c.Environment))
+			merged.TracesInsecure = strings.HasPrefix(merged.TracesEndpoint, "http://")
+		}
+	}
+	if o.TracesHeaders != nil {
+		merged.TracesHeaders = o.TracesHeaders
+	}
+
+	if o.MetricsEndpoint != "" {
+		merged.MetricsEndpoint = o.MetricsEndpoint
+		merged.MetricsInsecure = strings.HasPrefix(o.MetricsEndpoint, "http://")
+	}
+	if o.MetricsProtocol != "" {
+		merged.MetricsProtocol = o.MetricsProtocol
+	}
+	if o.PrometheusEnabled != nil {
+		merged.PrometheusEnabled = *o.PrometheusEnabled
+	}
+	if o.MetricsEnabled != nil {
+		if !*o.MetricsEnabled {
+			merged.MetricsEndpoint = "" // Disable metrics
+			merged.PrometheusEnabled = false
+		} else if merged.MetricsEndpoint == "" {
+			// TELEMETRY_METRICS_ENABLED=false cleared the environment's endpoint; restore it
+			merged.MetricsEndpoint = resolveStringValue("", "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", getDefaultMetricsEndpoint(c.Environment))
+			merged.MetricsInsecure = strings.HasPrefix(merged.MetricsEndpoint, "http://")
+		}
+	}
+	if o.MetricsHeaders != nil {
+		merged.MetricsHeaders = o.MetricsHeaders
+	}
+
+	if o.SampleRate != nil {
+		merged.SampleRate = clampSampleRate(*o.SampleRate)
+	}
+	if o.MetricsExportInterval > 0 {
+		merged.MetricsExportInterval = o.MetricsExportInterval
+	}
+
+	return &merged
+}
+
 // ============================================================================
 // Helper Functions - Default Endpoints
 // ============================================================================
@@ -313,6 +450,18 @@ func resolveHeaders(envVar string) map[string]string {
 	return headers
 }
 
+// resolveProtocol resolves an OTLP protocol with priority: signal ENV > OTEL_EXPORTER_OTLP_PROTOCOL > http/protobuf
+func resolveProtocol(signalEnvVar string) (string, error) {
+	protocol := strings.ToLower(resolveStringValue("", signalEnvVar, os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")))
+	if protocol == "" {
+		return ProtocolHTTPProtobuf, nil
+	}
+	if !ValidProtocol(protocol) {
+		return "", fmt.Errorf("unsupported OTLP protocol %q: must be %q or %q", protocol, ProtocolGRPC, ProtocolHTTPProtobuf)
+	}
+	return protocol, nil
+}
+
 // resolveDuration parses a duration string with fallback
 func resolveDuration(value string, defaultValue time.Duration) time.Duration {
 	if value == "" {
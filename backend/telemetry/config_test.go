@@ -252,6 +252,91 @@ func TestBuildConfig_MetricsDisabled(t *testing.T) {
 	}
 }
 
+func TestBuildConfig_Protocol(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantTraces  string
+		wantMetrics string
+		wantErr     bool
+	}{
+		{
+			name:        "Defaults to http/protobuf",
+			wantTraces:  ProtocolHTTPProtobuf,
+			wantMetrics: ProtocolHTTPProtobuf,
+		},
+		{
+			name:        "OTEL_EXPORTER_OTLP_PROTOCOL applies to both signals",
+			env:         map[string]string{"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc"},
+			wantTraces:  ProtocolGRPC,
+			wantMetrics: ProtocolGRPC,
+		},
+		{
+			name: "Per-signal protocol wins",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_PROTOCOL":         "grpc",
+				"OTEL_EXPORTER_OTLP_METRICS_PROTOCOL": "http/protobuf",
+			},
+			wantTraces:  ProtocolGRPC,
+			wantMetrics: ProtocolHTTPProtobuf,
+		},
+		{
+			name:    "Unsupported protocol",
+			env:     map[string]string{"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL": "http/json"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearTelemetryEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := BuildConfig(BuildConfigInput{ServiceName: "test-service", Environment: "dev"})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("BuildConfig() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildConfig() error = %v", err)
+			}
+
+			if cfg.TracesProtocol != tt.wantTraces {
+				t.Errorf("TracesProtocol = %q, want %q", cfg.TracesProtocol, tt.wantTraces)
+			}
+			if cfg.MetricsProtocol != tt.wantMetrics {
+				t.Errorf("MetricsProtocol = %q, want %q", cfg.MetricsProtocol, tt.wantMetrics)
+			}
+		})
+	}
+}
+
+func TestBuildConfig_Prometheus(t *testing.T) {
+	clearTelemetryEnv(t)
+	t.Setenv("TELEMETRY_PROMETHEUS_ENABLED", "true")
+
+	cfg, err := BuildConfig(BuildConfigInput{ServiceName: "test-service", Environment: "local"})
+	if err != nil {
+		t.Fatalf("BuildConfig() error = %v", err)
+	}
+	if !cfg.IsPrometheusEnabled() || !cfg.IsMetricsEnabled() {
+		t.Error("expected metrics enabled for Prometheus without a metrics endpoint")
+	}
+
+	t.Setenv("TELEMETRY_METRICS_ENABLED", "false")
+	cfg, err = BuildConfig(BuildConfigInput{ServiceName: "test-service", Environment: "local"})
+	if err != nil {
+		t.Fatalf("BuildConfig() error = %v", err)
+	}
+	if cfg.IsPrometheusEnabled() {
+		t.Error("expected TELEMETRY_METRICS_ENABLED=false to disable Prometheus")
+	}
+}
+
 func TestResolvedConfig_IsTracesEnabled(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -518,12 +603,16 @@ func clearTelemetryEnv(t *testing.T) {
 		"OTEL_EXPORTER_OTLP_HEADERS",
 		"OTEL_EXPORTER_OTLP_METRICS_HEADERS",
 		"OTEL_EXPORTER_OTLP_TIMEOUT",
+		"OTEL_EXPORTER_OTLP_PROTOCOL",
+		"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL",
+		"OTEL_EXPORTER_OTLP_METRICS_PROTOCOL",
 		"OTEL_SERVICE_NAME",
 		"SERVICE_NAMESPACE",
 		"TELEMETRY_ENABLED",
 		"TELEMETRY_TRACES_ENABLED",
 		"TELEMETRY_METRICS_ENABLED",
 		"TELEMETRY_METRICS_EXPORT_INTERVAL",
+		"TELEMETRY_PROMETHEUS_ENABLED",
 		"TELEMETRY_SAMPLE_RATE",
 		"RUNTIME_LOCAL",
 		"ENV",
@@ -532,3 +621,104 @@ func clearTelemetryEnv(t *testing.T) {
 		os.Unsetenv(env)
 	}
 }
+
+func TestResolvedConfig_WithOverrides(t *testing.T) {
+	base := &ResolvedConfig{
+		Enabled:               true,
+		TracesEndpoint:        "https://otel-dev.example.com/otlp/v1/traces",
+		MetricsEndpoint:       "https://otel-dev.example.com/otlp/v1/metrics",
+		TracesHeaders:         map[string]string{"x-env": "1"},
+		MetricsExportInterval: 60 * time.Second,
+		SampleRate:            1.0,
+	}
+
+	t.Run("Nil overrides copy the config", func(t *testing.T) {
+		merged := base.WithOverrides(nil)
+		if merged == base || merged.TracesEndpoint != base.TracesEndpoint {
+			t.Errorf("expected an equal copy, got %+v", merged)
+		}
+	})
+
+	t.Run("Set fields replace the environment", func(t *testing.T) {
+		rate := 0.1
+		merged := base.WithOverrides(&Overrides{
+			TracesEndpoint:        "http://collector:4318/v1/traces",
+			TracesHeaders:         map[string]string{"authorization": "token"},
+			SampleRate:            &rate,
+			MetricsExportInterval: 10 * time.Second,
+		})
+
+		if merged.TracesEndpoint != "http://collector:4318/v1/traces" || !merged.TracesInsecure {
+			t.Errorf("unexpected traces endpoint: %q insecure=%v", merged.TracesEndpoint, merged.TracesInsecure)
+		}
+		if merged.TracesHeaders["authorization"] != "token" {
+			t.Errorf("unexpected traces headers: %v", merged.TracesHeaders)
+		}
+		if merged.SampleRate != 0.1 || merged.MetricsExportInterval != 10*time.Second {
+			t.Errorf("unexpected sampling: rate=%v interval=%v", merged.SampleRate, merged.MetricsExportInterval)
+		}
+		if merged.MetricsEndpoint != base.MetricsEndpoint {
+			t.Errorf("expected metrics endpoint to be kept, got %q", merged.MetricsEndpoint)
+		}
+		if base.TracesEndpoint != "https://otel-dev.example.com/otlp/v1/traces" {
+			t.Error("expected the base config to be unchanged")
+		}
+	})
+
+	t.Run("Switches disable signals", func(t *testing.T) {
+		off := false
+		merged := base.WithOverrides(&Overrides{MetricsEnabled: &off})
+		if merged.IsMetricsEnabled() || !merged.IsTracesEnabled() {
+			t.Errorf("expected only metrics disabled, got traces=%v metrics=%v", merged.IsTracesEnabled(), merged.IsMetricsEnabled())
+		}
+
+		merged = base.WithOverrides(&Overrides{Enabled: &off})
+		if merged.IsTracesEnabled() || merged.IsMetricsEnabled() {
+			t.Error("expected the master switch to disable everything")
+		}
+	})
+
+	t.Run("Switches enable signals the environment turned off", func(t *testing.T) {
+		clearTelemetryEnv(t)
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://collector:4318/v1/traces")
+
+		off := *base
+		off.Environment = "dev"
+		off.TracesEndpoint = ""
+		off.MetricsEndpoint = ""
+
+		on := true
+		merged := off.WithOverrides(&Overrides{TracesEnabled: &on, MetricsEnabled: &on})
+		if merged.TracesEndpoint != "http://collector:4318/v1/traces" || !merged.TracesInsecure {
+			t.Errorf("expected the environment's traces endpoint, got %q insecure=%v", merged.TracesEndpoint, merged.TracesInsecure)
+		}
+		if merged.MetricsEndpoint != "https://otel-dev.example.com/otlp/v1/metrics" {
+			t.Errorf("expected the dev metrics endpoint, got %q", merged.MetricsEndpoint)
+		}
+
+		merged = off.WithOverrides(&Overrides{TracesEnabled: &on, TracesEndpoint: "https://mount-collector.example.com/v1/traces"})
+		if merged.TracesEndpoint != "https://mount-collector.example.com/v1/traces" {
+			t.Errorf("expected the override endpoint to win, got %q", merged.TracesEndpoint)
+		}
+	})
+
+	t.Run("Empty headers send none", func(t *testing.T) {
+		merged := base.WithOverrides(&Overrides{TracesHeaders: map[string]string{}})
+		if len(merged.TracesHeaders) != 0 {
+			t.Errorf("expected no traces headers, got %v", merged.TracesHeaders)
+		}
+
+		if merged := base.WithOverrides(&Overrides{}); merged.TracesHeaders["x-env"] != "1" {
+			t.Errorf("expected unset headers to keep the environment's, got %v", merged.TracesHeaders)
+		}
+	})
+
+	t.Run("RUNTIME_LOCAL cannot be overridden", func(t *testing.T) {
+		noop := *base
+		noop.UseNoOp = true
+		on := true
+		if merged := noop.WithOverrides(&Overrides{Enabled: &on}); merged.IsTracesEnabled() {
+			t.Error("expected NoOp to win over the enabled override")
+		}
+	})
+}
@@ -15,6 +15,8 @@ const TracerName = "github.com/sundhar-perumal/vault-plugin-secrets-skyflow"
 
 const (
 	SpanSkyflowPluginTokenGenerate = "SkyflowPlugin.Token.Generate"
+	SpanSkyflowPluginTokenRevoke   = "SkyflowPlugin.Token.Revoke"
+	SpanSkyflowPluginTokenSign     = "SkyflowPlugin.Token.Sign"
 	SpanSkyflowPluginSDKAuth       = "SkyflowPlugin.SDK.Auth"
 )
 
@@ -23,8 +25,22 @@ const (
 // ============================================================================
 
 const (
-	SpanSkyflowPluginConfigWrite = "SkyflowPlugin.Config.Write"
-	SpanSkyflowPluginConfigRead  = "SkyflowPlugin.Config.Read"
+	SpanSkyflowPluginConfigWrite    = "SkyflowPlugin.Config.Write"
+	SpanSkyflowPluginConfigRead     = "SkyflowPlugin.Config.Read"
+	SpanSkyflowPluginConfigHistory  = "SkyflowPlugin.Config.History"
+	SpanSkyflowPluginConfigRollback = "SkyflowPlugin.Config.Rollback"
+	SpanSkyflowPluginConfigRotate   = "SkyflowPlugin.Config.RotateRoot"
+)
+
+// ============================================================================
+// Span Names - Credential Set Operations
+// ============================================================================
+
+const (
+	SpanSkyflowPluginCredentialsWrite  = "SkyflowPlugin.Credentials.Write"
+	SpanSkyflowPluginCredentialsRead   = "SkyflowPlugin.Credentials.Read"
+	SpanSkyflowPluginCredentialsList   = "SkyflowPlugin.Credentials.List"
+	SpanSkyflowPluginCredentialsDelete = "SkyflowPlugin.Credentials.Delete"
 )
 
 // ============================================================================
@@ -62,11 +78,16 @@ const (
 	// Token events
 	EventTokenGenerated = "token.generated"
 	EventTokenFailed    = "token.failed"
+	EventTokenRevoked   = "token.revoked"
+	EventTokenSigned    = "token.signed"
+	EventTokenStale     = "token.served_stale"
 
 	// SDK auth events
 	EventSDKAuthStart   = "sdk.auth.start"
 	EventSDKAuthSuccess = "sdk.auth.success"
 	EventSDKAuthFailed  = "sdk.auth.failed"
+	EventSDKAuthRetry   = "sdk.auth.retry"
+	EventSDKAuthShared  = "sdk.auth.shared"
 
 	// Config events
 	EventConfigUpdated = "config.updated"
@@ -89,18 +110,33 @@ var (
 	AttrRole           = attribute.Key("skyflow.role")
 	AttrCredentialType = attribute.Key("credential_type")
 	AttrRoleIDsCount   = attribute.Key("role_ids_count")
+	AttrCacheHit       = attribute.Key("cache_hit")
+	AttrCredentialSet  = attribute.Key("skyflow.credential_set")
+
+	// Signed data token attributes
+	AttrDataTokensCount = attribute.Key("data_tokens_count")
 
 	// Operation attributes
-	AttrOperation = attribute.Key("operation")
-	AttrFound     = attribute.Key("found")
+	AttrOperation     = attribute.Key("operation")
+	AttrFound         = attribute.Key("found")
+	AttrConfigVersion = attribute.Key("config_version")
+
+	// Rotation attributes
+	AttrRotationTrigger = attribute.Key("rotation.trigger")
 
 	// Error attributes
 	AttrErrorOperation = attribute.Key("error.operation")
 	AttrErrorSeverity  = attribute.Key("error.severity")
 	AttrErrorMessage   = attribute.Key("error.message")
+	AttrErrorType      = attribute.Key("error.type")
 
 	// Duration and status
 	AttrDurationMs    = attribute.Key("duration_ms")
 	AttrSDKDurationMs = attribute.Key("sdk_duration_ms")
 	AttrSuccess       = attribute.Key("success")
+
+	// Retry attributes
+	AttrRetryAttempt = attribute.Key("retry.attempt")
+	AttrRetryDelayMs = attribute.Key("retry.delay_ms")
+	AttrRetryReason  = attribute.Key("retry.reason")
 )
\ No newline at end of file
@@ -4,20 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ============================================================================
@@ -31,6 +37,10 @@ type Providers struct {
 	traces          *TracesProvider
 	metrics         *MetricsProvider
 	config          *ResolvedConfig
+
+	// Prometheus registry of the pipeline and the mount whose series are served from it
+	prometheus prometheus.Gatherer
+	scope      MountScope
 }
 
 // Init initializes telemetry with traces and metrics using BuildConfigInput.
@@ -61,52 +71,119 @@ func InitWithConfig(ctx context.Context, cfg *ResolvedConfig) (*Providers, func(
 		return nil, func(ctx context.Context) error { return nil }, nil
 	}
 
-	providers := &Providers{config: cfg}
+	p, err := newPipeline(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.installGlobals()
+
+	providers, err := p.scoped(MountScope{})
+	if err != nil {
+		_ = p.shutdown(ctx)
+		return nil, nil, err
+	}
+
+	return providers, p.shutdown, nil
+}
+
+// pipeline is a set of exporting tracer and meter providers
+type pipeline struct {
+	config         *ResolvedConfig
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+
+	// Registry the Prometheus reader exposes; nil unless PrometheusEnabled
+	prometheus *prometheus.Registry
+}
+
+// newPipeline builds the exporters for an enabled config
+func newPipeline(ctx context.Context, cfg *ResolvedConfig) (*pipeline, error) {
+	p := &pipeline{config: cfg}
 
 	// Initialize TracerProvider
 	if cfg.IsTracesEnabled() {
 		tp, err := setupTracerProvider(ctx, cfg)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to setup tracer provider: %w", err)
+			return nil, fmt.Errorf("failed to setup tracer provider: %w", err)
 		}
-		providers.tracerProvider = tp
-		providers.traces = newTracesProvider(true)
-		otel.SetTracerProvider(tp)
-		otel.SetTextMapPropagator(propagation.TraceContext{})
+		p.tracerProvider = tp
 	}
 
-	// Initialize MetricsProvider
+	// Initialize MeterProvider
 	if cfg.IsMetricsEnabled() {
-		mp, metrics, err := setupMetricsProvider(ctx, cfg)
+		mp, registry, err := setupMetricsProvider(ctx, cfg)
 		if err != nil {
 			// Cleanup tracer if metrics fail
-			if providers.tracerProvider != nil {
-				_ = providers.tracerProvider.Shutdown(ctx)
+			if p.tracerProvider != nil {
+				_ = p.tracerProvider.Shutdown(ctx)
 			}
-			return nil, nil, fmt.Errorf("failed to setup metrics provider: %w", err)
+			return nil, fmt.Errorf("failed to setup metrics provider: %w", err)
+		}
+		p.meterProvider = mp
+		p.prometheus = registry
+	}
+
+	return p, nil
+}
+
+// installGlobals makes the pipeline's providers the OTel globals used by instrumented libraries,
+// and installs the W3C trace context propagator
+func (p *pipeline) installGlobals() {
+	if p.tracerProvider != nil {
+		otel.SetTracerProvider(p.tracerProvider)
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	}
+	if p.meterProvider != nil {
+		otel.SetMeterProvider(p.meterProvider)
+	}
+}
+
+// scoped returns Providers whose tracer and meter carry the scope's attributes
+func (p *pipeline) scoped(scope MountScope) (*Providers, error) {
+	providers := &Providers{
+		tracerProvider:  p.tracerProvider,
+		metricsProvider: p.meterProvider,
+		config:          p.config,
+		scope:           scope,
+	}
+
+	if p.prometheus != nil {
+		providers.prometheus = p.prometheus
+	}
+
+	if p.tracerProvider != nil {
+		providers.traces = newTracesProviderFromTracer(p.tracerProvider.Tracer(TracerName,
+			trace.WithInstrumentationAttributes(scope.attributes()...),
+		))
+	}
+
+	if p.meterProvider != nil {
+		metrics, err := newMetricsProviderFromResolved(p.meterProvider, p.config,
+			metric.WithInstrumentationAttributes(scope.attributes()...),
+		)
+		if err != nil {
+			return nil, err
 		}
-		providers.metricsProvider = mp
 		providers.metrics = metrics
-		otel.SetMeterProvider(mp)
 	}
 
-	// Return shutdown function
-	shutdown := func(ctx context.Context) error {
-		var errs []error
-		if providers.tracerProvider != nil {
-			if err := providers.tracerProvider.Shutdown(ctx); err != nil {
-				errs = append(errs, fmt.Errorf("tracer shutdown: %w", err))
-			}
+	return providers, nil
+}
+
+// shutdown flushes and stops the pipeline's exporters
+func (p *pipeline) shutdown(ctx context.Context) error {
+	var errs []error
+	if p.tracerProvider != nil {
+		if err := p.tracerProvider.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("tracer shutdown: %w", err))
 		}
-		if providers.metricsProvider != nil {
-			if err := providers.metricsProvider.Shutdown(ctx); err != nil {
-				errs = append(errs, fmt.Errorf("metrics shutdown: %w", err))
-			}
+	}
+	if p.meterProvider != nil {
+		if err := p.meterProvider.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("metrics shutdown: %w", err))
 		}
-		return errors.Join(errs...)
 	}
-
-	return providers, shutdown, nil
+	return errors.Join(errs...)
 }
 
 // Metrics returns the MetricsProvider for recording metrics
@@ -135,9 +212,7 @@ func (p *Providers) IsEnabled() bool {
 // ============================================================================
 
 func setupTracerProvider(ctx context.Context, cfg *ResolvedConfig) (*sdktrace.TracerProvider, error) {
-	opts := buildTracerExporterOptions(cfg)
-
-	exporter, err := otlptracehttp.New(ctx, opts...)
+	exporter, err := newTracesExporter(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OTLP traces exporter: %w", err)
 	}
@@ -154,44 +229,80 @@ func setupTracerProvider(ctx context.Context, cfg *ResolvedConfig) (*sdktrace.Tr
 	return tp, nil
 }
 
-func setupMetricsProvider(ctx context.Context, cfg *ResolvedConfig) (*sdkmetric.MeterProvider, *MetricsProvider, error) {
-	opts := buildMetricsExporterOptions(cfg)
+func setupMetricsProvider(ctx context.Context, cfg *ResolvedConfig) (*sdkmetric.MeterProvider, *prometheus.Registry, error) {
+	res := buildResource(cfg)
+	opts := []sdkmetric.Option{sdkmetric.WithResource(res)}
 
-	exporter, err := otlpmetrichttp.New(ctx, opts...)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create OTLP metrics exporter: %w", err)
-	}
+	// Push to the OTLP collector
+	if cfg.MetricsEndpoint != "" {
+		exporter, err := newMetricsExporter(ctx, cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create OTLP metrics exporter: %w", err)
+		}
 
-	res := buildResource(cfg)
+		exportInterval := cfg.MetricsExportInterval
+		if exportInterval == 0 {
+			exportInterval = 60 * time.Second
+		}
 
-	exportInterval := cfg.MetricsExportInterval
-	if exportInterval == 0 {
-		exportInterval = 60 * time.Second
+		opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter,
+			sdkmetric.WithInterval(exportInterval),
+		)))
 	}
 
-	reader := sdkmetric.NewPeriodicReader(exporter,
-		sdkmetric.WithInterval(exportInterval),
-	)
+	// Serve Prometheus scrapes
+	var registry *prometheus.Registry
+	if cfg.PrometheusEnabled {
+		reader, reg, err := newPrometheusReader()
+		if err != nil {
+			return nil, nil, err
+		}
+		registry = reg
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
 
-	mp := sdkmetric.NewMeterProvider(
-		sdkmetric.WithResource(res),
-		sdkmetric.WithReader(reader),
-	)
+	return sdkmetric.NewMeterProvider(opts...), registry, nil
+}
 
-	// Create MetricsProvider wrapper for recording
-	metrics, err := newMetricsProviderFromResolved(mp, cfg)
+// newTracesExporter builds the OTLP span exporter for the configured protocol
+func newTracesExporter(ctx context.Context, cfg *ResolvedConfig) (sdktrace.SpanExporter, error) {
+	if cfg.TracesProtocol == ProtocolGRPC {
+		exporter, err := otlptracegrpc.New(ctx, buildTracerGRPCExporterOptions(cfg)...)
+		if err != nil {
+			return nil, err
+		}
+		return exporter, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, buildTracerExporterOptions(cfg)...)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	return exporter, nil
+}
 
-	return mp, metrics, nil
+// newMetricsExporter builds the OTLP metric exporter for the configured protocol
+func newMetricsExporter(ctx context.Context, cfg *ResolvedConfig) (sdkmetric.Exporter, error) {
+	if cfg.MetricsProtocol == ProtocolGRPC {
+		exporter, err := otlpmetricgrpc.New(ctx, buildMetricsGRPCExporterOptions(cfg)...)
+		if err != nil {
+			return nil, err
+		}
+		return exporter, nil
+	}
+
+	exporter, err := otlpmetrichttp.New(ctx, buildMetricsExporterOptions(cfg)...)
+	if err != nil {
+		return nil, err
+	}
+	return exporter, nil
 }
 
 func buildTracerExporterOptions(cfg *ResolvedConfig) []otlptracehttp.Option {
 	var opts []otlptracehttp.Option
 
 	if cfg.TracesEndpoint != "" {
-		endpoint, urlPath, useInsecure := parseEndpointURL(cfg.TracesEndpoint)
+		endpoint, urlPath, useInsecure := parseEndpointURL(cfg.TracesEndpoint, ProtocolHTTPProtobuf)
 		opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
 
 		if urlPath != "" && urlPath != "/v1/traces" {
@@ -214,7 +325,7 @@ func buildMetricsExporterOptions(cfg *ResolvedConfig) []otlpmetrichttp.Option {
 	var opts []otlpmetrichttp.Option
 
 	if cfg.MetricsEndpoint != "" {
-		endpoint, urlPath, useInsecure := parseEndpointURL(cfg.MetricsEndpoint)
+		endpoint, urlPath, useInsecure := parseEndpointURL(cfg.MetricsEndpoint, ProtocolHTTPProtobuf)
 		opts = append(opts, otlpmetrichttp.WithEndpoint(endpoint))
 
 		if urlPath != "" && urlPath != "/v1/metrics" {
@@ -233,6 +344,46 @@ func buildMetricsExporterOptions(cfg *ResolvedConfig) []otlpmetrichttp.Option {
 	return opts
 }
 
+func buildTracerGRPCExporterOptions(cfg *ResolvedConfig) []otlptracegrpc.Option {
+	var opts []otlptracegrpc.Option
+
+	if cfg.TracesEndpoint != "" {
+		endpoint, _, useInsecure := parseEndpointURL(cfg.TracesEndpoint, ProtocolGRPC)
+		opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+
+		// Without WithInsecure the exporter uses TLS with the system roots
+		if useInsecure || cfg.TracesInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+	}
+
+	// Sent as gRPC metadata
+	if len(cfg.TracesHeaders) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(cfg.TracesHeaders))
+	}
+
+	return opts
+}
+
+func buildMetricsGRPCExporterOptions(cfg *ResolvedConfig) []otlpmetricgrpc.Option {
+	var opts []otlpmetricgrpc.Option
+
+	if cfg.MetricsEndpoint != "" {
+		endpoint, _, useInsecure := parseEndpointURL(cfg.MetricsEndpoint, ProtocolGRPC)
+		opts = append(opts, otlpmetricgrpc.WithEndpoint(endpoint))
+
+		if useInsecure || cfg.MetricsInsecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+	}
+
+	if len(cfg.MetricsHeaders) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(cfg.MetricsHeaders))
+	}
+
+	return opts
+}
+
 func buildResource(cfg *ResolvedConfig) *resource.Resource {
 	attrs := []attribute.KeyValue{
 		semconv.ServiceName(cfg.ServiceName),
@@ -257,22 +408,31 @@ func buildSampler(sampleRate float64) sdktrace.Sampler {
 	return sdktrace.TraceIDRatioBased(sampleRate)
 }
 
-func parseEndpointURL(rawURL string) (endpoint string, urlPath string, useInsecure bool) {
-	if !strings.Contains(rawURL, "://") {
-		return rawURL, "", false
-	}
+// parseEndpointURL splits an OTLP endpoint into the host:port, URL path and scheme-derived
+// insecure flag the exporters take. gRPC has no URL path and a bare host defaults to port 4317,
+// so both "collector:4317" and "https://collector" work for gRPC collectors.
+func parseEndpointURL(rawURL string, protocol string) (endpoint string, urlPath string, useInsecure bool) {
+	endpoint = rawURL
+	if strings.Contains(rawURL, "://") {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return rawURL, "", false
+		}
 
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return rawURL, "", false
+		endpoint = parsed.Host
+		urlPath = parsed.Path
+		if urlPath == "/" {
+			urlPath = ""
+		}
+		useInsecure = parsed.Scheme == "http"
 	}
 
-	endpoint = parsed.Host
-	urlPath = parsed.Path
-	if urlPath == "/" {
+	if protocol == ProtocolGRPC {
 		urlPath = ""
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			endpoint = net.JoinHostPort(strings.Trim(endpoint, "[]"), defaultGRPCPort)
+		}
 	}
-	useInsecure = parsed.Scheme == "http"
 
 	return endpoint, urlPath, useInsecure
 }
@@ -289,18 +449,25 @@ func logTelemetryStatus(cfg *ResolvedConfig) {
 	}
 
 	if !cfg.Enabled {
-		logInfof("disabled (TELEMETRY_ENABLED=false)")
+		logInfof("disabled (TELEMETRY_ENABLED=false or config/telemetry enabled=false)")
 		return
 	}
 
 	tracesStatus := "off"
 	if cfg.IsTracesEnabled() {
-		tracesStatus = cfg.TracesEndpoint
+		tracesStatus = cfg.TracesEndpoint + " (" + protocolName(cfg.TracesProtocol) + ")"
 	}
 
 	metricsStatus := "off"
-	if cfg.IsMetricsEnabled() {
-		metricsStatus = cfg.MetricsEndpoint
+	if cfg.IsMetricsEnabled() && cfg.MetricsEndpoint != "" {
+		metricsStatus = cfg.MetricsEndpoint + " (" + protocolName(cfg.MetricsProtocol) + ")"
+	}
+	if cfg.IsPrometheusEnabled() {
+		if metricsStatus == "off" {
+			metricsStatus = "prometheus"
+		} else {
+			metricsStatus += " + prometheus"
+		}
 	}
 
 	logInfof("enabled (env=%s, traces=%s, metrics=%s, sample_rate=%.2f)",
@@ -311,6 +478,14 @@ func logTelemetryStatus(cfg *ResolvedConfig) {
 	)
 }
 
+// protocolName returns the protocol for logging; configs built by hand may leave it empty
+func protocolName(protocol string) string {
+	if protocol == "" {
+		return ProtocolHTTPProtobuf
+	}
+	return protocol
+}
+
 // ============================================================================
 // Trace Context Extraction (W3C traceparent)
 // ============================================================================
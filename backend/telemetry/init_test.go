@@ -0,0 +1,171 @@
+package telemetry
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// grpcCollector is an in-process OTLP/gRPC collector that records what it receives
+type grpcCollector struct {
+	collectortrace.UnimplementedTraceServiceServer
+
+	mu            sync.Mutex
+	spans         int
+	metrics       int
+	authorization []string
+}
+
+func (c *grpcCollector) Export(ctx context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			c.spans += len(ss.GetSpans())
+		}
+	}
+	c.recordHeaders(ctx)
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+// metricsService adapts the collector to the metrics service, whose Export has a different signature
+type metricsService struct {
+	collectormetrics.UnimplementedMetricsServiceServer
+	collector *grpcCollector
+}
+
+func (s metricsService) Export(ctx context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	s.collector.mu.Lock()
+	defer s.collector.mu.Unlock()
+
+	for _, rm := range req.GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			s.collector.metrics += len(sm.GetMetrics())
+		}
+	}
+	s.collector.recordHeaders(ctx)
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+// recordHeaders keeps the authorization metadata of a request; callers hold mu
+func (c *grpcCollector) recordHeaders(ctx context.Context) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		c.authorization = append(c.authorization, md.Get("authorization")...)
+	}
+}
+
+// startGRPCCollector serves a grpcCollector on a local port and returns it with its address
+func startGRPCCollector(t *testing.T) (*grpcCollector, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	collector := &grpcCollector{}
+	server := grpc.NewServer()
+	collectortrace.RegisterTraceServiceServer(server, collector)
+	collectormetrics.RegisterMetricsServiceServer(server, metricsService{collector: collector})
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return collector, listener.Addr().String()
+}
+
+func TestParseEndpointURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		rawURL       string
+		protocol     string
+		wantEndpoint string
+		wantPath     string
+		wantInsecure bool
+	}{
+		{"HTTP URL with path", "https://otel.example.com/otlp/v1/traces", ProtocolHTTPProtobuf, "otel.example.com", "/otlp/v1/traces", false},
+		{"HTTP plaintext URL", "http://collector:4318/v1/metrics", ProtocolHTTPProtobuf, "collector:4318", "/v1/metrics", true},
+		{"HTTP bare host", "collector:4318", ProtocolHTTPProtobuf, "collector:4318", "", false},
+		{"gRPC host and port", "collector:4317", ProtocolGRPC, "collector:4317", "", false},
+		{"gRPC bare host gets the default port", "collector", ProtocolGRPC, "collector:4317", "", false},
+		{"gRPC URL drops the path", "https://otel.example.com/otlp/v1/traces", ProtocolGRPC, "otel.example.com:4317", "", false},
+		{"gRPC plaintext URL", "http://127.0.0.1:4317", ProtocolGRPC, "127.0.0.1:4317", "", true},
+		{"gRPC IPv6 host", "[::1]", ProtocolGRPC, "[::1]:4317", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint, urlPath, insecure := parseEndpointURL(tt.rawURL, tt.protocol)
+			if endpoint != tt.wantEndpoint || urlPath != tt.wantPath || insecure != tt.wantInsecure {
+				t.Errorf("parseEndpointURL(%q, %q) = (%q, %q, %v), want (%q, %q, %v)",
+					tt.rawURL, tt.protocol, endpoint, urlPath, insecure, tt.wantEndpoint, tt.wantPath, tt.wantInsecure)
+			}
+		})
+	}
+}
+
+func TestNewPipeline_GRPC(t *testing.T) {
+	collector, addr := startGRPCCollector(t)
+	ctx := context.Background()
+
+	cfg := &ResolvedConfig{
+		Enabled:         true,
+		ServiceName:     "test-service",
+		ServiceVersion:  "1.0.0",
+		Environment:     "dev",
+		TracesEndpoint:  addr,
+		TracesProtocol:  ProtocolGRPC,
+		TracesInsecure:  true,
+		TracesHeaders:   map[string]string{"authorization": "Bearer collector-token"},
+		MetricsEndpoint: "http://" + addr,
+		MetricsProtocol: ProtocolGRPC,
+		MetricsHeaders:  map[string]string{"authorization": "Bearer collector-token"},
+		SampleRate:      1.0,
+	}
+
+	p, err := newPipeline(ctx, cfg)
+	if err != nil {
+		t.Fatalf("newPipeline() error = %v", err)
+	}
+	defer p.shutdown(ctx)
+
+	providers, err := p.scoped(MountScope{BackendUUID: "order-uuid"})
+	if err != nil {
+		t.Fatalf("scoped() error = %v", err)
+	}
+
+	_, span := providers.Traces().StartTokenGenerate(ctx, "order-processor")
+	span.End()
+	providers.Metrics().RecordTokenGenerate(ctx, "order-processor", "order-service", "order-vault", 12, true)
+
+	if err := p.tracerProvider.ForceFlush(ctx); err != nil {
+		t.Fatalf("traces flush error = %v", err)
+	}
+	if err := p.meterProvider.ForceFlush(ctx); err != nil {
+		t.Fatalf("metrics flush error = %v", err)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	if collector.spans != 1 {
+		t.Errorf("collector received %d spans, want 1", collector.spans)
+	}
+	if collector.metrics == 0 {
+		t.Error("expected the collector to receive metrics")
+	}
+	for _, value := range collector.authorization {
+		if value != "Bearer collector-token" {
+			t.Errorf("unexpected authorization metadata %q", value)
+		}
+	}
+	if len(collector.authorization) < 2 {
+		t.Errorf("expected headers on both exports, got %v", collector.authorization)
+	}
+}
@@ -28,6 +28,18 @@ type MetricsProvider struct {
 	healthChecksTotal   metric.Int64Counter
 	sdkCallTotal        metric.Int64Counter
 	sdkCallErrors       metric.Int64Counter
+	sdkCallRetries      metric.Int64Counter
+	tokenCacheHits      metric.Int64Counter
+	tokenCacheMisses    metric.Int64Counter
+	tokensServedStale   metric.Int64Counter
+	tokenPrewarms       metric.Int64Counter
+	configRollbacks     metric.Int64Counter
+	rootRotations       metric.Int64Counter
+	tokenRevocations    metric.Int64Counter
+	dataTokenSigns      metric.Int64Counter
+
+	// Gauges
+	circuitBreakerState metric.Int64Gauge
 
 	// Histograms
 	tokenGenerateDuration metric.Float64Histogram
@@ -38,11 +50,12 @@ type MetricsProvider struct {
 	startTime time.Time
 }
 
-// newMetricsProviderFromResolved creates a MetricsProvider from an existing MeterProvider using ResolvedConfig
-func newMetricsProviderFromResolved(mp *sdkmetric.MeterProvider, cfg *ResolvedConfig) (*MetricsProvider, error) {
+// newMetricsProviderFromResolved creates a MetricsProvider from an existing MeterProvider using ResolvedConfig.
+// opts are added to the meter's options, e.g. to scope it to a mount.
+func newMetricsProviderFromResolved(mp *sdkmetric.MeterProvider, cfg *ResolvedConfig, opts ...metric.MeterOption) (*MetricsProvider, error) {
 	meter := mp.Meter(
 		TracerName,
-		metric.WithInstrumentationVersion("1.0.0"),
+		append([]metric.MeterOption{metric.WithInstrumentationVersion("1.0.0")}, opts...)...,
 	)
 
 	p := &MetricsProvider{
@@ -163,6 +176,97 @@ func (p *MetricsProvider) initMetrics() error {
 		return err
 	}
 
+	p.sdkCallRetries, err = p.meter.Int64Counter(
+		"skyflow_sdk_call_retries_total",
+		metric.WithDescription("Total number of retried Skyflow SDK calls"),
+		metric.WithUnit("{retry}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	p.tokenCacheHits, err = p.meter.Int64Counter(
+		"skyflow_token_cache_hits_total",
+		metric.WithDescription("Total number of token requests served from the token cache"),
+		metric.WithUnit("{hit}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	p.tokensServedStale, err = p.meter.Int64Counter(
+		"skyflow_tokens_served_stale_total",
+		metric.WithDescription("Total number of previously issued tokens served because a new token could not be minted"),
+		metric.WithUnit("{token}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	p.tokenPrewarms, err = p.meter.Int64Counter(
+		"skyflow_token_prewarms_total",
+		metric.WithDescription("Total number of background token refreshes for prewarmed roles"),
+		metric.WithUnit("{refresh}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	p.tokenCacheMisses, err = p.meter.Int64Counter(
+		"skyflow_token_cache_misses_total",
+		metric.WithDescription("Total number of token requests not served from the token cache"),
+		metric.WithUnit("{miss}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	p.configRollbacks, err = p.meter.Int64Counter(
+		"skyflow_config_rollbacks_total",
+		metric.WithDescription("Total number of config rollbacks to a previous version"),
+		metric.WithUnit("{rollback}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	p.rootRotations, err = p.meter.Int64Counter(
+		"skyflow_root_rotations_total",
+		metric.WithDescription("Total number of service account key rotations"),
+		metric.WithUnit("{rotation}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	p.tokenRevocations, err = p.meter.Int64Counter(
+		"skyflow_token_revocations_total",
+		metric.WithDescription("Total number of token leases revoked"),
+		metric.WithUnit("{revocation}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	p.dataTokenSigns, err = p.meter.Int64Counter(
+		"skyflow_data_token_signs_total",
+		metric.WithDescription("Total number of signed data token requests"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	// === GAUGES ===
+
+	p.circuitBreakerState, err = p.meter.Int64Gauge(
+		"skyflow_circuit_breaker_state",
+		metric.WithDescription("Token endpoint circuit breaker state per mount and credential set (0 closed, 1 half-open, 2 open)"),
+	)
+	if err != nil {
+		return err
+	}
+
 	// === HISTOGRAMS ===
 
 	p.tokenGenerateDuration, err = p.meter.Float64Histogram(
@@ -214,6 +318,22 @@ func (p *MetricsProvider) RecordTokenGenerate(ctx context.Context, role, vaultSe
 	p.tokenGenerateDuration.Record(ctx, durationMs, attrs)
 }
 
+// RecordDataTokenSign records a signed data token request
+func (p *MetricsProvider) RecordDataTokenSign(ctx context.Context, role, vaultServiceName, skyflowVaultName string, success bool) {
+	if !p.IsEnabled() {
+		return
+	}
+
+	p.dataTokenSigns.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("role", role),
+			attribute.String("vault_service_name", vaultServiceName),
+			attribute.String("skyflow_vault_name", skyflowVaultName),
+			attribute.Bool("success", success),
+		),
+	)
+}
+
 // RecordTokenError records a token generation error
 func (p *MetricsProvider) RecordTokenError(ctx context.Context, role, vaultServiceName, skyflowVaultName, errorType string) {
 	if !p.IsEnabled() {
@@ -257,6 +377,33 @@ func (p *MetricsProvider) RecordRoleWrite(ctx context.Context, role, operation s
 	)
 }
 
+// RecordConfigRollback records a config rollback attempt
+func (p *MetricsProvider) RecordConfigRollback(ctx context.Context, status string) {
+	if !p.IsEnabled() {
+		return
+	}
+
+	p.configRollbacks.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("status", status),
+		),
+	)
+}
+
+// RecordRootRotation records a manual or scheduled service account key rotation attempt
+func (p *MetricsProvider) RecordRootRotation(ctx context.Context, trigger, status string) {
+	if !p.IsEnabled() {
+		return
+	}
+
+	p.rootRotations.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("trigger", trigger),
+			attribute.String("status", status),
+		),
+	)
+}
+
 // RecordConfigError records a config error
 func (p *MetricsProvider) RecordConfigError(ctx context.Context, operation, errorType string) {
 	if !p.IsEnabled() {
@@ -353,4 +500,106 @@ func (p *MetricsProvider) RecordSkyflowSDKCallError(ctx context.Context, roleNam
 			attribute.String("error_type", errorType),
 		),
 	)
-}
\ No newline at end of file
+}
+
+// RecordSkyflowSDKCallRetry records a Skyflow SDK call that is retried after a transient error
+func (p *MetricsProvider) RecordSkyflowSDKCallRetry(ctx context.Context, roleName string, attempt int) {
+	if !p.IsEnabled() {
+		return
+	}
+
+	p.sdkCallRetries.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("role", roleName),
+			attribute.Int("attempt", attempt),
+		),
+	)
+}
+
+// RecordCircuitBreakerState records the token endpoint circuit breaker state of a mount's
+// credential set; credentialSet is empty for the mount config's credentials
+func (p *MetricsProvider) RecordCircuitBreakerState(ctx context.Context, skyflowVaultName, credentialSet string, state int) {
+	if !p.IsEnabled() {
+		return
+	}
+
+	p.circuitBreakerState.Record(ctx, int64(state),
+		metric.WithAttributes(
+			attribute.String("skyflow_vault_name", skyflowVaultName),
+			attribute.String("credential_set", credentialSet),
+		),
+	)
+}
+
+// RecordTokenCacheHit records a token request served from the token cache
+func (p *MetricsProvider) RecordTokenCacheHit(ctx context.Context, role, vaultServiceName, skyflowVaultName string) {
+	if !p.IsEnabled() {
+		return
+	}
+
+	p.tokenCacheHits.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("role", role),
+			attribute.String("vault_service_name", vaultServiceName),
+			attribute.String("skyflow_vault_name", skyflowVaultName),
+		),
+	)
+}
+
+// RecordTokenServedStale records a previously issued token served because minting a new one failed
+func (p *MetricsProvider) RecordTokenServedStale(ctx context.Context, role, vaultServiceName, skyflowVaultName string) {
+	if !p.IsEnabled() {
+		return
+	}
+
+	p.tokensServedStale.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("role", role),
+			attribute.String("vault_service_name", vaultServiceName),
+			attribute.String("skyflow_vault_name", skyflowVaultName),
+		),
+	)
+}
+
+// RecordTokenPrewarm records a background token refresh for a prewarmed role
+func (p *MetricsProvider) RecordTokenPrewarm(ctx context.Context, role, skyflowVaultName string, success bool) {
+	if !p.IsEnabled() {
+		return
+	}
+
+	p.tokenPrewarms.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("role", role),
+			attribute.String("skyflow_vault_name", skyflowVaultName),
+			attribute.Bool("success", success),
+		),
+	)
+}
+
+// RecordTokenRevoke records a token lease revocation
+func (p *MetricsProvider) RecordTokenRevoke(ctx context.Context, role string) {
+	if !p.IsEnabled() {
+		return
+	}
+
+	p.tokenRevocations.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("role", role),
+		),
+	)
+}
+
+// RecordTokenCacheMiss records a token request that required a new Skyflow token
+func (p *MetricsProvider) RecordTokenCacheMiss(ctx context.Context, role, vaultServiceName, skyflowVaultName string) {
+	if !p.IsEnabled() {
+		return
+	}
+
+	p.tokenCacheMisses.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("role", role),
+			attribute.String("vault_service_name", vaultServiceName),
+			attribute.String("skyflow_vault_name", skyflowVaultName),
+		),
+	)
+}
\ No newline at end of file
@@ -0,0 +1,92 @@
+package telemetry
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+)
+
+// ============================================================================
+// Prometheus Scrape Output
+// ============================================================================
+
+// PrometheusContentType is the content type of PrometheusMetrics output
+var PrometheusContentType = string(expfmt.NewFormat(expfmt.TypeTextPlain))
+
+// prometheusScopeLabel is the label the Prometheus exporter gives the AttrMountUUID scope attribute
+var prometheusScopeLabel = "otel_scope_" + strings.ReplaceAll(AttrMountUUID, ".", "_")
+
+// newPrometheusReader returns a pull reader backed by its own registry, so pipelines in the same
+// process (and the plugin SDK's default registry) never collide. Instrument names already carry
+// their unit (e.g. _duration_ms), so no unit suffix is added.
+func newPrometheusReader() (*otelprom.Exporter, *prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+
+	exporter, err := otelprom.New(
+		otelprom.WithRegisterer(registry),
+		otelprom.WithoutUnits(),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Prometheus exporter: %w", err)
+	}
+
+	return exporter, registry, nil
+}
+
+// PrometheusEnabled returns whether PrometheusMetrics can serve a scrape
+func (p *Providers) PrometheusEnabled() bool {
+	return p != nil && p.prometheus != nil
+}
+
+// PrometheusMetrics returns the mount's metrics in the Prometheus text format. Mounts share
+// pipelines, so only series recorded under this mount's scope (plus target_info) are included.
+func (p *Providers) PrometheusMetrics() ([]byte, error) {
+	if !p.PrometheusEnabled() {
+		return nil, fmt.Errorf("prometheus metrics are not enabled")
+	}
+
+	families, err := p.prometheus.Gather()
+	if err != nil {
+		return nil, fmt.Errorf("failed to gather metrics: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, family := range families {
+		family.Metric = mountSeries(family.GetMetric(), p.scope.BackendUUID)
+		if len(family.Metric) == 0 {
+			continue
+		}
+		if _, err := expfmt.MetricFamilyToText(&buf, family); err != nil {
+			return nil, fmt.Errorf("failed to encode metrics: %w", err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// mountSeries keeps the series of a mount; series without a mount label are not mount-specific
+func mountSeries(series []*dto.Metric, backendUUID string) []*dto.Metric {
+	if backendUUID == "" {
+		return series
+	}
+
+	kept := series[:0]
+	for _, s := range series {
+		keep := true
+		for _, label := range s.GetLabel() {
+			if label.GetName() == prometheusScopeLabel {
+				keep = label.GetValue() == backendUUID
+				break
+			}
+		}
+		if keep {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
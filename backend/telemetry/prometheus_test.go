@@ -0,0 +1,80 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProviders_PrometheusMetrics(t *testing.T) {
+	clearTelemetryEnv(t)
+	t.Setenv("TELEMETRY_PROMETHEUS_ENABLED", "true")
+	ctx := context.Background()
+
+	// No OTLP collector for the environment: metrics are only served for scrapes
+	input := BuildConfigInput{ServiceName: "test-service", ServiceVersion: "1.0.0", Environment: "local"}
+
+	order, releaseOrder, err := Acquire(ctx, input, MountScope{BackendUUID: "order-uuid"})
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer releaseOrder(ctx)
+
+	payment, releasePayment, err := Acquire(ctx, input, MountScope{BackendUUID: "payment-uuid"})
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer releasePayment(ctx)
+
+	order.Metrics().RecordTokenGenerate(ctx, "order-processor", "order-service", "order-vault", 12, true)
+	payment.Metrics().RecordTokenGenerate(ctx, "payment-risk-engine", "payment-service", "payment-vault", 15, true)
+
+	t.Run("Only the mount's series are served", func(t *testing.T) {
+		body, err := order.PrometheusMetrics()
+		if err != nil {
+			t.Fatalf("PrometheusMetrics() error = %v", err)
+		}
+		text := string(body)
+
+		if !strings.Contains(text, "skyflow_tokens_generated_total") || !strings.Contains(text, `role="order-processor"`) {
+			t.Errorf("expected the mount's token metrics, got:\n%s", text)
+		}
+		if !strings.Contains(text, `otel_scope_vault_mount_uuid="order-uuid"`) {
+			t.Errorf("expected series labelled with the mount UUID, got:\n%s", text)
+		}
+		if !strings.Contains(text, "skyflow_token_generated_duration_ms_bucket") {
+			t.Errorf("expected histogram names without a unit suffix, got:\n%s", text)
+		}
+		if strings.Contains(text, "payment-uuid") || strings.Contains(text, "payment-risk-engine") {
+			t.Errorf("expected no series of another mount, got:\n%s", text)
+		}
+	})
+
+	t.Run("Tracing stays off without an endpoint", func(t *testing.T) {
+		if order.Traces() != nil {
+			t.Error("expected no traces provider without a traces endpoint")
+		}
+	})
+}
+
+func TestProviders_PrometheusDisabled(t *testing.T) {
+	useCollector(t)
+
+	providers, release, err := Acquire(context.Background(), BuildConfigInput{Environment: "dev"}, MountScope{})
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer release(context.Background())
+
+	if providers.PrometheusEnabled() {
+		t.Error("expected Prometheus disabled by default")
+	}
+	if _, err := providers.PrometheusMetrics(); err == nil {
+		t.Error("expected an error when Prometheus is disabled")
+	}
+
+	var nilProviders *Providers
+	if nilProviders.PrometheusEnabled() {
+		t.Error("expected nil providers to report Prometheus disabled")
+	}
+}
@@ -0,0 +1,171 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ============================================================================
+// Process-wide Registry
+// ============================================================================
+
+// AttrMountUUID tags the tracer and meter of a mount with its backend UUID
+const AttrMountUUID = "vault.mount.uuid"
+
+// MountScope identifies the mount a set of Providers records for
+type MountScope struct {
+	// BackendUUID is the UUID Vault assigns the mount (logical.BackendConfig.BackendUUID)
+	BackendUUID string
+}
+
+// attributes returns the instrumentation scope attributes of the mount
+func (s MountScope) attributes() []attribute.KeyValue {
+	if s.BackendUUID == "" {
+		return nil
+	}
+	return []attribute.KeyValue{attribute.String(AttrMountUUID, s.BackendUUID)}
+}
+
+// sharedPipeline is a pipeline and the number of mounts using it
+type sharedPipeline struct {
+	*pipeline
+	refs int
+}
+
+// registry holds the telemetry pipelines of the plugin process. Mounts with the same resolved
+// config share one pipeline; a mount whose config/telemetry changes its settings gets its own.
+// The first live pipeline also provides the OTel globals.
+var registry struct {
+	mu        sync.Mutex
+	pipelines map[string]*sharedPipeline
+	globals   *sharedPipeline
+}
+
+// Acquire resolves the config from input and the environment and returns Providers for a mount.
+// See AcquireWithConfig.
+func Acquire(ctx context.Context, input BuildConfigInput, scope MountScope) (*Providers, func(context.Context) error, error) {
+	cfg, err := BuildConfig(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return AcquireWithConfig(ctx, cfg, scope)
+}
+
+// AcquireWithConfig returns Providers for a mount backed by the process-wide pipeline for cfg,
+// building it on first use. The returned release function must be called once when the mount
+// is cleaned up or switches config; a pipeline is shut down when its last mount releases it.
+// If telemetry is disabled or UseNoOp, returns nil providers - OTEL uses built-in noop.
+func AcquireWithConfig(ctx context.Context, cfg *ResolvedConfig, scope MountScope) (*Providers, func(context.Context) error, error) {
+	noop := func(ctx context.Context) error { return nil }
+	if cfg.UseNoOp || !cfg.Enabled {
+		logTelemetryStatus(cfg)
+		return nil, noop, nil
+	}
+
+	key, err := pipelineKey(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.pipelines == nil {
+		registry.pipelines = make(map[string]*sharedPipeline)
+	}
+
+	shared, ok := registry.pipelines[key]
+	if !ok {
+		logTelemetryStatus(cfg)
+
+		p, err := newPipeline(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		shared = &sharedPipeline{pipeline: p}
+	}
+
+	providers, err := shared.scoped(scope)
+	if err != nil {
+		if !ok {
+			_ = shared.shutdown(ctx)
+		}
+		return nil, nil, err
+	}
+
+	if !ok {
+		registry.pipelines[key] = shared
+		if registry.globals == nil {
+			registry.globals = shared
+			shared.installGlobals()
+		}
+	}
+	shared.refs++
+
+	var once sync.Once
+	release := func(ctx context.Context) error {
+		var err error
+		once.Do(func() {
+			err = releasePipeline(ctx, key)
+		})
+		return err
+	}
+
+	return providers, release, nil
+}
+
+// releasePipeline drops one reference to a pipeline and shuts it down with the last
+func releasePipeline(ctx context.Context, key string) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	shared, ok := registry.pipelines[key]
+	if !ok {
+		return nil
+	}
+
+	shared.refs--
+	if shared.refs > 0 {
+		return nil
+	}
+
+	delete(registry.pipelines, key)
+
+	// Hand the OTel globals to a pipeline that is still running
+	if registry.globals == shared {
+		registry.globals = nil
+		for _, next := range registry.pipelines {
+			registry.globals = next
+			next.installGlobals()
+			break
+		}
+	}
+
+	return shared.shutdown(ctx)
+}
+
+// pipelineKey identifies the exporters a config needs, so equal configs share a pipeline
+func pipelineKey(cfg *ResolvedConfig) (string, error) {
+	key, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode telemetry config: %w", err)
+	}
+	return string(key), nil
+}
+
+// activeMounts returns how many mounts hold a pipeline
+func activeMounts() int {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	mounts := 0
+	for _, shared := range registry.pipelines {
+		mounts += shared.refs
+	}
+	return mounts
+}
@@ -0,0 +1,146 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// useCollector points the OTLP exporters at a local collector that accepts everything
+func useCollector(t *testing.T) {
+	t.Helper()
+	clearTelemetryEnv(t)
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(collector.Close)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", collector.URL+"/v1/traces")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", collector.URL+"/v1/metrics")
+}
+
+func TestAcquire_SharedPipeline(t *testing.T) {
+	useCollector(t)
+	ctx := context.Background()
+	input := BuildConfigInput{ServiceName: "test-service", ServiceVersion: "1.0.0", Environment: "dev"}
+
+	order, releaseOrder, err := Acquire(ctx, input, MountScope{BackendUUID: "order-uuid"})
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	payment, releasePayment, err := Acquire(ctx, input, MountScope{BackendUUID: "payment-uuid"})
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	t.Run("Mounts share exporters", func(t *testing.T) {
+		if order.tracerProvider != payment.tracerProvider || order.metricsProvider != payment.metricsProvider {
+			t.Error("expected both mounts to use the same tracer and meter providers")
+		}
+		if order.Metrics() == payment.Metrics() || order.Traces() == payment.Traces() {
+			t.Error("expected each mount to get its own scoped providers")
+		}
+		if activeMounts() != 2 {
+			t.Errorf("activeMounts() = %d, want 2", activeMounts())
+		}
+	})
+
+	t.Run("Releasing one mount keeps the pipeline", func(t *testing.T) {
+		if err := releaseOrder(ctx); err != nil {
+			t.Fatalf("release error = %v", err)
+		}
+		// A second release of the same mount is a no-op
+		if err := releaseOrder(ctx); err != nil {
+			t.Fatalf("release error = %v", err)
+		}
+
+		if activeMounts() != 1 {
+			t.Errorf("activeMounts() = %d, want 1", activeMounts())
+		}
+
+		// The remaining mount can still record
+		_, span := payment.Traces().StartTokenGenerate(ctx, "payment-risk-engine")
+		if !span.SpanContext().IsValid() {
+			t.Error("expected a recording span after another mount was released")
+		}
+		span.End()
+	})
+
+	t.Run("Last release shuts down the pipeline", func(t *testing.T) {
+		if err := releasePayment(ctx); err != nil {
+			t.Fatalf("release error = %v", err)
+		}
+
+		if activeMounts() != 0 {
+			t.Errorf("activeMounts() = %d, want 0", activeMounts())
+		}
+
+		next, releaseNext, err := Acquire(ctx, input, MountScope{BackendUUID: "order-uuid"})
+		if err != nil {
+			t.Fatalf("Acquire() error = %v", err)
+		}
+		defer releaseNext(ctx)
+
+		if next.tracerProvider == order.tracerProvider {
+			t.Error("expected a new pipeline after the last mount was released")
+		}
+	})
+}
+
+func TestAcquire_PerConfigPipelines(t *testing.T) {
+	useCollector(t)
+	ctx := context.Background()
+
+	envConfig, err := BuildConfig(BuildConfigInput{ServiceName: "test-service", ServiceVersion: "1.0.0", Environment: "dev"})
+	if err != nil {
+		t.Fatalf("BuildConfig() error = %v", err)
+	}
+	rate := 0.5
+	tuned := envConfig.WithOverrides(&Overrides{SampleRate: &rate})
+
+	order, releaseOrder, err := AcquireWithConfig(ctx, envConfig, MountScope{BackendUUID: "order-uuid"})
+	if err != nil {
+		t.Fatalf("AcquireWithConfig() error = %v", err)
+	}
+	defer releaseOrder(ctx)
+
+	payment, releasePayment, err := AcquireWithConfig(ctx, tuned, MountScope{BackendUUID: "payment-uuid"})
+	if err != nil {
+		t.Fatalf("AcquireWithConfig() error = %v", err)
+	}
+	defer releasePayment(ctx)
+
+	purchase, releasePurchase, err := AcquireWithConfig(ctx, envConfig.WithOverrides(nil), MountScope{BackendUUID: "purchase-uuid"})
+	if err != nil {
+		t.Fatalf("AcquireWithConfig() error = %v", err)
+	}
+	defer releasePurchase(ctx)
+
+	if order.tracerProvider == payment.tracerProvider {
+		t.Error("expected a mount with its own settings to get its own pipeline")
+	}
+	if order.tracerProvider != purchase.tracerProvider {
+		t.Error("expected mounts with equal settings to share a pipeline")
+	}
+}
+
+func TestAcquire_Disabled(t *testing.T) {
+	clearTelemetryEnv(t)
+	t.Setenv("TELEMETRY_ENABLED", "false")
+
+	providers, release, err := Acquire(context.Background(), BuildConfigInput{Environment: "dev"}, MountScope{})
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if providers != nil {
+		t.Error("expected nil providers when telemetry is disabled")
+	}
+	if err := release(context.Background()); err != nil {
+		t.Errorf("release error = %v", err)
+	}
+	if activeMounts() != 0 {
+		t.Errorf("activeMounts() = %d, want 0", activeMounts())
+	}
+}
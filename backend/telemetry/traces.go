@@ -24,6 +24,14 @@ func newTracesProvider(enabled bool) *TracesProvider {
 	}
 }
 
+// newTracesProviderFromTracer creates an enabled TracesProvider that records with tracer
+func newTracesProviderFromTracer(tracer trace.Tracer) *TracesProvider {
+	return &TracesProvider{
+		tracer:  tracer,
+		enabled: true,
+	}
+}
+
 // IsEnabled returns whether tracing is enabled
 func (t *TracesProvider) IsEnabled() bool {
 	return t != nil && t.enabled
@@ -51,6 +59,27 @@ func (t *TracesProvider) StartTokenGenerate(ctx context.Context, roleName string
 	))
 }
 
+// StartTokenRevoke starts a span for revoking a token lease
+func (t *TracesProvider) StartTokenRevoke(ctx context.Context, roleName string) (context.Context, trace.Span) {
+	if !t.IsEnabled() {
+		return noopSpan(ctx)
+	}
+	return t.tracer.Start(ctx, SpanSkyflowPluginTokenRevoke, trace.WithAttributes(
+		AttrRole.String(roleName),
+	))
+}
+
+// StartTokenSign starts a span for signing data tokens
+func (t *TracesProvider) StartTokenSign(ctx context.Context, roleName string, dataTokensCount int) (context.Context, trace.Span) {
+	if !t.IsEnabled() {
+		return noopSpan(ctx)
+	}
+	return t.tracer.Start(ctx, SpanSkyflowPluginTokenSign, trace.WithAttributes(
+		AttrRole.String(roleName),
+		AttrDataTokensCount.Int(dataTokensCount),
+	))
+}
+
 // StartSDKAuth starts a span for Skyflow SDK authentication
 func (t *TracesProvider) StartSDKAuth(ctx context.Context, roleName, credentialType string, roleIDsCount int) (context.Context, trace.Span) {
 	if !t.IsEnabled() {
@@ -90,6 +119,79 @@ func (t *TracesProvider) StartConfigRead(ctx context.Context) (context.Context,
 	return t.tracer.Start(ctx, SpanSkyflowPluginConfigRead)
 }
 
+// StartConfigHistory starts a span for config history list and read operations
+func (t *TracesProvider) StartConfigHistory(ctx context.Context, operation string) (context.Context, trace.Span) {
+	if !t.IsEnabled() {
+		return noopSpan(ctx)
+	}
+	return t.tracer.Start(ctx, SpanSkyflowPluginConfigHistory, trace.WithAttributes(
+		AttrOperation.String(operation),
+	))
+}
+
+// StartConfigRollback starts a span for restoring a previous config version
+func (t *TracesProvider) StartConfigRollback(ctx context.Context, version int) (context.Context, trace.Span) {
+	if !t.IsEnabled() {
+		return noopSpan(ctx)
+	}
+	return t.tracer.Start(ctx, SpanSkyflowPluginConfigRollback, trace.WithAttributes(
+		AttrConfigVersion.Int(version),
+	))
+}
+
+// StartConfigRotateRoot starts a span for rotating the mount's service account key
+func (t *TracesProvider) StartConfigRotateRoot(ctx context.Context, trigger string) (context.Context, trace.Span) {
+	if !t.IsEnabled() {
+		return noopSpan(ctx)
+	}
+	return t.tracer.Start(ctx, SpanSkyflowPluginConfigRotate, trace.WithAttributes(
+		AttrRotationTrigger.String(trigger),
+	))
+}
+
+// ============================================================================
+// Start Methods - Credential Set Operations
+// ============================================================================
+
+// StartCredentialsWrite starts a span for credential set write operation
+func (t *TracesProvider) StartCredentialsWrite(ctx context.Context, name, operation string) (context.Context, trace.Span) {
+	if !t.IsEnabled() {
+		return noopSpan(ctx)
+	}
+	return t.tracer.Start(ctx, SpanSkyflowPluginCredentialsWrite, trace.WithAttributes(
+		AttrCredentialSet.String(name),
+		AttrOperation.String(operation),
+	))
+}
+
+// StartCredentialsRead starts a span for credential set read operation
+func (t *TracesProvider) StartCredentialsRead(ctx context.Context, name string) (context.Context, trace.Span) {
+	if !t.IsEnabled() {
+		return noopSpan(ctx)
+	}
+	return t.tracer.Start(ctx, SpanSkyflowPluginCredentialsRead, trace.WithAttributes(
+		AttrCredentialSet.String(name),
+	))
+}
+
+// StartCredentialsList starts a span for credential set list operation
+func (t *TracesProvider) StartCredentialsList(ctx context.Context) (context.Context, trace.Span) {
+	if !t.IsEnabled() {
+		return noopSpan(ctx)
+	}
+	return t.tracer.Start(ctx, SpanSkyflowPluginCredentialsList)
+}
+
+// StartCredentialsDelete starts a span for credential set delete operation
+func (t *TracesProvider) StartCredentialsDelete(ctx context.Context, name string) (context.Context, trace.Span) {
+	if !t.IsEnabled() {
+		return noopSpan(ctx)
+	}
+	return t.tracer.Start(ctx, SpanSkyflowPluginCredentialsDelete, trace.WithAttributes(
+		AttrCredentialSet.String(name),
+	))
+}
+
 // ============================================================================
 // Start Methods - Role Operations
 // ============================================================================
@@ -163,6 +265,20 @@ func (t *TracesProvider) RecordSDKAuthFailed(span trace.Span, durationMs float64
 	t.recordError(span, err)
 }
 
+// RecordSDKAuthShared records that the request joined another request's in-flight SDK call
+func (t *TracesProvider) RecordSDKAuthShared(span trace.Span) {
+	t.addEvent(span, EventSDKAuthShared)
+}
+
+// RecordSDKAuthRetry records a retried SDK auth attempt and the delay before the next one
+func (t *TracesProvider) RecordSDKAuthRetry(span trace.Span, attempt int, delayMs float64, err error) {
+	t.addEvent(span, EventSDKAuthRetry,
+		AttrRetryAttempt.Int(attempt),
+		AttrRetryDelayMs.Float64(delayMs),
+		AttrRetryReason.String(err.Error()),
+	)
+}
+
 // ============================================================================
 // Record Methods - Token Events
 // ============================================================================
@@ -173,6 +289,42 @@ func (t *TracesProvider) RecordTokenGenerated(span trace.Span, durationMs float6
 	t.setOK(span)
 }
 
+// RecordTokenCacheHit records whether the token was served from the token cache
+func (t *TracesProvider) RecordTokenCacheHit(span trace.Span, hit bool) {
+	t.setAttributes(span, AttrCacheHit.Bool(hit))
+}
+
+// RecordErrorType records the error class of a failed operation
+func (t *TracesProvider) RecordErrorType(span trace.Span, errorType string) {
+	t.setAttributes(span, AttrErrorType.String(errorType))
+}
+
+// RecordTokenRevoked records a token lease revocation
+func (t *TracesProvider) RecordTokenRevoked(span trace.Span) {
+	t.addEvent(span, EventTokenRevoked)
+	t.setOK(span)
+}
+
+// RecordTokenRevokeFailed records a failed token lease revocation
+func (t *TracesProvider) RecordTokenRevokeFailed(span trace.Span, err error) {
+	t.recordError(span, err)
+}
+
+// RecordTokenSigned records successful data token signing
+func (t *TracesProvider) RecordTokenSigned(span trace.Span, durationMs float64) {
+	t.addEvent(span, EventTokenSigned, AttrDurationMs.Float64(durationMs))
+	t.setOK(span)
+}
+
+// RecordTokenServedStale records a previously issued token served because minting a new one failed
+func (t *TracesProvider) RecordTokenServedStale(span trace.Span, durationMs float64, err error) {
+	t.addEvent(span, EventTokenStale,
+		AttrDurationMs.Float64(durationMs),
+		AttrErrorMessage.String(err.Error()),
+	)
+	t.setOK(span)
+}
+
 // RecordTokenFailed records token generation failure
 func (t *TracesProvider) RecordTokenFailed(span trace.Span, durationMs float64, err error) {
 	t.addEvent(span, EventTokenFailed, AttrDurationMs.Float64(durationMs))
@@ -101,8 +101,16 @@ func TestTracesProvider_NilSafety(t *testing.T) {
 	// Record methods should not panic on nil
 	nilProvider.RecordSDKAuthSuccess(nil, 100)
 	nilProvider.RecordSDKAuthFailed(nil, 100, errors.New("test"))
+	nilProvider.RecordSDKAuthRetry(nil, 1, 100, errors.New("test"))
+	nilProvider.RecordSDKAuthShared(nil)
 	nilProvider.RecordTokenGenerated(nil, 100)
 	nilProvider.RecordTokenFailed(nil, 100, errors.New("test"))
+	nilProvider.RecordErrorType(nil, "timeout")
+	nilProvider.RecordTokenCacheHit(nil, true)
+	nilProvider.RecordTokenRevoked(nil)
+	nilProvider.RecordTokenRevokeFailed(nil, errors.New("test"))
+	nilProvider.RecordTokenSigned(nil, 100)
+	nilProvider.RecordTokenServedStale(nil, 100, errors.New("test"))
 	nilProvider.RecordConfigUpdated(nil)
 	nilProvider.RecordConfigFound(nil, true)
 	nilProvider.RecordConfigError(nil, errors.New("test"))
@@ -175,6 +183,18 @@ func TestTracesProvider_StartMethods_CreateSpans(t *testing.T) {
 				return provider.StartSDKAuth(context.Background(), "test-role", "json", 2)
 			},
 		},
+		{
+			name: "StartTokenRevoke",
+			startF: func() (context.Context, trace.Span) {
+				return provider.StartTokenRevoke(context.Background(), "test-role")
+			},
+		},
+		{
+			name: "StartTokenSign",
+			startF: func() (context.Context, trace.Span) {
+				return provider.StartTokenSign(context.Background(), "test-role", 3)
+			},
+		},
 		{
 			name: "StartConfigWrite",
 			startF: func() (context.Context, trace.Span) {
@@ -187,6 +207,48 @@ func TestTracesProvider_StartMethods_CreateSpans(t *testing.T) {
 				return provider.StartConfigRead(context.Background())
 			},
 		},
+		{
+			name: "StartConfigHistory",
+			startF: func() (context.Context, trace.Span) {
+				return provider.StartConfigHistory(context.Background(), "list")
+			},
+		},
+		{
+			name: "StartConfigRollback",
+			startF: func() (context.Context, trace.Span) {
+				return provider.StartConfigRollback(context.Background(), 3)
+			},
+		},
+		{
+			name: "StartConfigRotateRoot",
+			startF: func() (context.Context, trace.Span) {
+				return provider.StartConfigRotateRoot(context.Background(), "scheduled")
+			},
+		},
+		{
+			name: "StartCredentialsWrite",
+			startF: func() (context.Context, trace.Span) {
+				return provider.StartCredentialsWrite(context.Background(), "test-set", "create")
+			},
+		},
+		{
+			name: "StartCredentialsRead",
+			startF: func() (context.Context, trace.Span) {
+				return provider.StartCredentialsRead(context.Background(), "test-set")
+			},
+		},
+		{
+			name: "StartCredentialsList",
+			startF: func() (context.Context, trace.Span) {
+				return provider.StartCredentialsList(context.Background())
+			},
+		},
+		{
+			name: "StartCredentialsDelete",
+			startF: func() (context.Context, trace.Span) {
+				return provider.StartCredentialsDelete(context.Background(), "test-set")
+			},
+		},
 		{
 			name: "StartRoleWrite",
 			startF: func() (context.Context, trace.Span) {
@@ -248,6 +310,7 @@ func TestTracesProvider_RecordMethods_NoErrors(t *testing.T) {
 	// Token records
 	provider.RecordTokenGenerated(span, 100.0)
 	provider.RecordTokenFailed(span, 100.0, testErr)
+	provider.RecordErrorType(span, "timeout")
 
 	// Config records
 	provider.RecordConfigUpdated(span)
@@ -0,0 +1,210 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sundhar-perumal/vault-plugin-secrets-skyflow/backend/telemetry"
+)
+
+// telemetryConfigStorageKey is where config/telemetry is stored
+const telemetryConfigStorageKey = "telemetry_config"
+
+// telemetryConfig holds a mount's telemetry settings. Unset fields keep the value the plugin
+// process resolved from its environment (ENV, OTEL_EXPORTER_OTLP_*, TELEMETRY_*).
+type telemetryConfig struct {
+	// Switches (optional) - nil keeps the environment's setting
+	Enabled        *bool `json:"enabled,omitempty"`
+	TracesEnabled  *bool `json:"traces_enabled,omitempty"`
+	MetricsEnabled *bool `json:"metrics_enabled,omitempty"`
+
+	// OTLP collector endpoints, protocols and headers (optional). Headers are nil when unset and
+	// empty when the mount sends none, so they are stored without omitempty.
+	TracesEndpoint  string            `json:"traces_endpoint,omitempty"`
+	MetricsEndpoint string            `json:"metrics_endpoint,omitempty"`
+	TracesProtocol  string            `json:"traces_protocol,omitempty"`
+	MetricsProtocol string            `json:"metrics_protocol,omitempty"`
+	TracesHeaders   map[string]string `json:"traces_headers"`
+	MetricsHeaders  map[string]string `json:"metrics_headers"`
+
+	// Sampling and export (optional)
+	SampleRate            *float64      `json:"sample_rate,omitempty"`
+	MetricsExportInterval time.Duration `json:"metrics_export_interval,omitempty"`
+
+	// Serve this mount's metrics on its metrics path (optional)
+	PrometheusEnabled *bool `json:"prometheus_enabled,omitempty"`
+
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// validate checks the telemetry settings
+func (c *telemetryConfig) validate() error {
+	for name, endpoint := range map[string]string{"traces_endpoint": c.TracesEndpoint, "metrics_endpoint": c.MetricsEndpoint} {
+		if endpoint == "" {
+			continue
+		}
+		parsed, err := url.Parse(endpoint)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%s must be an http or https URL", name)
+		}
+	}
+
+	for name, protocol := range map[string]string{"traces_protocol": c.TracesProtocol, "metrics_protocol": c.MetricsProtocol} {
+		if protocol != "" && !telemetry.ValidProtocol(protocol) {
+			return fmt.Errorf("%s must be %q or %q", name, telemetry.ProtocolGRPC, telemetry.ProtocolHTTPProtobuf)
+		}
+	}
+
+	if c.SampleRate != nil && (*c.SampleRate < 0 || *c.SampleRate > 1) {
+		return fmt.Errorf("sample_rate must be between 0 and 1")
+	}
+
+	if c.MetricsExportInterval < 0 {
+		return fmt.Errorf("metrics_export_interval cannot be negative")
+	}
+
+	return nil
+}
+
+// overriddenFields returns the names of the settings this mount overrides
+func (c *telemetryConfig) overriddenFields() []string {
+	fields := []string{}
+	set := []struct {
+		name string
+		ok   bool
+	}{
+		{"enabled", c.Enabled != nil},
+		{"traces_enabled", c.TracesEnabled != nil},
+		{"metrics_enabled", c.MetricsEnabled != nil},
+		{"traces_endpoint", c.TracesEndpoint != ""},
+		{"metrics_endpoint", c.MetricsEndpoint != ""},
+		{"traces_protocol", c.TracesProtocol != ""},
+		{"metrics_protocol", c.MetricsProtocol != ""},
+		{"traces_headers", c.TracesHeaders != nil},
+		{"metrics_headers", c.MetricsHeaders != nil},
+		{"sample_rate", c.SampleRate != nil},
+		{"metrics_export_interval", c.MetricsExportInterval > 0},
+		{"prometheus_enabled", c.PrometheusEnabled != nil},
+	}
+	for _, field := range set {
+		if field.ok {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
+
+// overrides converts the settings for merging over the environment-derived telemetry config
+func (c *telemetryConfig) overrides() *telemetry.Overrides {
+	if c == nil {
+		return nil
+	}
+
+	return &telemetry.Overrides{
+		Enabled:               c.Enabled,
+		TracesEnabled:         c.TracesEnabled,
+		MetricsEnabled:        c.MetricsEnabled,
+		TracesEndpoint:        c.TracesEndpoint,
+		MetricsEndpoint:       c.MetricsEndpoint,
+		TracesProtocol:        c.TracesProtocol,
+		MetricsProtocol:       c.MetricsProtocol,
+		TracesHeaders:         c.TracesHeaders,
+		MetricsHeaders:        c.MetricsHeaders,
+		SampleRate:            c.SampleRate,
+		MetricsExportInterval: c.MetricsExportInterval,
+		PrometheusEnabled:     c.PrometheusEnabled,
+	}
+}
+
+// getTelemetryConfig retrieves the mount's telemetry settings from storage
+func (b *skyflowBackend) getTelemetryConfig(ctx context.Context, s logical.Storage) (*telemetryConfig, error) {
+	entry, err := s.Get(ctx, telemetryConfigStorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get telemetry configuration: %w", err)
+	}
+
+	if entry == nil {
+		return nil, nil
+	}
+
+	config := &telemetryConfig{}
+	if err := entry.DecodeJSON(config); err != nil {
+		return nil, fmt.Errorf("failed to decode telemetry configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+// saveTelemetryConfig stores the mount's telemetry settings
+func (b *skyflowBackend) saveTelemetryConfig(ctx context.Context, s logical.Storage, config *telemetryConfig) error {
+	entry, err := logical.StorageEntryJSON(telemetryConfigStorageKey, config)
+	if err != nil {
+		return fmt.Errorf("failed to create storage entry: %w", err)
+	}
+
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("failed to save telemetry configuration: %w", err)
+	}
+
+	return nil
+}
+
+// deleteTelemetryConfig removes the mount's telemetry settings
+func (b *skyflowBackend) deleteTelemetryConfig(ctx context.Context, s logical.Storage) error {
+	if err := s.Delete(ctx, telemetryConfigStorageKey); err != nil {
+		return fmt.Errorf("failed to delete telemetry configuration: %w", err)
+	}
+
+	return nil
+}
+
+// effectiveTelemetryConfig merges the mount's settings over the environment-derived config
+func (b *skyflowBackend) effectiveTelemetryConfig(config *telemetryConfig) (*telemetry.ResolvedConfig, error) {
+	if b.telemetryEnvConfig == nil {
+		return nil, fmt.Errorf("telemetry environment configuration is unavailable")
+	}
+
+	return b.telemetryEnvConfig.WithOverrides(config.overrides()), nil
+}
+
+// applyTelemetryConfig switches the mount to providers built for its settings (nil for the
+// environment's) without a plugin reload. The new pipeline is acquired before the old one is
+// released, so unchanged settings keep the running exporters.
+func (b *skyflowBackend) applyTelemetryConfig(ctx context.Context, config *telemetryConfig) error {
+	resolved, err := b.effectiveTelemetryConfig(config)
+	if err != nil {
+		return err
+	}
+
+	providers, shutdown, err := telemetry.AcquireWithConfig(ctx, resolved, b.telemetryScope)
+	if err != nil {
+		return fmt.Errorf("failed to apply telemetry configuration: %w", err)
+	}
+
+	b.telemetryLock.Lock()
+	previous := b.telemetryShutdown
+	b.telemetryProviders = providers
+	b.telemetryShutdown = shutdown
+	b.telemetryLock.Unlock()
+
+	if previous != nil {
+		if err := previous(ctx); err != nil {
+			b.Logger().Warn("telemetry shutdown error", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// reloadTelemetryConfig applies the stored telemetry settings, e.g. after another node changed them
+func (b *skyflowBackend) reloadTelemetryConfig(ctx context.Context, s logical.Storage) error {
+	config, err := b.getTelemetryConfig(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	return b.applyTelemetryConfig(ctx, config)
+}
@@ -0,0 +1,129 @@
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
+)
+
+// tokenClaims holds the JWT claims read from a Skyflow access token
+type tokenClaims struct {
+	Exp int64 `json:"exp"`
+	Iat int64 `json:"iat"`
+}
+
+// parseTokenClaims reads the claims from a Skyflow JWT access token without verifying it.
+// The token was just returned by Skyflow over TLS; the claims are only used for metadata.
+func parseTokenClaims(accessToken string) (*tokenClaims, error) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("access token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token claims: %w", err)
+	}
+
+	claims := &tokenClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("failed to parse token claims: %w", err)
+	}
+
+	if claims.Exp == 0 {
+		return nil, fmt.Errorf("access token has no exp claim")
+	}
+
+	return claims, nil
+}
+
+// issuedToken is a Skyflow bearer token together with the metadata returned to callers
+type issuedToken struct {
+	Role          string
+	AccessToken   string
+	TokenType     string
+	IssuedAt      time.Time
+	ExpiresAt     time.Time
+	RoleIDs       []string
+	CredentialSet string
+	ConfigVersion int
+}
+
+// newIssuedToken builds an issuedToken from an SDK token response.
+// Expiry metadata is taken from the JWT claims; an error is returned if they cannot be read,
+// in which case the returned token is still usable but has no expiry.
+func newIssuedToken(token *common.TokenResponse, role *skyflowRole, config *skyflowConfig) (*issuedToken, error) {
+	issued := &issuedToken{
+		Role:          role.Name,
+		AccessToken:   token.AccessToken,
+		TokenType:     token.TokenType,
+		IssuedAt:      time.Now(),
+		RoleIDs:       role.RoleIDs,
+		CredentialSet: role.CredentialSet,
+		ConfigVersion: config.Version,
+	}
+
+	claims, err := parseTokenClaims(token.AccessToken)
+	if err != nil {
+		return issued, err
+	}
+
+	issued.ExpiresAt = time.Unix(claims.Exp, 0)
+	if claims.Iat != 0 {
+		issued.IssuedAt = time.Unix(claims.Iat, 0)
+	}
+
+	return issued, nil
+}
+
+// fingerprint returns a SHA-256 of the access token, used to refer to a token without storing it
+func (t *issuedToken) fingerprint() string {
+	return tokenFingerprint(t.AccessToken)
+}
+
+// tokenFingerprint returns a SHA-256 of an access token
+func tokenFingerprint(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// ttl returns the remaining lifetime of the token
+func (t *issuedToken) ttl() time.Duration {
+	if t.ExpiresAt.IsZero() {
+		return 0
+	}
+
+	ttl := time.Until(t.ExpiresAt)
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// responseData returns the creds/<role> response body for the token
+func (t *issuedToken) responseData() map[string]interface{} {
+	data := map[string]interface{}{
+		"access_token":   t.AccessToken,
+		"token_type":     t.TokenType,
+		"issued_at":      t.IssuedAt.UTC().Format(time.RFC3339),
+		"role_ids":       t.RoleIDs,
+		"config_version": t.ConfigVersion,
+	}
+
+	if t.CredentialSet != "" {
+		data["credential_set"] = t.CredentialSet
+	}
+
+	if !t.ExpiresAt.IsZero() {
+		data["expires_at"] = t.ExpiresAt.UTC().Format(time.RFC3339)
+		data["ttl_seconds"] = int64(t.ttl().Seconds())
+	}
+
+	return data
+}
@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTokenCacheRefreshWindow is how long before expiry a cached token stops being served
+const defaultTokenCacheRefreshWindow = 60 * time.Second
+
+// defaultStaleMinRemainingTTL is how long a token must still be valid to be served stale
+const defaultStaleMinRemainingTTL = 30 * time.Second
+
+// tokenCache is an in-process cache of Skyflow bearer tokens keyed by role name + ctx.
+// Each mount owns its own cache; entries are dropped when config or roles change.
+type tokenCache struct {
+	mu      sync.RWMutex
+	entries map[string]*issuedToken
+
+	// epoch is bumped on every purge so tokens minted before an invalidation are not stored
+	epoch uint64
+}
+
+// newTokenCache creates an empty token cache
+func newTokenCache() *tokenCache {
+	return &tokenCache{
+		entries: make(map[string]*issuedToken),
+	}
+}
+
+// tokenCacheKey builds the cache key for a role and ctx pair
+func tokenCacheKey(roleName, ctxData string) string {
+	return roleName + "\x00" + ctxData
+}
+
+// get returns a cached token for role+ctx if it is valid beyond the refresh window
+func (c *tokenCache) get(roleName, ctxData string, refreshWindow time.Duration) (*issuedToken, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[tokenCacheKey(roleName, ctxData)]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	if time.Until(entry.ExpiresAt) <= refreshWindow {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// getStale returns the last token cached for role+ctx, even inside the refresh window,
+// if it is valid for at least minRemaining. Used when a fresh token cannot be minted.
+func (c *tokenCache) getStale(roleName, ctxData string, minRemaining time.Duration) (*issuedToken, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[tokenCacheKey(roleName, ctxData)]
+	c.mu.RUnlock()
+
+	if !ok || time.Until(entry.ExpiresAt) < minRemaining {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// currentEpoch returns the cache epoch to pass to put once a token has been minted
+func (c *tokenCache) currentEpoch() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.epoch
+}
+
+// put stores a token for role+ctx until its expiry.
+// The token is dropped if the cache was purged since epoch was read.
+func (c *tokenCache) put(epoch uint64, roleName, ctxData string, token *issuedToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if epoch != c.epoch {
+		return
+	}
+
+	c.entries[tokenCacheKey(roleName, ctxData)] = token
+}
+
+// purgeRole removes all cached tokens for a role, regardless of ctx
+func (c *tokenCache) purgeRole(roleName string) {
+	prefix := tokenCacheKey(roleName, "")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// purgeToken removes every cache entry holding the token with the given fingerprint
+func (c *tokenCache) purgeToken(fingerprint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	for key, entry := range c.entries {
+		if entry.fingerprint() == fingerprint {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// purge removes all cached tokens
+func (c *tokenCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	c.entries = make(map[string]*issuedToken)
+}
@@ -0,0 +1,153 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// testIssuedToken builds an issuedToken that expires at expiresAt
+func testIssuedToken(expiresAt time.Time) *issuedToken {
+	return &issuedToken{
+		AccessToken: "token-1",
+		TokenType:   "Bearer",
+		IssuedAt:    time.Now(),
+		ExpiresAt:   expiresAt,
+		RoleIDs:     []string{"role-id-1"},
+	}
+}
+
+func TestTokenCache_GetPut(t *testing.T) {
+	cache := newTokenCache()
+	token := testIssuedToken(time.Now().Add(time.Hour))
+
+	t.Run("Miss on empty cache", func(t *testing.T) {
+		if _, ok := cache.get("test-role", "", time.Minute); ok {
+			t.Error("expected cache miss on empty cache")
+		}
+	})
+
+	t.Run("Hit after put", func(t *testing.T) {
+		cache.put(cache.currentEpoch(), "test-role", "", token)
+
+		cached, ok := cache.get("test-role", "", time.Minute)
+		if !ok {
+			t.Fatal("expected cache hit")
+		}
+		if cached.AccessToken != token.AccessToken {
+			t.Errorf("expected access token '%s', got '%s'", token.AccessToken, cached.AccessToken)
+		}
+	})
+
+	t.Run("Ctx is part of the key", func(t *testing.T) {
+		if _, ok := cache.get("test-role", "order:ORD-42", time.Minute); ok {
+			t.Error("expected cache miss for different ctx")
+		}
+	})
+
+	t.Run("Miss inside refresh window", func(t *testing.T) {
+		cache.put(cache.currentEpoch(), "expiring-role", "", testIssuedToken(time.Now().Add(30*time.Second)))
+
+		if _, ok := cache.get("expiring-role", "", time.Minute); ok {
+			t.Error("expected cache miss for token inside refresh window")
+		}
+		if _, ok := cache.get("expiring-role", "", 10*time.Second); !ok {
+			t.Error("expected cache hit for token outside refresh window")
+		}
+	})
+
+	t.Run("Stale read ignores the refresh window", func(t *testing.T) {
+		if _, ok := cache.getStale("expiring-role", "", 20*time.Second); !ok {
+			t.Error("expected stale hit for token with enough lifetime left")
+		}
+		if _, ok := cache.getStale("expiring-role", "", time.Minute); ok {
+			t.Error("expected stale miss for token expiring before the minimum")
+		}
+	})
+}
+
+func TestTokenCache_Purge(t *testing.T) {
+	token := testIssuedToken(time.Now().Add(time.Hour))
+
+	t.Run("Purge role", func(t *testing.T) {
+		cache := newTokenCache()
+		cache.put(cache.currentEpoch(), "role-a", "", token)
+		cache.put(cache.currentEpoch(), "role-a", "order:ORD-42", token)
+		cache.put(cache.currentEpoch(), "role-ab", "", token)
+
+		cache.purgeRole("role-a")
+
+		if _, ok := cache.get("role-a", "", time.Minute); ok {
+			t.Error("expected role-a to be purged")
+		}
+		if _, ok := cache.get("role-a", "order:ORD-42", time.Minute); ok {
+			t.Error("expected role-a ctx entry to be purged")
+		}
+		if _, ok := cache.get("role-ab", "", time.Minute); !ok {
+			t.Error("expected role-ab to remain cached")
+		}
+	})
+
+	t.Run("Purge all", func(t *testing.T) {
+		cache := newTokenCache()
+		cache.put(cache.currentEpoch(), "role-a", "", token)
+		cache.put(cache.currentEpoch(), "role-b", "", token)
+
+		cache.purge()
+
+		if _, ok := cache.get("role-a", "", time.Minute); ok {
+			t.Error("expected role-a to be purged")
+		}
+		if _, ok := cache.get("role-b", "", time.Minute); ok {
+			t.Error("expected role-b to be purged")
+		}
+	})
+
+	t.Run("Put after purge is dropped", func(t *testing.T) {
+		cache := newTokenCache()
+		epoch := cache.currentEpoch()
+
+		cache.purge()
+		cache.put(epoch, "role-a", "", token)
+
+		if _, ok := cache.get("role-a", "", time.Minute); ok {
+			t.Error("expected token minted before purge to be dropped")
+		}
+	})
+}
+
+func TestTokenCache_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	config := &logical.BackendConfig{
+		Logger: nil,
+		System: &logical.StaticSystemView{},
+	}
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	backend := b.(*skyflowBackend)
+	token := testIssuedToken(time.Now().Add(time.Hour))
+
+	backend.tokenCache.put(backend.tokenCache.currentEpoch(), "role-a", "", token)
+	backend.tokenCache.put(backend.tokenCache.currentEpoch(), "role-b", "", token)
+
+	backend.invalidate(ctx, "role/role-a")
+
+	if _, ok := backend.tokenCache.get("role-a", "", time.Minute); ok {
+		t.Error("expected role-a to be invalidated")
+	}
+	if _, ok := backend.tokenCache.get("role-b", "", time.Minute); !ok {
+		t.Error("expected role-b to remain cached")
+	}
+
+	backend.invalidate(ctx, "config")
+
+	if _, ok := backend.tokenCache.get("role-b", "", time.Minute); ok {
+		t.Error("expected config invalidation to purge all tokens")
+	}
+}
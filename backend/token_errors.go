@@ -0,0 +1,205 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	skyflowError "github.com/skyflowapi/skyflow-go/v2/utils/error"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Token failure classes. They label the token error metrics and spans, and are returned to
+// callers as error_code, so they are part of the plugin's API: add new classes, never rename.
+const (
+	tokenErrorRoleNotFound           = "role_not_found"
+	tokenErrorNotConfigured          = "not_configured"
+	tokenErrorCredentialSetNotFound  = "credential_set_not_found"
+	tokenErrorCredentialsFileMissing = "credentials_file_missing"
+	tokenErrorInvalidCredentials     = "invalid_credentials"
+	tokenErrorPolicyViolation        = "policy_violation"
+	tokenErrorCtxRejected            = "ctx_rejected"
+	tokenErrorInvalidRequest         = "invalid_request"
+	tokenErrorSigningNotAllowed      = "signing_not_allowed"
+	tokenErrorRateLimited            = "rate_limited"
+	tokenErrorCircuitOpen            = "circuit_open"
+	tokenErrorSkyflowUnauthorized    = "skyflow_unauthorized"
+	tokenErrorSkyflowRateLimited     = "skyflow_rate_limited"
+	tokenErrorSkyflowUnavailable     = "skyflow_unavailable"
+	tokenErrorSkyflowRejected        = "skyflow_rejected"
+	tokenErrorNetwork                = "network"
+	tokenErrorTimeout                = "timeout"
+	tokenErrorCanceled               = "canceled"
+	tokenErrorSDKPanic               = "sdk_panic"
+	tokenErrorEmptyToken             = "empty_token"
+	tokenErrorStorage                = "storage"
+	tokenErrorUnknown                = "unknown"
+)
+
+var (
+	// errNoCredentials is returned when a config or request carries no service account credentials
+	errNoCredentials = errors.New("no credentials configured")
+
+	// errTokenPanic wraps a panic recovered from the Skyflow SDK
+	errTokenPanic = errors.New("token generation panic")
+
+	// errEmptyToken is returned when an issuer succeeds without an access token
+	errEmptyToken = errors.New("token generation returned empty token")
+)
+
+// invalidCredentialsError marks an error caused by unusable service account credentials
+type invalidCredentialsError struct {
+	err error
+}
+
+// Error implements error
+func (e *invalidCredentialsError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *invalidCredentialsError) Unwrap() error {
+	return e.err
+}
+
+// invalidCredentials marks err as caused by unusable credentials, keeping its message
+func invalidCredentials(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &invalidCredentialsError{err: err}
+}
+
+// classifyTokenError returns the failure class of an error from generateToken
+func classifyTokenError(err error) string {
+	var exchangeErr *tokenExchangeError
+	var sdkErr *skyflowError.SkyflowError
+	var credsErr *invalidCredentialsError
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, errCircuitOpen):
+		return tokenErrorCircuitOpen
+	case errors.Is(err, errNoCredentials):
+		return tokenErrorNotConfigured
+	case errors.Is(err, fs.ErrNotExist):
+		return tokenErrorCredentialsFileMissing
+	case errors.Is(err, errTokenPanic):
+		return tokenErrorSDKPanic
+	case errors.Is(err, errEmptyToken):
+		return tokenErrorEmptyToken
+	case errors.Is(err, context.DeadlineExceeded):
+		return tokenErrorTimeout
+	case errors.Is(err, context.Canceled):
+		return tokenErrorCanceled
+	case errors.As(err, &exchangeErr):
+		return classifyStatusCode(exchangeErr.StatusCode)
+	case errors.As(err, &sdkErr):
+		return classifySkyflowError(sdkErr)
+	case errors.As(err, &credsErr):
+		return tokenErrorInvalidCredentials
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return tokenErrorTimeout
+		}
+		return tokenErrorNetwork
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return tokenErrorNetwork
+	default:
+		return tokenErrorUnknown
+	}
+}
+
+// classifySkyflowError classifies an SDK error. The SDK reports transport failures and its own
+// credential checks as 400s; only responses from Skyflow carry a request ID.
+func classifySkyflowError(sdkErr *skyflowError.SkyflowError) string {
+	message := sdkErr.Error()
+	for _, fragment := range transientNetworkErrors {
+		if strings.Contains(message, fragment) {
+			if strings.Contains(strings.ToLower(fragment), "timeout") {
+				return tokenErrorTimeout
+			}
+			return tokenErrorNetwork
+		}
+	}
+
+	code, err := strconv.Atoi(strings.TrimPrefix(sdkErr.GetCode(), "Code: "))
+	if err != nil {
+		return tokenErrorUnknown
+	}
+	if code == 400 && sdkErr.GetRequestId() == "" {
+		return tokenErrorInvalidCredentials
+	}
+	return classifyStatusCode(code)
+}
+
+// classifyStatusCode classifies a non-2xx status from the Skyflow token endpoint
+func classifyStatusCode(code int) string {
+	switch {
+	case code == 401 || code == 403:
+		return tokenErrorSkyflowUnauthorized
+	case code == 408:
+		return tokenErrorTimeout
+	case code == 429:
+		return tokenErrorSkyflowRateLimited
+	case code >= 500:
+		return tokenErrorSkyflowUnavailable
+	case code >= 400:
+		return tokenErrorSkyflowRejected
+	default:
+		return tokenErrorUnknown
+	}
+}
+
+// tokenErrorResponse returns an error response carrying the failure class as error_code. Vault
+// returns it to HTTP callers as {"errors": [...], "data": {"error_code": ...}}.
+func tokenErrorResponse(errorCode string, format string, args ...interface{}) *logical.Response {
+	return logical.ErrorResponseWithData(map[string]interface{}{"error_code": errorCode}, format, args...)
+}
+
+// tokenErrorStatusResponse returns a token error response with a non-default HTTP status. Vault
+// only passes a status through on a raw response, so the body is built in Vault's error shape,
+// {"errors": [...], "data": {"error_code": ...}}, which RespondWithStatusCode would not produce.
+func tokenErrorStatusResponse(status int, errorCode string, format string, args ...interface{}) (*logical.Response, error) {
+	message := format
+	if len(args) > 0 {
+		message = fmt.Sprintf(format, args...)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"errors": []string{message},
+		"data":   map[string]interface{}{"error_code": errorCode},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode error response: %w", err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: "application/json",
+			logical.HTTPRawBody:     body,
+			logical.HTTPStatusCode:  status,
+		},
+	}, nil
+}
+
+// recordTokenFailure records a failed token request of the given class on its span and metrics
+func (b *skyflowBackend) recordTokenFailure(ctx context.Context, span trace.Span, start time.Time, roleName, vaultServiceName, skyflowVaultName, errorCode string, err error) {
+	traces := b.traces()
+	traces.RecordTokenFailed(span, float64(time.Since(start).Milliseconds()), err)
+	traces.RecordErrorType(span, errorCode)
+
+	if m := b.metrics(); m != nil {
+		m.RecordTokenError(ctx, roleName, vaultServiceName, skyflowVaultName, errorCode)
+	}
+}
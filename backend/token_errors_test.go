@@ -0,0 +1,309 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/logical"
+	skyflowError "github.com/skyflowapi/skyflow-go/v2/utils/error"
+)
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// rawErrorBody is the body of a token error response with a non-default HTTP status
+type rawErrorBody struct {
+	Errors []string `json:"errors"`
+	Data   struct {
+		ErrorCode string `json:"error_code"`
+	} `json:"data"`
+}
+
+// decodeRawErrorBody checks that resp is a raw response with the given status and decodes its body
+func decodeRawErrorBody(t *testing.T, resp *logical.Response, status int) rawErrorBody {
+	t.Helper()
+	if resp == nil || resp.Data[logical.HTTPStatusCode] != status {
+		t.Fatalf("expected a %d response, got %v", status, resp)
+	}
+
+	raw, ok := resp.Data[logical.HTTPRawBody].([]byte)
+	if !ok {
+		t.Fatalf("expected a raw body, got %T", resp.Data[logical.HTTPRawBody])
+	}
+
+	var body rawErrorBody
+	if err := json.Unmarshal(raw, &body); err != nil {
+		t.Fatalf("failed to decode body %s: %v", raw, err)
+	}
+	if len(body.Errors) != 1 || body.Errors[0] == "" {
+		t.Errorf("expected one error message, got %s", raw)
+	}
+	return body
+}
+
+func TestTokenErrors_Classify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "Nil", err: nil, want: ""},
+		{name: "Circuit open", err: fmt.Errorf("%w; retry after now", errCircuitOpen), want: tokenErrorCircuitOpen},
+		{name: "No credentials", err: errNoCredentials, want: tokenErrorNotConfigured},
+		{name: "Credentials file missing", err: fmt.Errorf("credentials file not found: %s: %w", "/vault/creds.json", fs.ErrNotExist), want: tokenErrorCredentialsFileMissing},
+		{name: "Native credentials file missing", err: invalidCredentials(fmt.Errorf("failed to read credentials file: %w", os.ErrNotExist)), want: tokenErrorCredentialsFileMissing},
+		{name: "SDK panic", err: fmt.Errorf("%w: %v", errTokenPanic, "nil map"), want: tokenErrorSDKPanic},
+		{name: "Empty token", err: errEmptyToken, want: tokenErrorEmptyToken},
+		{name: "Request timeout", err: fmt.Errorf("token request exceeded request_timeout of 30s: %w", context.DeadlineExceeded), want: tokenErrorTimeout},
+		{name: "Canceled", err: fmt.Errorf("token request abandoned: %w", context.Canceled), want: tokenErrorCanceled},
+		{name: "Exchange 401", err: &tokenExchangeError{StatusCode: http.StatusUnauthorized}, want: tokenErrorSkyflowUnauthorized},
+		{name: "Exchange 403", err: &tokenExchangeError{StatusCode: http.StatusForbidden}, want: tokenErrorSkyflowUnauthorized},
+		{name: "Exchange 429", err: &tokenExchangeError{StatusCode: http.StatusTooManyRequests}, want: tokenErrorSkyflowRateLimited},
+		{name: "Exchange 503", err: &tokenExchangeError{StatusCode: http.StatusServiceUnavailable}, want: tokenErrorSkyflowUnavailable},
+		{name: "Exchange 400", err: &tokenExchangeError{StatusCode: http.StatusBadRequest}, want: tokenErrorSkyflowRejected},
+		{name: "SDK 401", err: fmt.Errorf("failed: %w", skyflowError.NewSkyflowError("401", "unauthorized")), want: tokenErrorSkyflowUnauthorized},
+		{name: "SDK 429", err: fmt.Errorf("failed: %w", skyflowError.NewSkyflowError("429", "too many requests")), want: tokenErrorSkyflowRateLimited},
+		{name: "SDK server error", err: fmt.Errorf("failed: %w", skyflowError.NewSkyflowError(skyflowError.SERVER, "boom")), want: tokenErrorSkyflowUnavailable},
+		{name: "SDK invalid credentials", err: fmt.Errorf("failed: %w", skyflowError.NewSkyflowError(skyflowError.INVALID_INPUT_CODE, "Invalid credentials")), want: tokenErrorInvalidCredentials},
+		{name: "SDK connection refused", err: fmt.Errorf("failed: %w", skyflowError.NewSkyflowError(skyflowError.INVALID_INPUT_CODE, "dial tcp 127.0.0.1:443: connect: connection refused")), want: tokenErrorNetwork},
+		{name: "SDK client timeout", err: fmt.Errorf("failed: %w", skyflowError.NewSkyflowError(skyflowError.INVALID_INPUT_CODE, "Post: Client.Timeout exceeded while awaiting headers")), want: tokenErrorTimeout},
+		{name: "Native invalid credentials", err: invalidCredentials(fmt.Errorf("privateKey must be an RSA key")), want: tokenErrorInvalidCredentials},
+		{name: "Network error", err: fmt.Errorf("token request failed: %w", &net.OpError{Op: "dial", Err: fmt.Errorf("refused")}), want: tokenErrorNetwork},
+		{name: "Network timeout", err: fmt.Errorf("token request failed: %w", timeoutError{}), want: tokenErrorTimeout},
+		{name: "Unexpected EOF", err: fmt.Errorf("failed to read token response: %w", io.ErrUnexpectedEOF), want: tokenErrorNetwork},
+		{name: "Plain error", err: fmt.Errorf("issuer unavailable"), want: tokenErrorUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyTokenError(tt.err); got != tt.want {
+				t.Errorf("classifyTokenError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenErrors_TokenPath(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	issuer := &recordingTokenIssuer{err: &tokenExchangeError{StatusCode: http.StatusUnauthorized, Message: "invalid signature"}}
+
+	b, err := FactoryWithOptions(WithTokenIssuer(issuer))(ctx, &logical.BackendConfig{
+		Logger:      nil,
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	readRawCreds := func(t *testing.T, role string) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.ReadOperation,
+			Path:       "creds/" + role,
+			Storage:    storage,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil {
+			t.Fatalf("creds read failed: %v", err)
+		}
+		return resp
+	}
+
+	readCreds := func(t *testing.T, role string) *logical.Response {
+		t.Helper()
+		resp := readRawCreds(t, role)
+		if resp == nil || !resp.IsError() {
+			t.Fatalf("expected an error response, got %v", resp)
+		}
+		return resp
+	}
+
+	write := func(t *testing.T, path string, data map[string]interface{}) {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write %s: err=%v resp=%v", path, err, resp)
+		}
+	}
+
+	errorCode := func(resp *logical.Response) interface{} {
+		data, _ := resp.Data["data"].(map[string]interface{})
+		return data["error_code"]
+	}
+
+	t.Run("Unknown role", func(t *testing.T) {
+		resp := readCreds(t, "missing-role")
+		if got := errorCode(resp); got != tokenErrorRoleNotFound {
+			t.Errorf("error_code = %v, want %q", got, tokenErrorRoleNotFound)
+		}
+	})
+
+	t.Run("Not configured", func(t *testing.T) {
+		write(t, "roles/order-processor", map[string]interface{}{"role_ids": "order-role-id"})
+
+		resp := readCreds(t, "order-processor")
+		if got := errorCode(resp); got != tokenErrorNotConfigured {
+			t.Errorf("error_code = %v, want %q", got, tokenErrorNotConfigured)
+		}
+	})
+
+	t.Run("Skyflow rejects the credentials", func(t *testing.T) {
+		write(t, "config", map[string]interface{}{
+			"credentials_json":     `{"test": "creds"}`,
+			"validate_credentials": false,
+			"max_retries":          0,
+		})
+
+		resp := readCreds(t, "order-processor")
+		if got := errorCode(resp); got != tokenErrorSkyflowUnauthorized {
+			t.Errorf("error_code = %v, want %q", got, tokenErrorSkyflowUnauthorized)
+		}
+	})
+
+	t.Run("Rate limited reads carry error_code in a raw 429 body", func(t *testing.T) {
+		write(t, "roles/ledger-reader", map[string]interface{}{"role_ids": "ledger-role-id", "rate_limit": 0.01, "rate_limit_burst": 1})
+
+		readCreds(t, "ledger-reader")
+		body := decodeRawErrorBody(t, readRawCreds(t, "ledger-reader"), http.StatusTooManyRequests)
+		if body.Data.ErrorCode != tokenErrorRateLimited {
+			t.Errorf("error_code = %q, want %q", body.Data.ErrorCode, tokenErrorRateLimited)
+		}
+	})
+
+	t.Run("An open breaker carries error_code in a raw 503 body", func(t *testing.T) {
+		issuer.err = &tokenExchangeError{StatusCode: http.StatusBadGateway}
+		write(t, "config", map[string]interface{}{
+			"credentials_json":          `{"test": "creds"}`,
+			"validate_credentials":      false,
+			"max_retries":               0,
+			"circuit_breaker_threshold": 1,
+		})
+
+		readCreds(t, "order-processor")
+		body := decodeRawErrorBody(t, readRawCreds(t, "order-processor"), http.StatusServiceUnavailable)
+		if body.Data.ErrorCode != tokenErrorCircuitOpen {
+			t.Errorf("error_code = %q, want %q", body.Data.ErrorCode, tokenErrorCircuitOpen)
+		}
+	})
+}
+
+func TestTokenErrors_SignPath(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	var logs bytes.Buffer
+	b, err := Factory(ctx, &logical.BackendConfig{
+		Logger:      hclog.New(&hclog.LoggerOptions{Output: &logs, JSONFormat: true}),
+		System:      &logical.StaticSystemView{},
+		StorageView: storage,
+	})
+	if err != nil {
+		t.Fatalf("unable to create backend: %v", err)
+	}
+
+	write := func(t *testing.T, path string, data map[string]interface{}) {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("failed to write %s: err=%v resp=%v", path, err, resp)
+		}
+	}
+
+	// checkSign signs as role and checks the error_code of the response and of its audit event
+	checkSign := func(t *testing.T, role string, data map[string]interface{}, want string) {
+		t.Helper()
+		logs.Reset()
+
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation:  logical.UpdateOperation,
+			Path:       "sign/" + role,
+			Storage:    storage,
+			Data:       data,
+			Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+		})
+		if err != nil {
+			t.Fatalf("sign failed: %v", err)
+		}
+		if resp == nil || !resp.IsError() {
+			t.Fatalf("expected an error response, got %v", resp)
+		}
+		if got, _ := resp.Data["data"].(map[string]interface{}); got["error_code"] != want {
+			t.Errorf("error_code = %v, want %q", got["error_code"], want)
+		}
+
+		var audit map[string]interface{}
+		for _, line := range bytes.Split(logs.Bytes(), []byte("\n")) {
+			var entry map[string]interface{}
+			if json.Unmarshal(line, &entry) == nil && entry["@message"] == "audit" {
+				audit = entry
+			}
+		}
+		if audit == nil {
+			t.Fatalf("expected an audit event, got logs:\n%s", logs.String())
+		}
+		if audit["operation"] != "data_token_sign" || audit["success"] != false || audit["error_code"] != want {
+			t.Errorf("unexpected audit event: %v", audit)
+		}
+	}
+
+	write(t, "roles/creds-only", map[string]interface{}{"role_ids": "skyflow-role-read"})
+	write(t, "roles/detokenizer", map[string]interface{}{
+		"role_ids":            "skyflow-role-read",
+		"allow_signing":       true,
+		"allowed_ctx_pattern": "txn:*",
+	})
+
+	tests := []struct {
+		name string
+		role string
+		data map[string]interface{}
+		want string
+	}{
+		{name: "Unknown role", role: "missing-role", data: map[string]interface{}{"data_tokens": "tok-1"}, want: tokenErrorRoleNotFound},
+		{name: "Missing data tokens", role: "detokenizer", data: map[string]interface{}{}, want: tokenErrorInvalidRequest},
+		{name: "Signing not allowed", role: "creds-only", data: map[string]interface{}{"data_tokens": "tok-1"}, want: tokenErrorSigningNotAllowed},
+		{name: "ctx rejected", role: "detokenizer", data: map[string]interface{}{"data_tokens": "tok-1", "ctx": "admin"}, want: tokenErrorCtxRejected},
+		{name: "Not configured", role: "detokenizer", data: map[string]interface{}{"data_tokens": "tok-1", "ctx": "txn:PAY-1"}, want: tokenErrorNotConfigured},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkSign(t, tt.role, tt.data, tt.want)
+		})
+	}
+
+	t.Run("Unusable credentials", func(t *testing.T) {
+		write(t, "config", map[string]interface{}{
+			"credentials_json":     `{"test": "creds"}`,
+			"validate_credentials": false,
+		})
+
+		checkSign(t, "detokenizer", map[string]interface{}{"data_tokens": "tok-1", "ctx": "txn:PAY-1"}, tokenErrorInvalidCredentials)
+	})
+}
@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
+)
+
+// tokenFlight coalesces concurrent token mints for the same key so they share one Skyflow call.
+// Each mount owns its own; a key is only shared while its call is in flight.
+type tokenFlight struct {
+	mu    sync.Mutex
+	calls map[string]*tokenFlightCall
+}
+
+// tokenFlightCall is an in-flight token mint and, once done is closed, its result
+type tokenFlightCall struct {
+	done  chan struct{}
+	token *common.TokenResponse
+	err   error
+}
+
+// newTokenFlight creates an empty tokenFlight
+func newTokenFlight() *tokenFlight {
+	return &tokenFlight{
+		calls: make(map[string]*tokenFlightCall),
+	}
+}
+
+// tokenFlightKey identifies requests that may share a mint: same role, ctx and config version,
+// and no config, credential set or role change since (the token cache epoch).
+func tokenFlightKey(roleName, ctxData string, configVersion int, cacheEpoch uint64) string {
+	return roleName + "\x00" + ctxData + "\x00" + strconv.Itoa(configVersion) + "\x00" + strconv.FormatUint(cacheEpoch, 10)
+}
+
+// do returns the result of mint for key, joining a call already in flight if there is one.
+// mint runs detached from the caller's cancellation so one caller giving up does not fail the
+// others; each caller still stops waiting when its own ctx is done. shared reports whether
+// the result came from another caller's call.
+func (f *tokenFlight) do(ctx context.Context, key string, mint func(context.Context) (*common.TokenResponse, error)) (token *common.TokenResponse, shared bool, err error) {
+	f.mu.Lock()
+	call, shared := f.calls[key]
+	if !shared {
+		call = &tokenFlightCall{done: make(chan struct{})}
+		f.calls[key] = call
+
+		go func() {
+			call.token, call.err = mint(context.WithoutCancel(ctx))
+
+			f.mu.Lock()
+			delete(f.calls, key)
+			f.mu.Unlock()
+
+			close(call.done)
+		}()
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, shared, call.err
+	case <-ctx.Done():
+		return nil, shared, fmt.Errorf("token request abandoned: %w", ctx.Err())
+	}
+}
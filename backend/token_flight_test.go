@@ -0,0 +1,219 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
+)
+
+// slowTokenIssuer is a TokenIssuer that takes delay to answer and counts its calls. Its tokens
+// have no readable expiry, so they are never cached and every creds read reaches the issuer.
+type slowTokenIssuer struct {
+	delay time.Duration
+	calls atomic.Int64
+}
+
+func (s *slowTokenIssuer) IssueToken(ctx context.Context, req *TokenRequest) (*common.TokenResponse, error) {
+	s.calls.Add(1)
+	select {
+	case <-time.After(s.delay):
+		return &common.TokenResponse{AccessToken: "opaque-token", TokenType: "Bearer"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestTokenFlight_Do(t *testing.T) {
+	t.Run("Concurrent callers share one call", func(t *testing.T) {
+		flight := newTokenFlight()
+		var calls atomic.Int64
+		release := make(chan struct{})
+
+		mint := func(ctx context.Context) (*common.TokenResponse, error) {
+			calls.Add(1)
+			<-release
+			return &common.TokenResponse{AccessToken: "token-1"}, nil
+		}
+
+		const callers = 20
+		var wg sync.WaitGroup
+		var sharedCount atomic.Int64
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, shared, err := flight.do(context.Background(), "key", mint)
+				if err != nil || token.AccessToken != "token-1" {
+					t.Errorf("unexpected result: token=%v err=%v", token, err)
+				}
+				if shared {
+					sharedCount.Add(1)
+				}
+			}()
+		}
+
+		// Let every caller join before the call completes
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			flight.mu.Lock()
+			inFlight := len(flight.calls)
+			flight.mu.Unlock()
+			if inFlight == 1 && calls.Load() == 1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		if calls.Load() != 1 {
+			t.Errorf("expected 1 call, got %d", calls.Load())
+		}
+		if sharedCount.Load() != callers-1 {
+			t.Errorf("expected %d shared results, got %d", callers-1, sharedCount.Load())
+		}
+	})
+
+	t.Run("Different keys do not share", func(t *testing.T) {
+		flight := newTokenFlight()
+		var calls atomic.Int64
+		mint := func(ctx context.Context) (*common.TokenResponse, error) {
+			calls.Add(1)
+			return &common.TokenResponse{AccessToken: "token-1"}, nil
+		}
+
+		_, _, _ = flight.do(context.Background(), tokenFlightKey("role-a", "", 1, 0), mint)
+		_, _, _ = flight.do(context.Background(), tokenFlightKey("role-a", "", 2, 0), mint)
+
+		if calls.Load() != 2 {
+			t.Errorf("expected 2 calls, got %d", calls.Load())
+		}
+	})
+
+	t.Run("Cancelled caller stops waiting without failing the call", func(t *testing.T) {
+		flight := newTokenFlight()
+		release := make(chan struct{})
+		mint := func(ctx context.Context) (*common.TokenResponse, error) {
+			select {
+			case <-release:
+				return &common.TokenResponse{AccessToken: "token-1"}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error, 1)
+		go func() {
+			_, _, err := flight.do(ctx, "key", mint)
+			errCh <- err
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		if err := <-errCh; !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+
+		resultCh := make(chan error, 1)
+		go func() {
+			token, _, err := flight.do(context.Background(), "key", mint)
+			if err == nil && token.AccessToken != "token-1" {
+				err = errors.New("unexpected token")
+			}
+			resultCh <- err
+		}()
+
+		close(release)
+		if err := <-resultCh; err != nil {
+			t.Errorf("expected the shared call to succeed, got %v", err)
+		}
+	})
+}
+
+// BenchmarkPathToken_Coalescing compares Skyflow calls per creds read with and without
+// request coalescing while many clients ask for the same role at once
+func BenchmarkPathToken_Coalescing(b *testing.B) {
+	ctx := context.Background()
+
+	setup := func(b *testing.B) (*skyflowBackend, *slowTokenIssuer, logical.Storage) {
+		b.Helper()
+		storage := &logical.InmemStorage{}
+		issuer := &slowTokenIssuer{delay: 5 * time.Millisecond}
+
+		lb, err := FactoryWithOptions(WithTokenIssuer(issuer))(ctx, &logical.BackendConfig{
+			Logger:      nil,
+			System:      &logical.StaticSystemView{},
+			StorageView: storage,
+		})
+		if err != nil {
+			b.Fatalf("unable to create backend: %v", err)
+		}
+
+		writes := []struct {
+			path string
+			data map[string]interface{}
+		}{
+			{path: "config", data: map[string]interface{}{"credentials_json": `{"test": "creds"}`, "validate_credentials": false}},
+			{path: "roles/payment-risk-engine", data: map[string]interface{}{"role_ids": "skyflow-role-read"}},
+		}
+		for _, w := range writes {
+			resp, err := lb.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      w.path,
+				Storage:   storage,
+				Data:      w.data,
+			})
+			if err != nil || (resp != nil && resp.IsError()) {
+				b.Fatalf("failed to write %s: err=%v resp=%v", w.path, err, resp)
+			}
+		}
+
+		return lb.(*skyflowBackend), issuer, storage
+	}
+
+	b.Run("Uncoalesced", func(b *testing.B) {
+		backend, issuer, storage := setup(b)
+		config, _ := backend.getConfig(ctx, storage)
+		role, _ := backend.getRole(ctx, storage, "payment-risk-engine")
+
+		b.SetParallelism(50)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := backend.generateToken(ctx, config, role, ""); err != nil {
+					b.Error(err)
+				}
+			}
+		})
+		b.ReportMetric(float64(issuer.calls.Load())/float64(b.N), "skyflow-calls/op")
+	})
+
+	b.Run("Coalesced", func(b *testing.B) {
+		backend, issuer, storage := setup(b)
+
+		b.SetParallelism(50)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				resp, err := backend.HandleRequest(ctx, &logical.Request{
+					Operation:  logical.ReadOperation,
+					Path:       "creds/payment-risk-engine",
+					Storage:    storage,
+					Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+				})
+				if err != nil || resp == nil || resp.IsError() {
+					b.Errorf("token request failed: err=%v resp=%v", err, resp)
+				}
+			}
+		})
+		b.ReportMetric(float64(issuer.calls.Load())/float64(b.N), "skyflow-calls/op")
+	})
+}
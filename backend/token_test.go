@@ -0,0 +1,140 @@
+package backend
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/skyflowapi/skyflow-go/v2/utils/common"
+)
+
+// testJWT builds an unsigned JWT with the given claims payload
+func testJWT(claims string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(claims))
+	return header + "." + payload + ".signature"
+}
+
+func TestParseTokenClaims(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	iat := time.Now().Unix()
+
+	tests := []struct {
+		name      string
+		token     string
+		wantError bool
+	}{
+		{
+			name:      "Valid JWT",
+			token:     testJWT(fmt.Sprintf(`{"exp":%d,"iat":%d}`, exp, iat)),
+			wantError: false,
+		},
+		{
+			name:      "Not a JWT",
+			token:     "opaque-token",
+			wantError: true,
+		},
+		{
+			name:      "Invalid payload encoding",
+			token:     "header.!!!.signature",
+			wantError: true,
+		},
+		{
+			name:      "Invalid payload JSON",
+			token:     "header." + base64.RawURLEncoding.EncodeToString([]byte("not-json")) + ".signature",
+			wantError: true,
+		},
+		{
+			name:      "Missing exp claim",
+			token:     testJWT(`{"sub":"client"}`),
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := parseTokenClaims(tt.token)
+			if tt.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims.Exp != exp {
+				t.Errorf("expected exp %d, got %d", exp, claims.Exp)
+			}
+			if claims.Iat != iat {
+				t.Errorf("expected iat %d, got %d", iat, claims.Iat)
+			}
+		})
+	}
+}
+
+func TestIssuedToken_ResponseData(t *testing.T) {
+	role := &skyflowRole{
+		Name:    "payment-risk-engine",
+		RoleIDs: []string{"skyflow-role-risk-001"},
+	}
+	config := &skyflowConfig{Version: 3}
+
+	t.Run("Token with claims", func(t *testing.T) {
+		exp := time.Now().Add(time.Hour).Unix()
+		iat := time.Now().Add(-time.Minute).Unix()
+		token := &common.TokenResponse{
+			AccessToken: testJWT(fmt.Sprintf(`{"exp":%d,"iat":%d}`, exp, iat)),
+			TokenType:   "Bearer",
+		}
+
+		issued, err := newIssuedToken(token, role, config)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data := issued.responseData()
+
+		if data["access_token"] != token.AccessToken {
+			t.Error("expected access_token to be returned")
+		}
+		if data["expires_at"] != time.Unix(exp, 0).UTC().Format(time.RFC3339) {
+			t.Errorf("unexpected expires_at: %v", data["expires_at"])
+		}
+		if data["issued_at"] != time.Unix(iat, 0).UTC().Format(time.RFC3339) {
+			t.Errorf("unexpected issued_at: %v", data["issued_at"])
+		}
+		ttl, ok := data["ttl_seconds"].(int64)
+		if !ok || ttl <= 0 || ttl > 3600 {
+			t.Errorf("unexpected ttl_seconds: %v", data["ttl_seconds"])
+		}
+		if data["config_version"] != 3 {
+			t.Errorf("expected config_version 3, got %v", data["config_version"])
+		}
+		if ids, ok := data["role_ids"].([]string); !ok || len(ids) != 1 || ids[0] != "skyflow-role-risk-001" {
+			t.Errorf("unexpected role_ids: %v", data["role_ids"])
+		}
+	})
+
+	t.Run("Token without readable claims", func(t *testing.T) {
+		token := &common.TokenResponse{AccessToken: "opaque-token", TokenType: "Bearer"}
+
+		issued, err := newIssuedToken(token, role, config)
+		if err == nil {
+			t.Error("expected error for token without claims")
+		}
+
+		data := issued.responseData()
+
+		if data["access_token"] != "opaque-token" {
+			t.Error("expected access_token to be returned")
+		}
+		if _, ok := data["expires_at"]; ok {
+			t.Error("expected no expires_at for token without claims")
+		}
+		if _, ok := data["ttl_seconds"]; ok {
+			t.Error("expected no ttl_seconds for token without claims")
+		}
+	})
+}